@@ -0,0 +1,119 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// RegisterAlias makes alias refer to the original reference
+// so code still using an old reference keeps on working after
+// the config was renamed
+// aliases are resolved in MustConfigure(), MustConstruct() and Get()
+// before the sources are used, so only the original is read from sources
+// config already registered under the alias (e.g. from an init() that ran
+// before the alias was registered) is moved to the original
+// multiple aliases may refer to the same original and an alias may
+// refer to another alias, but circular aliases will panic
+func (c *Config) RegisterAlias(alias, original string) {
+	if c.loaded {
+		panic(fmt.Sprintf("config.RegisterAlias(%s) called after config.Load()", alias))
+	}
+	if !validReference(alias) {
+		panic(fmt.Sprintf("invalid config alias(%s), expecting dot-notation reference", alias))
+	}
+	if !validReference(original) {
+		panic(fmt.Sprintf("invalid config reference(%s), expecting dot-notation reference", original))
+	}
+
+	c.moduleDataMutex.Lock()
+	defer c.moduleDataMutex.Unlock()
+	c.aliasMutex.Lock()
+	defer c.aliasMutex.Unlock()
+	if existing, ok := c.originalByAlias[alias]; ok && existing != original {
+		panic(fmt.Sprintf("config.RegisterAlias(%s) already refers to %s != %s", alias, existing, original))
+	}
+
+	//follow the chain of aliases from original to detect circular aliases
+	for ref, ok := original, true; ok; ref, ok = c.originalByAlias[ref] {
+		if ref == alias {
+			panic(fmt.Sprintf("config.RegisterAlias(%s -> %s) is circular", alias, original))
+		}
+	}
+	c.originalByAlias[alias] = original
+	Logger().Debugf("Registered alias(%s) -> %s", alias, original)
+
+	//follow the chain to the end, because config is registered there
+	for next, ok := c.originalByAlias[original]; ok; next, ok = c.originalByAlias[original] {
+		original = next
+	}
+	c.moveAliased(alias, original)
+} //RegisterAlias()
+
+// moveAliased moves config and watchers that were registered under the alias to the original
+// the caller must hold moduleDataMutex and aliasMutex
+func (c *Config) moveAliased(alias, original string) {
+	if tmpl, ok := c.mustConfigureByRef[alias]; ok {
+		if existingTmpl, ok := c.mustConfigureByRef[original]; ok && reflect.TypeOf(tmpl) != reflect.TypeOf(existingTmpl) {
+			panic(fmt.Sprintf("config.RegisterAlias(%s -> %s) with conflicting type %v != %v already registered", alias, original, reflect.TypeOf(tmpl), reflect.TypeOf(existingTmpl)))
+		} else if !ok {
+			c.mustConfigureByRef[original] = tmpl
+		}
+		c.requiredConfigureByRef[original] = c.requiredConfigureByRef[original] || c.requiredConfigureByRef[alias]
+		delete(c.mustConfigureByRef, alias)
+		delete(c.requiredConfigureByRef, alias)
+		Logger().Debugf("Moved config(%s) to alias original(%s)", alias, original)
+	}
+	for _, info := range c.constructorsByType {
+		info.Lock()
+		if required, ok := info.mustConstructByRef[alias]; ok {
+			info.mustConstructByRef[original] = info.mustConstructByRef[original] || required
+			delete(info.mustConstructByRef, alias)
+			Logger().Debugf("Moved construct(%s) to alias original(%s)", alias, original)
+		}
+		info.Unlock()
+	}
+	c.watchMutex.Lock()
+	if list, ok := c.watchersByRef[alias]; ok {
+		c.watchersByRef[original] = append(c.watchersByRef[original], list...)
+		delete(c.watchersByRef, alias)
+	}
+	c.watchMutex.Unlock()
+	c.validatorMutex.Lock()
+	if list, ok := c.validatorsByName[alias]; ok {
+		c.validatorsByName[original] = append(c.validatorsByName[original], list...)
+		delete(c.validatorsByName, alias)
+	}
+	c.validatorMutex.Unlock()
+	c.transformMutex.Lock()
+	if list, ok := c.transformsByRef[alias]; ok {
+		c.transformsByRef[original] = append(c.transformsByRef[original], list...)
+		delete(c.transformsByRef, alias)
+	}
+	c.transformMutex.Unlock()
+} //moveAliased()
+
+// Alias returns the original reference for an alias
+// following chained aliases to the end
+// it returns false if ref is not an alias
+func (c *Config) Alias(ref string) (string, bool) {
+	c.aliasMutex.Lock()
+	defer c.aliasMutex.Unlock()
+	original, ok := c.originalByAlias[ref]
+	if !ok {
+		return ref, false
+	}
+	for next, ok := c.originalByAlias[original]; ok; next, ok = c.originalByAlias[original] {
+		original = next
+	}
+	return original, true
+}
+
+// resolveAlias returns the original if ref is an alias
+// with a warning to encourage use of the original
+func (c *Config) resolveAlias(ref string) string {
+	if original, ok := c.Alias(ref); ok {
+		Logger().Infof("config(%s) is deprecated, use config(%s) instead", ref, original)
+		return original
+	}
+	return ref
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestAlias(t *testing.T) {
+	reset(t)
+	RegisterAlias("old.name", "ms.name")
+	MustConfigure("old.name", "")
+	addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{"name": "test"}, "old": map[string]interface{}{"name": "ignored"}})
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if Get("old.name") != "test" || Get("ms.name") != "test" {
+		t.Fatalf("old.name=%v ms.name=%v", Get("old.name"), Get("ms.name"))
+	}
+	if original, ok := Alias("old.name"); !ok || original != "ms.name" {
+		t.Fatalf("Alias()=%s,%v", original, ok)
+	}
+	if original, ok := Alias("ms.name"); ok || original != "ms.name" {
+		t.Fatalf("Alias()=%s,%v for original", original, ok)
+	}
+}
+
+func TestAliasChained(t *testing.T) {
+	reset(t)
+	RegisterAlias("older.name", "old.name")
+	RegisterAlias("old.name", "ms.name")
+	if original, ok := Alias("older.name"); !ok || original != "ms.name" {
+		t.Fatalf("Alias()=%s,%v", original, ok)
+	}
+	MustConfigure("older.name", "")
+	addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{"name": "test"}})
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if Get("older.name") != "test" {
+		t.Fatalf("older.name=%v", Get("older.name"))
+	}
+}
+
+func TestAliasRegisteredAfterConfigure(t *testing.T) {
+	reset(t)
+	//e.g. init() of a package that uses the old name ran before the alias was registered
+	MustConfigure("old.name", "")
+	MayConstruct("old.greeter", testGreeterType)
+	changed := make(chan interface{}, 1)
+	stop := Watch("old.name", func(v interface{}) { changed <- v })
+	RegisterAlias("old.name", "ms.name")
+	RegisterAlias("old.greeter", "ms.greeter")
+	RegisterConstructor("hello", testGreeterConfig{})
+	s := addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{
+		"name":    "test",
+		"greeter": map[string]interface{}{"hello": map[string]interface{}{"greeting": "hi"}},
+	}})
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if Get("old.name") != "test" {
+		t.Fatalf("old.name=%v", Get("old.name"))
+	}
+	if Get("old.greeter").(testGreeter).Greet() != "hi" {
+		t.Fatalf("old.greeter not constructed")
+	}
+	if _, ok := defaultConfig.mustConfigureByRef["old.name"]; ok {
+		t.Fatalf("old.name still registered")
+	}
+	if v := waitFor(t, changed); v != "test" {
+		t.Fatalf("watcher got %v on load", v)
+	}
+
+	s.set(map[string]interface{}{"ms": map[string]interface{}{
+		"name":    "changed",
+		"greeter": map[string]interface{}{"hello": map[string]interface{}{"greeting": "hi"}},
+	}})
+	if err := Reload(); err != nil {
+		t.Fatalf("failed to reload: %+v", err)
+	}
+	if v := waitFor(t, changed); v != "changed" {
+		t.Fatalf("watcher got %v", v)
+	}
+	stop()
+	if len(defaultConfig.watchersByRef) != 0 {
+		t.Fatalf("watcher not stopped: %+v", defaultConfig.watchersByRef)
+	}
+}
+
+func TestAliasRegisteredAfterConfigureConflict(t *testing.T) {
+	reset(t)
+	MustConfigure("old.name", "")
+	MustConfigure("ms.name", 0)
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("conflicting types did not panic")
+		}
+	}()
+	RegisterAlias("old.name", "ms.name")
+}
+
+func TestAliasCircular(t *testing.T) {
+	reset(t)
+	RegisterAlias("a.name", "b.name")
+	RegisterAlias("b.name", "c.name")
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("circular alias did not panic")
+		}
+	}()
+	RegisterAlias("c.name", "a.name")
+}
+
+func TestAliasConflict(t *testing.T) {
+	reset(t)
+	RegisterAlias("old.name", "ms.name")
+	RegisterAlias("old.name", "ms.name") //same is allowed
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("conflicting alias did not panic")
+		}
+	}()
+	RegisterAlias("old.name", "ms.other")
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"sort"
+)
+
+// ListableSource is a source that can list the names it has values for,
+// e.g. for auto-completion or documentation of the available config
+type ListableSource interface {
+	Source
+	Keys() []string
+}
+
+// AllKeys returns the names listed by all sources that implement ListableSource,
+// sorted and without duplicates
+// sources that cannot list their names are skipped
+func (c *Config) AllKeys() []string {
+	c.moduleDataMutex.Lock()
+	sources := c.allSources()
+	c.moduleDataMutex.Unlock()
+
+	found := map[string]bool{}
+	for _, ns := range sources {
+		if ls, ok := ns.source.(ListableSource); ok {
+			for _, key := range ls.Keys() {
+				found[key] = true
+			}
+		}
+	}
+	keys := make([]string, 0, len(found))
+	for key := range found {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestAllKeys(t *testing.T) {
+	reset(t)
+	dir := t.TempDir()
+	for filename, content := range map[string]string{
+		"a.json": `{"db":{"host":"a"},"name":"shop"}`,
+		"b.yaml": "db:\n  host: b\ncache:\n  size: 10\n",
+	} {
+		path := filepath.Join(dir, filename)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := AddFileSource(path); err != nil {
+			t.Fatalf("failed to add %s: %+v", filename, err)
+		}
+	}
+	addTestSource(t, map[string]interface{}{"hidden": 1}) //cannot list
+	DeclareDefault("log.level", "info")
+
+	expected := []string{"cache", "db", "log.level", "name"}
+	if keys := AllKeys(); !reflect.DeepEqual(keys, expected) {
+		t.Fatalf("keys %v, expected %v", keys, expected)
+	}
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"context"
+
+	"github.com/go-msvc/errors"
+)
+
+// Await waits until cond() is true for the value of ref and returns that value,
+// e.g. to wait until a provisioning flag is set:
+//
+//	v, err := config.Await(ctx, "ms.provisioned", func(v interface{}) bool { return v == true })
+//
+// cond is called with the current value, and again each time the value changes after Reload()
+// if called before Load(), it waits for Load() first
+// it returns an error wrapping ctx.Err() if ctx is done before cond is true
+// or if ref was not registered with MustConfigure() or MustConstruct()
+func (c *Config) Await(ctx context.Context, ref string, cond func(interface{}) bool) (interface{}, error) {
+	if cond == nil {
+		panic("config.Await() called with nil cond")
+	}
+	//watch before the first check, so that a change after it is not missed
+	changed := make(chan struct{}, 1)
+	stop := c.Watch(ref, func(interface{}) {
+		select {
+		case changed <- struct{}{}:
+		default: //already signalled
+		}
+	})
+	defer stop()
+
+	select {
+	case <-c.waitLoaded():
+	case <-ctx.Done():
+		return nil, errors.Wrapf(ctx.Err(), "config(%s) not loaded", ref)
+	}
+	for {
+		value, err := c.getLoaded(ref)
+		if err != nil {
+			return nil, err
+		}
+		if cond(value) {
+			return value, nil
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return nil, errors.Wrapf(ctx.Err(), "config(%s) did not meet the condition", ref)
+		}
+	}
+} //Await()
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAwait(t *testing.T) {
+	reset(t)
+	s := addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{"ready": false}})
+	MustConfigure("ms.ready", false)
+	isReady := func(v interface{}) bool { return v == true }
+
+	//waits for Load()
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		if err := Load(); err != nil {
+			t.Errorf("failed to load: %+v", err)
+		}
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := Await(ctx, "ms.ready", isReady); err == nil || !strings.Contains(err.Error(), "did not meet") {
+		t.Fatalf("got %+v before ready", err)
+	}
+
+	//blocks until changed
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		s.set(map[string]interface{}{"ms": map[string]interface{}{"ready": true}})
+		if err := Reload(); err != nil {
+			t.Errorf("failed to reload: %+v", err)
+		}
+	}()
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if v, err := Await(ctx, "ms.ready", isReady); err != nil || v != true {
+		t.Fatalf("got %v,%+v", v, err)
+	}
+
+	//already true
+	if v, err := Await(context.Background(), "ms.ready", isReady); err != nil || v != true {
+		t.Fatalf("got %v,%+v when already true", v, err)
+	}
+	if _, err := Await(context.Background(), "ms.unknown", isReady); err == nil {
+		t.Fatalf("waited for unknown")
+	}
+	if n := len(defaultConfig.watchersByRef); n != 0 {
+		t.Fatalf("%d watchers not stopped", n)
+	}
+}
+
+func TestAwaitNotLoaded(t *testing.T) {
+	reset(t)
+	MustConfigure("ms.ready", false)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Await(ctx, "ms.ready", func(interface{}) bool { return true }); err == nil || !strings.Contains(err.Error(), "not loaded") {
+		t.Fatalf("got %+v", err)
+	}
+}
@@ -1,15 +1,16 @@
 package config
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 
 	"github.com/go-msvc/data"
 	"github.com/go-msvc/errors"
-	"github.com/go-msvc/logger"
 )
 
 // indicate that your module requires a configurable value
@@ -21,64 +22,75 @@ import (
 // not matter if called multiple times, as long it has the same tmpl
 // MayConfigure for optional config - Get() will return nil if not configured
 // Must for required config - Get() will return value
-func MayConfigure(ref string, tmpl interface{}) {
-	flagConfigure(ref, tmpl, false)
-	//todo: optional not yet implemented... will fail if not configured
+func (c *Config) MayConfigure(ref string, tmpl interface{}) {
+	c.flagConfigure(ref, tmpl, false)
 }
 
-func MustConfigure(ref string, tmpl interface{}) {
-	flagConfigure(ref, tmpl, true)
+func (c *Config) MustConfigure(ref string, tmpl interface{}) {
+	c.flagConfigure(ref, tmpl, true)
 }
 
-func MayConstruct(ref string, constructedType reflect.Type) {
-	flagConstruct(ref, constructedType, false)
-	//todo: optional not yet implemented... will fail if not configured
+func (c *Config) MayConstruct(ref string, constructedType reflect.Type) {
+	c.flagConstruct(ref, constructedType, false)
 }
 
-func MustConstruct(ref string, constructedType reflect.Type) {
-	flagConstruct(ref, constructedType, true)
+func (c *Config) MustConstruct(ref string, constructedType reflect.Type) {
+	c.flagConstruct(ref, constructedType, true)
 }
 
-func flagConfigure(ref string, tmpl interface{}, required bool) {
-	if loaded {
+func (c *Config) flagConfigure(ref string, tmpl interface{}, required bool) {
+	if c.loaded {
 		panic(fmt.Sprintf("config.MustConfigure(%s) called after config.Load()", ref))
 	}
 	if !validReference(ref) {
 		panic(fmt.Sprintf("invalid config reference(%s), expecting dot-notation reference", ref))
 	}
+	ref = c.resolveAlias(ref)
 	if tmpl == nil {
 		panic(fmt.Sprintf("MustConfigure(%s) cannot configure nil", ref))
 	}
-	if existingTmpl, ok := mustConfigureByRef[ref]; ok {
+	c.addRegistration(ref, required, reflect.TypeOf(tmpl))
+	if existingTmpl, ok := c.mustConfigureByRef[ref]; ok {
 		if reflect.TypeOf(tmpl) != reflect.TypeOf(existingTmpl) {
 			panic(fmt.Sprintf("config.MustConfigure(%s) with conflicting type %v != %v already required", ref, reflect.TypeOf(tmpl), reflect.TypeOf(existingTmpl)))
 		}
+		if required && !c.requiredConfigureByRef[ref] {
+			Logger().Infof("config(%s) registered as optional is now required, see config.Registrations()", ref)
+		}
 	} else {
-		mustConfigureByRef[ref] = tmpl
+		c.mustConfigureByRef[ref] = tmpl
 	}
+	//once required, it stays required even if MayConfigure() is also called
+	c.requiredConfigureByRef[ref] = c.requiredConfigureByRef[ref] || required
 } //flagConfigure()
 
-func flagConstruct(ref string, constructedType reflect.Type, required bool) {
-	if loaded {
+func (c *Config) flagConstruct(ref string, constructedType reflect.Type, required bool) {
+	if c.loaded {
 		panic(fmt.Sprintf("config.MustConstruct(%s) called after config.Load()", ref))
 	}
 	if !validReference(ref) {
 		panic(fmt.Sprintf("invalid config reference(%s), expecting dot-notation reference", ref))
 	}
+	ref = c.resolveAlias(ref)
 	if constructedType == nil {
 		panic(fmt.Sprintf("MustConstruct(%s) cannot construct nil", ref))
 	}
-	info := constructorInfoFor(constructedType)
+	info := c.constructorInfoFor(constructedType)
 	info.Lock()
 	defer info.Unlock()
-	info.mustConstructByRef[ref] = true
-	log.Infof("MUST Construct \"%s\"", ref)
+	//once required, it stays required even if MayConstruct() is also called
+	info.mustConstructByRef[ref] = info.mustConstructByRef[ref] || required
+	if required {
+		Logger().Infof("MUST Construct \"%s\"", ref)
+	} else {
+		Logger().Infof("MAY Construct \"%s\"", ref)
+	}
 } //flagConstruct()
 
 // Register a constructor implementation
 // tmpl is the config with optional default values and implements Validator interface
-func RegisterConstructor(name string, tmpl interface{}) {
-	if loaded {
+func (c *Config) RegisterConstructor(name string, tmpl interface{}) {
+	if c.loaded {
 		panic(fmt.Sprintf("config.RegisterConstructor(%s) called after config.Load()", name))
 	}
 
@@ -103,12 +115,12 @@ func RegisterConstructor(name string, tmpl interface{}) {
 		panic(fmt.Sprintf("%T.Create(...) must return (<YourInterfaceType>,error)", tmpl))
 	}
 
-	info := constructorInfoFor(constructedType)
+	info := c.constructorInfoFor(constructedType)
 	if _, ok := info.tmplByName[name]; ok {
 		panic(fmt.Sprintf("%v constructor(name=\"%s\") is already registered!", constructedType, name))
 	}
 	info.tmplByName[name] = tmpl
-	log.Debugf("Registered %s constructor(name=\"%s\"): %T", constructedType, name, tmpl)
+	Logger().Debugf("Registered %s constructor(name=\"%s\"): %T", constructedType, name, tmpl)
 } //RegisterConstructor()
 
 // load config from all config sources
@@ -117,83 +129,209 @@ func RegisterConstructor(name string, tmpl interface{}) {
 // is not used on some code branch that was not known at the start
 // this process will load and construct all the items marked with
 // calls to Required() and MustConstruct()
-func Load() error {
-	moduleDataMutex.Lock()
-	defer moduleDataMutex.Unlock()
+//
+// hooks added with AddPreLoadHook() are called before the sources are read
+// and hooks added with AddPostLoadHook() after all was loaded
+func (c *Config) Load() error {
+	loadedNow, err := c.runPreLoadHooksAndLoad()
+	if err != nil || !loadedNow {
+		return err
+	}
+	//called without locks, so that the hooks can use the config
+	_, postLoadHooks := c.hooks()
+	return runHooks("post-load", postLoadHooks)
+}
 
+// runPreLoadHooksAndLoad calls the pre-load hooks and then loadOnce(),
+// unless the config is already loaded, and returns true if it was loaded now
+// concurrent calls wait, so that the hooks are only called again after a failed load
+// the hooks are called without the module data lock, so that they can use
+// the config, e.g. SetLoader(), but they must not call Load()
+func (c *Config) runPreLoadHooksAndLoad() (bool, error) {
+	c.loadMutex.Lock()
+	defer c.loadMutex.Unlock()
+
+	c.configByRefMutex.RLock()
+	loaded := c.loaded
+	c.configByRefMutex.RUnlock()
 	if loaded {
-		return nil //already loaded
+		return false, nil
+	}
+
+	preLoadHooks, _ := c.hooks()
+	if err := runHooks("pre-load", preLoadHooks); err != nil {
+		return false, err
+	}
+	return c.loadOnce()
+} //runPreLoadHooksAndLoad()
+
+// loadOnce loads the config unless it is already loaded
+// and returns true if it was loaded now
+func (c *Config) loadOnce() (bool, error) {
+	c.moduleDataMutex.Lock()
+	defer c.moduleDataMutex.Unlock()
+
+	if c.loaded {
+		return false, nil //already loaded
+	}
+
+	newConfig, err := c.load(context.Background(), true)
+	if err != nil {
+		return false, err
 	}
 
-	if len(sources) == 0 {
-		return errors.Errorf("no sources of config were added (call config.AddSource(...))")
+	c.configByRefMutex.Lock()
+	defer c.configByRefMutex.Unlock()
+	c.configByRef = newConfig.configByRef
+	c.sourceNameByRef = newConfig.sourceNameByRef
+	c.constructorConfigByRef = newConfig.constructorConfigByRef
+	c.loaded = true
+	close(c.loadedChan)
+	c.nextVersion(nil, c.configByRef)
+	c.notifyWatchers(nil, c.configByRef)
+	return true, nil
+} //loadOnce()
+
+// loadedConfig is the result of load()
+type loadedConfig struct {
+	configByRef            map[string]interface{}
+	sourceNameByRef        map[string]string
+	constructorConfigByRef map[string]constructorConfig
+}
+
+// constructorConfig is the config that was used to construct an item
+// so that reload can keep the item when its config did not change
+type constructorConfig struct {
+	implName string
+	config   interface{}
+}
+
+// load reads all config from the sources and constructs all items
+// into new maps without changing the live config, so that the
+// caller can decide to discard it on error
+// items with the same constructor config as in the live config are
+// not constructed again, the live item is kept
+// ctx is checked before each value is read and before items are constructed
+// with construct=false it only reads and validates, see ValidateAll()
+// the caller must hold moduleDataMutex
+func (c *Config) load(ctx context.Context, construct bool) (loadedConfig, error) {
+	if len(c.sources) == 0 {
+		return loadedConfig{}, errors.Errorf("no sources of config were added (call config.AddSource(...))")
 	}
 
-	//get all MustConfigure() values from the available sources
+	//the live config is used to keep constructed items that did not change
+	c.configByRefMutex.RLock()
+	liveConfigByRef := c.configByRef
+	liveConstructorConfigByRef := c.constructorConfigByRef
+	c.configByRefMutex.RUnlock()
+
+	//get all MustConfigure() values from the available sources with the loader
 	//the first value is used, so multiple sources can be specified for redundancy
 	//or to support a mix of sources
-	configByRef = map[string]interface{}{}
-	for ref, requiredTmpl := range mustConfigureByRef {
-		found := false
-		for _, ns := range sources {
-			configuredValue, err := ns.source.GetInto(ref, requiredTmpl)
-			if err != nil {
-				//expect value and err nil if not configured in this source,
-				//so this is treated as an error in the source, e.g.
-				//cannot connect to source or authentication error or
-				//invalid values
-				return errors.Wrapf(err, "failed to get source(%s).config(%s)", ns.name, ref)
-			}
-			if configuredValue != nil {
-				configByRef[ref] = configuredValue
-				log.Debugf("Source(%s).Configured(%s): %T", ns.name, ref, configuredValue)
-				found = true
-				break //skip other sources
+	//errors are collected so that all missing and invalid values are reported at once
+	loader := c.loader
+	if loader == nil {
+		loader = defaultLoader{}
+	}
+	tmplByRef := make(map[string]interface{}, len(c.mustConfigureByRef))
+	for ref, tmpl := range c.mustConfigureByRef {
+		tmplByRef[ref] = tmpl
+	}
+	loadedByRef, err := loader.Load(ctx, tmplByRef, c.loaderSources(c.allSources()))
+	errs := ConfigErrors{}
+	if err != nil {
+		loadErrs, ok := err.(ConfigErrors)
+		if !ok {
+			return loadedConfig{}, err
+		}
+		errs = append(errs, loadErrs...)
+	}
+	failed := map[string]bool{}
+	for _, err := range errs {
+		failed[err.Key] = true
+	}
+
+	configByRef := map[string]interface{}{}
+	sourceNameByRef := map[string]string{}
+	for ref := range c.mustConfigureByRef {
+		if failed[ref] {
+			continue
+		}
+		loaded := loadedByRef[ref]
+		configuredValue, ns := loaded.Value, namedSource{name: loaded.SourceName}
+		if configuredValue == nil {
+			if !c.requiredConfigureByRef[ref] {
+				//optional: Get() will return nil
+				configByRef[ref] = nil
+				Logger().Debugf("Optional config(%s) not found in any source", ref)
+				continue
 			}
+			errs = append(errs, ConfigError{Key: ref, Err: errors.Errorf("config(%s) not found in any source", ref)})
+			continue
+		}
+		configuredValue, err := c.decryptFields(configuredValue)
+		if err != nil {
+			errs = append(errs, ConfigError{Key: ref, Err: errors.Wrapf(err, "invalid source(%s).config(%s)", ns.name, ref)})
+			continue
+		}
+		configuredValue = resolveEnvFields(configuredValue)
+		configuredValue, err = c.runTransforms(ref, configuredValue)
+		if err != nil {
+			errs = append(errs, ConfigError{Key: ref, Err: errors.Wrapf(err, "invalid source(%s).config(%s)", ns.name, ref)})
+			continue
+		}
+		if err := validateStruct(configuredValue); err != nil {
+			errs = append(errs, ConfigError{Key: ref, Err: errors.Wrapf(err, "invalid source(%s).config(%s)", ns.name, ref)})
+			continue
 		}
-		if !found {
-			return errors.Errorf("config(%s) not found in any source", ref)
+		if err := c.runValidators(ref, configuredValue); err != nil {
+			errs = append(errs, ConfigError{Key: ref, Err: errors.Wrapf(err, "invalid source(%s).config(%s)", ns.name, ref)})
+			continue
 		}
+		configByRef[ref] = configuredValue
+		sourceNameByRef[ref] = ns.name
+		Logger().Debugf("Source(%s).Configured(%s): %T", ns.name, ref, configuredValue)
 	} //for each required config
 
 	//construct all required items
 	//start first by getting all the required values from sources
 	//so we can fail on missing/invalid config before any construction code is called
 	constructorByRef := map[string]interface{}{}
-	for constructedType, info := range constructorsByType {
-		for ref := range info.mustConstructByRef {
-			if len(info.tmplByName) == 0 {
-				return errors.Errorf("config(%s) cannot load without any registered constructors for %v", ref, constructedType)
+	constructorImplByRef := map[string]string{}
+	for constructedType, info := range c.constructorsByType {
+		for ref, required := range info.mustConstructByRef {
+			if err := ctx.Err(); err != nil {
+				return loadedConfig{}, errors.Wrapf(err, "stopped before config(%s)", ref)
 			}
-			found := false
-			var implNamedConfig map[string]interface{}
-			var ns namedSource
-			for _, ns = range sources {
-				value, err := ns.source.GetInto(ref, map[string]interface{}{})
-				if err != nil {
-					//expect value and err nil if not configured in this source,
-					//so this is treated as an error in the source, e.g.
-					//cannot connect to source or authentication error or
-					//invalid values
-					return errors.Wrapf(err, "failed to get source(%s).config(%s)", ns.name, ref)
-				}
-				if value != nil {
-					//store the value for processing below
-					implNamedConfig = value.(map[string]interface{})
-					log.Debugf("Source(%s).Configured(%s)", ns.name, ref)
-					found = true
-					break //skip other sources
-				}
+			if len(info.tmplByName) == 0 && required {
+				errs = append(errs, ConfigError{Key: ref, Err: errors.Errorf("config(%s) cannot load without any registered constructors for %v", ref, constructedType)})
+				continue
+			}
+			value, ns, err := c.getConstructorFromSources(ref, map[string]interface{}{})
+			if err != nil {
+				errs = append(errs, ConfigError{Key: ref, Err: err})
+				continue
 			}
-			if !found {
-				return errors.Errorf("config(%s) not found in any source", ref)
+			if value == nil {
+				if !required {
+					//optional: Get() will return nil
+					configByRef[ref] = nil
+					Logger().Debugf("Optional config(%s) not found in any source", ref)
+					continue
+				}
+				errs = append(errs, ConfigError{Key: ref, Err: errors.Errorf("config(%s) not found in any source", ref)})
+				continue
 			}
+			implNamedConfig := value.(map[string]interface{})
+			Logger().Debugf("Source(%s).Configured(%s)", ns.name, ref)
 
 			if len(implNamedConfig) == 0 {
-				return errors.Errorf("source(%s).config(%s) does identify an implementation as {\"<impl>\":{...}}", ns.name, ref)
+				errs = append(errs, ConfigError{Key: ref, Err: errors.Errorf("source(%s).config(%s) does identify an implementation as {\"<impl>\":{...}}", ns.name, ref)})
+				continue
 			}
 			if len(implNamedConfig) > 1 {
-				return errors.Errorf("source(%s).config(%s) identifies multiple implementations {\"<impl>\":{...}, ...} instead of just one", ns.name, ref)
+				errs = append(errs, ConfigError{Key: ref, Err: errors.Errorf("source(%s).config(%s) identifies multiple implementations {\"<impl>\":{...}, ...} instead of just one", ns.name, ref)})
+				continue
 			}
 			var implName string
 			for implName = range implNamedConfig {
@@ -209,19 +347,38 @@ func Load() error {
 				}
 				//if you get this error, config.RegisterConstructor(<implName>, ...) was not called
 				//or you misspelled the <implName> in config <ref>:{<implName>:{...}}
-				return errors.Errorf("config(%s) has no constructor for \"%s\", only for %s", ref, implName, strings.Join(registeredNames, "|"))
+				errs = append(errs, ConfigError{Key: ref, Err: errors.Errorf("config(%s) has no constructor for \"%s\", only for %s", ref, implName, strings.Join(registeredNames, "|"))})
+				continue
 			}
 
 			// get the config value into the constructor tmpl by calling the source again
 			// this time including the implName and the tmpl
 			constructorRef := ref + "." + implName
+			if fc, ok := constructorTmpl.(factoryConstructor); ok {
+				//registered with RegisterType(): the factory gets the config as a map
+				cfg, _ := implNamedConfig[implName].(map[string]interface{})
+				if cfg == nil {
+					cfg = map[string]interface{}{}
+				}
+				fc.cfg = cfg
+				if err := c.runValidators(ref, fc.cfg); err != nil {
+					errs = append(errs, ConfigError{Key: ref, Err: errors.Wrapf(err, "invalid source(%s).config(%s)", ns.name, constructorRef)})
+					continue
+				}
+				constructorByRef[ref] = fc
+				constructorImplByRef[ref] = implName
+				sourceNameByRef[ref] = ns.name
+				continue
+			}
 			constructorValue, err := ns.source.GetInto(constructorRef, constructorTmpl)
 			if err != nil {
-				return errors.Wrapf(err, "failed to get source(%s).config(%s)", ns.name, constructorRef)
+				errs = append(errs, ConfigError{Key: ref, Err: errors.Wrapf(err, "failed to get source(%s).config(%s)", ns.name, constructorRef)})
+				continue
 			}
 			if constructorValue == nil {
 				//likely internal software error - should not get here after above checks - just checked for sanity
-				return errors.Wrapf(err, "failed to get source(%s).config(%s)", ns.name, constructorRef)
+				errs = append(errs, ConfigError{Key: ref, Err: errors.Wrapf(err, "failed to get source(%s).config(%s)", ns.name, constructorRef)})
+				continue
 			}
 
 			//this is valid - proceed to next MustConstruct(ref) then construction will be
@@ -231,54 +388,96 @@ func Load() error {
 				//try to fix it
 				if converted, err := data.GetInto(constructorValue, "", constructorTmpl); err == nil {
 					constructorByRef[ref] = converted
-					log.Debugf("source(%s).Get(%s) -> %T != %T but fixed, now %T", ns.name, constructorRef, constructorValue, constructorTmpl, converted)
+					Logger().Debugf("source(%s).Get(%s) -> %T != %T but fixed, now %T", ns.name, constructorRef, constructorValue, constructorTmpl, converted)
 				} else {
-					return errors.Errorf("source(%s).Get(%s) -> %T != %T and cannot fix it... check your config source", ns.name, constructorRef, constructorValue, constructorTmpl)
+					errs = append(errs, ConfigError{Key: ref, Err: errors.Errorf("source(%s).Get(%s) -> %T != %T and cannot fix it... check your config source", ns.name, constructorRef, constructorValue, constructorTmpl)})
+					continue
 				}
 			} else {
-				log.Debugf("%s: %T:%+v", constructorRef, constructorValue, constructorValue)
+				Logger().Debugf("%s: %T:%+v", constructorRef, constructorValue, MaskSecrets(constructorValue))
 				constructorByRef[ref] = constructorValue
 			}
+			decrypted, err := c.decryptFields(constructorByRef[ref])
+			if err != nil {
+				errs = append(errs, ConfigError{Key: ref, Err: errors.Wrapf(err, "invalid source(%s).config(%s)", ns.name, constructorRef)})
+				continue
+			}
+			constructorByRef[ref] = resolveEnvFields(decrypted)
+			if err := validateStruct(constructorByRef[ref]); err != nil {
+				errs = append(errs, ConfigError{Key: ref, Err: errors.Wrapf(err, "invalid source(%s).config(%s)", ns.name, constructorRef)})
+				continue
+			}
+			if err := c.runValidators(ref, constructorByRef[ref]); err != nil {
+				errs = append(errs, ConfigError{Key: ref, Err: errors.Wrapf(err, "invalid source(%s).config(%s)", ns.name, constructorRef)})
+				continue
+			}
+			constructorImplByRef[ref] = implName
+			sourceNameByRef[ref] = ns.name
 		}
 	}
 
+	if len(errs) > 0 {
+		sort.Slice(errs, func(i, j int) bool { return errs[i].Key < errs[j].Key })
+		return loadedConfig{}, errs
+	}
+	if !construct {
+		return loadedConfig{}, nil
+	}
+
 	//all config read and validated, now do all the constructions
+	if err := ctx.Err(); err != nil {
+		return loadedConfig{}, errors.Wrapf(err, "stopped before construction")
+	}
+	newConstructorConfigByRef := map[string]constructorConfig{}
 	for constructorRef, configured := range constructorByRef {
+		cc := constructorConfig{implName: constructorImplByRef[constructorRef], config: configured}
+		newConstructorConfigByRef[constructorRef] = cc
+		if live, ok := liveConstructorConfigByRef[constructorRef]; ok && reflect.DeepEqual(live, cc) {
+			//config did not change, keep the live item
+			configByRef[constructorRef] = liveConfigByRef[constructorRef]
+			Logger().Debugf("Unchanged(%s): %T", constructorRef, configByRef[constructorRef])
+			continue
+		}
+
 		//call Create() method
 		method := reflect.ValueOf(configured).MethodByName("Create")
 		results := method.Call(nil)
 		if !results[1].IsNil() {
-			return errors.Wrapf(results[1].Interface().(error), "failed to construct %s", constructorRef)
+			return loadedConfig{}, errors.Wrapf(results[1].Interface().(error), "failed to construct %s", constructorRef)
 		}
 		if results[0].IsNil() {
-			return errors.Errorf("%T.Create() returned nil,nil", configured)
+			return loadedConfig{}, errors.Errorf("%T.Create() returned nil,nil", configured)
 		}
 
 		//store without implName (e.g. "ms.server" and not "ms.server.http")
 		created := results[0].Interface()
 		configByRef[constructorRef] = created
-		log.Debugf("Constructed(%s): %T", constructorRef, created)
+		Logger().Debugf("Constructed(%s): %T", constructorRef, created)
 	}
-
-	loaded = true
-	return nil
-} //Load()
+	return loadedConfig{
+		configByRef:            configByRef,
+		sourceNameByRef:        sourceNameByRef,
+		constructorConfigByRef: newConstructorConfigByRef,
+	}, nil
+} //load()
 
 // Get an item that you specified with MustConfigure() or MustConstruct()
 // by the time you call this, the config must exist
 // and this call will panic if not
-func Get(ref string) any {
-	if !loaded {
+func (c *Config) Get(ref string) any {
+	ref = c.resolveAlias(ref)
+	c.reloadIfDirty()
+	c.configByRefMutex.RLock()
+	defer c.configByRefMutex.RUnlock()
+	if !c.loaded {
 		panic("config.Load() not yet called")
 	}
-	if v, ok := configByRef[ref]; ok {
+	if v, ok := c.configByRef[ref]; ok {
 		return v
 	}
 	panic(fmt.Sprintf("config(%s) not found. Make sure you called config.MustConfigure() or config.MustConstruct()", ref))
 } //Get()
 
-var log = logger.New().WithLevel(logger.LevelDebug)
-
 type Constructor interface {
 	Create() (interface{}, error)
 }
@@ -303,14 +502,6 @@ func validReference(ref string) bool {
 	return refRegex.MatchString(ref)
 }
 
-var (
-	moduleDataMutex    sync.Mutex
-	mustConfigureByRef = map[string]interface{}{}
-	constructorsByType = map[reflect.Type]*constructorInfo{}
-	loaded             = false
-	configByRef        = map[string]interface{}{} //loaded or constructed values from Load()
-)
-
 type constructorInfo struct {
 	sync.Mutex
 
@@ -320,6 +511,9 @@ type constructorInfo struct {
 	//when loading the config into a new copy of the specified structure
 	tmplByName map[string]interface{} //registered implementations, value is config tmpl
 
+	//factories registered with RegisterType(), with a factoryConstructor in tmplByName
+	factoryByName map[string]func(cfg map[string]interface{}) (interface{}, error)
+
 	//these are the constructed items by config name
 	//key is the config key, excluding the implementation name
 	//e.g. if you configure ms.server.http:{...}
@@ -329,6 +523,7 @@ type constructorInfo struct {
 	//which will be an HTTP version of Server.
 	//the values are defined in MustConstruct(name, type) as:
 	//		constructorByType[type].mustConstructedByName[name] = true
+	//or in MayConstruct(name, type) as false when it is optional
 	//then during config.Load(), the constructors are called and
 	//the constructed value is
 	//		constructorByType[type].constructedByName[name] = ...
@@ -336,17 +531,18 @@ type constructorInfo struct {
 	constructedByName  map[string]interface{}
 }
 
-func constructorInfoFor(constructedType reflect.Type) *constructorInfo {
-	moduleDataMutex.Lock()
-	defer moduleDataMutex.Unlock()
-	info, ok := constructorsByType[constructedType]
+func (c *Config) constructorInfoFor(constructedType reflect.Type) *constructorInfo {
+	c.moduleDataMutex.Lock()
+	defer c.moduleDataMutex.Unlock()
+	info, ok := c.constructorsByType[constructedType]
 	if !ok {
 		info = &constructorInfo{
 			tmplByName:         map[string]interface{}{},
+			factoryByName:      map[string]func(cfg map[string]interface{}) (interface{}, error){},
 			mustConstructByRef: map[string]bool{},
 			constructedByName:  map[string]interface{}{},
 		}
-		constructorsByType[constructedType] = info
+		c.constructorsByType[constructedType] = info
 	}
 	return info
 } //constructorInfoFor()
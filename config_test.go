@@ -0,0 +1,87 @@
+package config
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/go-msvc/logger"
+)
+
+// testSource is a Source with values that tests can change before Reload()
+type testSource struct {
+	sync.Mutex
+	value map[string]interface{}
+}
+
+func (s *testSource) GetInto(name string, tmpl interface{}) (interface{}, error) {
+	s.Lock()
+	defer s.Unlock()
+	return getInto(s.value, name, tmpl)
+}
+
+func (s *testSource) set(value map[string]interface{}) {
+	s.Lock()
+	defer s.Unlock()
+	s.value = value
+}
+
+// reset replaces the default config so that each test can register and load its own config
+func reset(t *testing.T) {
+	t.Helper()
+	defaultConfig = New()
+}
+
+// addTestSource adds a testSource with the value and returns it
+func addTestSource(t *testing.T, value map[string]interface{}) *testSource {
+	t.Helper()
+	s := &testSource{value: value}
+	if err := AddSource("test", s); err != nil {
+		t.Fatalf("failed to add source: %+v", err)
+	}
+	return s
+}
+
+type testServerConfig struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+func TestLoadAndGet(t *testing.T) {
+	reset(t)
+	MustConfigure("ms.server", testServerConfig{Host: "localhost"})
+	addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{"server": map[string]interface{}{"port": 8080}}})
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	c, ok := Get("ms.server").(testServerConfig)
+	if !ok {
+		t.Fatalf("got %T instead of testServerConfig", Get("ms.server"))
+	}
+	if c.Host != "localhost" || c.Port != 8080 {
+		t.Fatalf("got %+v", c)
+	}
+}
+
+// testLogWriter keeps the logged messages
+type testLogWriter struct {
+	sync.Mutex
+	messages []string
+}
+
+func (w *testLogWriter) Write(r logger.Record) {
+	w.Lock()
+	defer w.Unlock()
+	w.messages = append(w.messages, r.Message)
+}
+
+// captureLog sends the package log to a testLogWriter until the test ends
+func captureLog(t *testing.T) *testLogWriter {
+	t.Helper()
+	w := &testLogWriter{}
+	original := Logger()
+	testLog := original.New(t.Name())
+	testLog.SetWriter(w)
+	SetLogger(testLog.WithLevel(logger.LevelDebug))
+	t.Cleanup(func() { SetLogger(original) })
+	return w
+}
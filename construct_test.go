@@ -0,0 +1,69 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var testGreeterType = reflect.TypeOf((*testGreeter)(nil)).Elem()
+
+func TestMayConstructAbsent(t *testing.T) {
+	reset(t)
+	RegisterConstructor("hello", testGreeterConfig{})
+	MayConstruct("ms.greeter", testGreeterType)
+	addTestSource(t, map[string]interface{}{})
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if v := Get("ms.greeter"); v != nil {
+		t.Fatalf("got %v for absent optional item", v)
+	}
+}
+
+func TestMayConstructPresent(t *testing.T) {
+	reset(t)
+	RegisterConstructor("hello", testGreeterConfig{})
+	MayConstruct("ms.greeter", testGreeterType)
+	addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{"greeter": map[string]interface{}{"hello": map[string]interface{}{"greeting": "hi"}}}})
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if Get("ms.greeter").(testGreeter).Greet() != "hi" {
+		t.Fatalf("optional item not constructed")
+	}
+}
+
+func TestMustConstructAbsent(t *testing.T) {
+	reset(t)
+	RegisterConstructor("hello", testGreeterConfig{})
+	MustConstruct("ms.greeter", testGreeterType)
+	addTestSource(t, map[string]interface{}{})
+	err := Load()
+	if err == nil || !strings.Contains(err.Error(), "config(ms.greeter) not found") {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+}
+
+func TestMayAndMustConstructIsRequired(t *testing.T) {
+	reset(t)
+	RegisterConstructor("hello", testGreeterConfig{})
+	MayConstruct("ms.greeter", testGreeterType)
+	MustConstruct("ms.greeter", testGreeterType)
+	MayConstruct("ms.greeter", testGreeterType)
+	addTestSource(t, map[string]interface{}{})
+	if err := Load(); err == nil {
+		t.Fatalf("loaded without required item")
+	}
+}
+
+func TestConstructUnknownImplementation(t *testing.T) {
+	reset(t)
+	RegisterConstructor("hello", testGreeterConfig{})
+	MustConstruct("ms.greeter", testGreeterType)
+	addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{"greeter": map[string]interface{}{"bye": map[string]interface{}{}}}})
+	err := Load()
+	if err == nil || !strings.Contains(err.Error(), "no constructor for \"bye\"") {
+		t.Fatalf("expected no constructor error, got %v", err)
+	}
+}
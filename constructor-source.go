@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/go-msvc/errors"
+)
+
+// AddConstructorSource adds a source that is used only for the constructor config of ref,
+// before all other sources, e.g. when service credentials are in another backend:
+//
+//	config.MustConstruct("ms.db", reflect.TypeOf((*Database)(nil)).Elem())
+//	config.AddConstructorSource("ms.db", vault)
+//
+// overrides set with SetOverride() are still used before it
+// call this before Load()
+func (c *Config) AddConstructorSource(ref string, source Source) {
+	c.checkNotFinalized(fmt.Sprintf("AddConstructorSource(%s)", ref))
+	if c.loaded {
+		panic(fmt.Sprintf("config.AddConstructorSource(%s) called after config.Load()", ref))
+	}
+	if !validReference(ref) {
+		panic(fmt.Sprintf("invalid config reference(%s), expecting dot-notation reference", ref))
+	}
+	if source == nil {
+		panic(fmt.Sprintf("config.AddConstructorSource(%s) called with nil source", ref))
+	}
+	ref = c.resolveAlias(ref)
+	c.moduleDataMutex.Lock()
+	defer c.moduleDataMutex.Unlock()
+	c.constructorSourceByRef[ref] = source
+}
+
+// ConstructorSources returns the sources added with AddConstructorSource() by ref
+func (c *Config) ConstructorSources() map[string]Source {
+	c.moduleDataMutex.Lock()
+	defer c.moduleDataMutex.Unlock()
+	sourceByRef := make(map[string]Source, len(c.constructorSourceByRef))
+	for ref, source := range c.constructorSourceByRef {
+		sourceByRef[ref] = source
+	}
+	return sourceByRef
+}
+
+// getConstructorFromSources is getFromSources() that first uses the source
+// added with AddConstructorSource() for ref, after overrides
+// the caller must hold moduleDataMutex
+func (c *Config) getConstructorFromSources(ref string, tmpl interface{}) (interface{}, namedSource, error) {
+	source, ok := c.constructorSourceByRef[ref]
+	if !ok || c.hasOverride(ref) {
+		return c.getFromSources(ref, tmpl)
+	}
+	ns := namedSource{name: "constructor:" + ref, source: source}
+	value, err := source.GetInto(ref, tmpl)
+	if err != nil {
+		return nil, ns, errors.Wrapf(err, "failed to get source(%s).config(%s)", ns.name, ref)
+	}
+	if value != nil {
+		return value, ns, nil
+	}
+	return c.getFromSources(ref, tmpl)
+}
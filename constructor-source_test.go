@@ -0,0 +1,72 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+// testShoutConfig is another testGreeter implementation
+type testShoutConfig struct {
+	Greeting string `json:"greeting"`
+}
+
+func (c testShoutConfig) Create() (testGreeter, error) {
+	return &testGreeterImpl{greeting: strings.ToUpper(c.Greeting)}, nil
+}
+
+func TestAddConstructorSource(t *testing.T) {
+	reset(t)
+	RegisterConstructor("hello", testGreeterConfig{})
+	RegisterConstructor("shout", testShoutConfig{})
+	MustConstruct("ms.greeter", testGreeterType)
+	MustConstruct("ms.other", testGreeterType)
+	addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{
+		"greeter": map[string]interface{}{"hello": map[string]interface{}{"greeting": "hi"}},
+		"other":   map[string]interface{}{"hello": map[string]interface{}{"greeting": "hey"}},
+	}})
+	secrets := &testSource{value: map[string]interface{}{"ms": map[string]interface{}{
+		"greeter": map[string]interface{}{"shout": map[string]interface{}{"greeting": "secret"}},
+		"other":   map[string]interface{}{"shout": map[string]interface{}{"greeting": "unused"}},
+	}}}
+	AddConstructorSource("ms.greeter", secrets)
+	if sources := ConstructorSources(); len(sources) != 1 || sources["ms.greeter"] != secrets {
+		t.Fatalf("sources=%+v", sources)
+	}
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if greeting := Get("ms.greeter").(testGreeter).Greet(); greeting != "SECRET" {
+		t.Fatalf("ms.greeter=%s", greeting)
+	}
+	if defaultConfig.sourceNameByRef["ms.greeter"] != "constructor:ms.greeter" {
+		t.Fatalf("ms.greeter from %s", defaultConfig.sourceNameByRef["ms.greeter"])
+	}
+	//only used for its ref
+	if greeting := Get("ms.other").(testGreeter).Greet(); greeting != "hey" {
+		t.Fatalf("ms.other=%s", greeting)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatalf("added constructor source after load")
+			}
+		}()
+		AddConstructorSource("ms.other", secrets)
+	}()
+}
+
+func TestAddConstructorSourceFallback(t *testing.T) {
+	reset(t)
+	RegisterConstructor("hello", testGreeterConfig{})
+	MustConstruct("ms.greeter", testGreeterType)
+	addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{
+		"greeter": map[string]interface{}{"hello": map[string]interface{}{"greeting": "hi"}},
+	}})
+	AddConstructorSource("ms.greeter", &testSource{value: map[string]interface{}{}})
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if greeting := Get("ms.greeter").(testGreeter).Greet(); greeting != "hi" {
+		t.Fatalf("ms.greeter=%s", greeting)
+	}
+}
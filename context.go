@@ -0,0 +1,75 @@
+package config
+
+import (
+	"context"
+	"fmt"
+)
+
+// contextKey is the key of the overrides in a context
+type contextKey struct{}
+
+// NewContext returns a context with config overrides for request-scoped config,
+// e.g. in HTTP middleware for the tenant of each request:
+//
+//	r = r.WithContext(config.NewContext(r.Context(), tenantConfig))
+//
+// names are like SetOverride(): a whole value like "ms.db" or a field in it like "ms.db.host"
+// overrides already in ctx are kept unless overridden again
+// read them with FromContext()
+func NewContext(ctx context.Context, overrides map[string]interface{}) context.Context {
+	overrideByName := ContextOverrides(ctx)
+	for name, value := range overrides {
+		if !validReference(name) {
+			panic(fmt.Sprintf("invalid config context override(%s), expecting dot-notation reference", name))
+		}
+		overrideByName[name] = value
+	}
+	return context.WithValue(ctx, contextKey{}, overrideByName)
+}
+
+// ContextOverrides returns a copy of the overrides set with NewContext(),
+// or an empty map if there are none
+func ContextOverrides(ctx context.Context) map[string]interface{} {
+	overrideByName := map[string]interface{}{}
+	if ctx == nil {
+		return overrideByName
+	}
+	if m, ok := ctx.Value(contextKey{}).(map[string]interface{}); ok {
+		for name, value := range m {
+			overrideByName[name] = value
+		}
+	}
+	return overrideByName
+}
+
+// FromContext reads name from the context overrides set with NewContext(),
+// then from the sources like GetWithFallback()
+// it returns defaultValue when name is not configured or cannot be read,
+// and the value has the type of defaultValue, so it must not be nil
+func (c *Config) FromContext(ctx context.Context, name string, defaultValue interface{}) interface{} {
+	if defaultValue == nil {
+		Logger().Errorf("FromContext(%s) called with nil default value", name)
+		return nil
+	}
+	name = c.resolveAlias(name)
+	overrideByName := map[string]interface{}{}
+	for n, v := range ContextOverrides(ctx) {
+		overrideByName[c.resolveAlias(n)] = v
+	}
+
+	var value interface{}
+	var err error
+	if hasOverrideFor(overrideByName, name) {
+		value, err = getWithOverrides(overrideByName, c.loaderSources(c.allSources()), name, defaultValue)
+	} else {
+		value, _, err = c.getFromSources(name, defaultValue)
+	}
+	if err != nil {
+		Logger().Errorf("failed to get config(%s) from context: %+v", name, err)
+		return defaultValue
+	}
+	if value == nil {
+		return defaultValue
+	}
+	return value
+} //FromContext()
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFromContext(t *testing.T) {
+	reset(t)
+	addTestSource(t, map[string]interface{}{
+		"ms": map[string]interface{}{
+			"server": map[string]interface{}{"host": "localhost", "port": 8080},
+			"name":   "shop",
+		},
+	})
+
+	ctx := NewContext(context.Background(), map[string]interface{}{
+		"ms.name":        "tenant1",
+		"ms.server.port": 9090,
+	})
+	if v := FromContext(ctx, "ms.name", ""); v != "tenant1" {
+		t.Fatalf("name %v", v)
+	}
+	//field override applied to the value from the sources
+	if v := FromContext(ctx, "ms.server", testServerConfig{}).(testServerConfig); v.Host != "localhost" || v.Port != 9090 {
+		t.Fatalf("server %+v", v)
+	}
+	if v := FromContext(ctx, "ms.server.port", 0); v != 9090 {
+		t.Fatalf("port %v", v)
+	}
+
+	//fallback to the sources and default
+	if v := FromContext(context.Background(), "ms.name", ""); v != "shop" {
+		t.Fatalf("name %v without overrides", v)
+	}
+	if v := FromContext(ctx, "ms.unknown", "none"); v != "none" {
+		t.Fatalf("unknown %v", v)
+	}
+	if v := FromContext(ctx, "ms.name", 0); v != 0 {
+		t.Fatalf("got %v for wrong type", v)
+	}
+
+	//nested contexts keep the outer overrides
+	inner := NewContext(ctx, map[string]interface{}{"ms.name": "tenant2"})
+	if v := FromContext(inner, "ms.name", ""); v != "tenant2" {
+		t.Fatalf("inner name %v", v)
+	}
+	if v := FromContext(inner, "ms.server.port", 0); v != 9090 {
+		t.Fatalf("inner port %v", v)
+	}
+	if v := FromContext(ctx, "ms.name", ""); v != "tenant1" {
+		t.Fatalf("outer name %v after inner context", v)
+	}
+	if overrides := ContextOverrides(inner); len(overrides) != 2 || overrides["ms.name"] != "tenant2" {
+		t.Fatalf("overrides %v", overrides)
+	}
+	if overrides := ContextOverrides(context.Background()); len(overrides) != 0 {
+		t.Fatalf("overrides %v", overrides)
+	}
+}
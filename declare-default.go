@@ -0,0 +1,107 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/go-msvc/errors"
+)
+
+// defaultsSourceName is the name of the source in Dump(), TakeSnapshot() etc.
+// for values from DeclareDefault()
+const defaultsSourceName = "defaults"
+
+// DeclareDefault sets an application-wide default for name,
+// used when no source has the value, e.g. in main() before Load():
+//
+//	config.DeclareDefault("ms.db.port", 5432)
+//
+// name may be a whole config value like "ms.db" or a field in it like "ms.db.port"
+// nil value removes the default
+// defaults declared after Load() are only used after the next Reload()
+func (c *Config) DeclareDefault(name string, value interface{}) {
+	c.DeclareDefaults(map[string]interface{}{name: value})
+}
+
+// DeclareDefaults is DeclareDefault() for multiple names at once
+func (c *Config) DeclareDefaults(valueByName map[string]interface{}) {
+	for name := range valueByName {
+		if !validReference(name) {
+			panic(fmt.Sprintf("invalid config default(%s), expecting dot-notation reference", name))
+		}
+	}
+	c.defaultsMutex.Lock()
+	defer c.defaultsMutex.Unlock()
+	for name, value := range valueByName {
+		name = c.resolveAlias(name)
+		if value == nil {
+			delete(c.defaultByName, name)
+			continue
+		}
+		c.defaultByName[name] = value
+	}
+}
+
+// ClearDefaults removes all defaults declared with DeclareDefault(), e.g. in tests
+func (c *Config) ClearDefaults() {
+	c.defaultsMutex.Lock()
+	defer c.defaultsMutex.Unlock()
+	c.defaultByName = map[string]interface{}{}
+}
+
+// DefaultsSource returns the source of the declared defaults,
+// which is used after all other sources
+func (c *Config) DefaultsSource() Source {
+	return defaultsSource{c: c}
+}
+
+func (c *Config) hasDefaults() bool {
+	c.defaultsMutex.Lock()
+	defer c.defaultsMutex.Unlock()
+	return len(c.defaultByName) > 0
+}
+
+type defaultsSource struct {
+	c *Config
+}
+
+// Keys returns the names of all declared defaults
+func (s defaultsSource) Keys() []string {
+	s.c.defaultsMutex.Lock()
+	defer s.c.defaultsMutex.Unlock()
+	keys := make([]string, 0, len(s.c.defaultByName))
+	for name := range s.c.defaultByName {
+		keys = append(keys, name)
+	}
+	return keys
+}
+
+// GetInto reads name from all defaults combined into one object,
+// so defaults of fields are set in the default of their parent
+func (s defaultsSource) GetInto(name string, tmpl interface{}) (interface{}, error) {
+	c := s.c
+	c.defaultsMutex.Lock()
+	names := make([]string, 0, len(c.defaultByName))
+	for n := range c.defaultByName {
+		names = append(names, n)
+	}
+	sort.Strings(names) //parents before children
+	obj := map[string]interface{}{}
+	for _, n := range names {
+		//copy so that declared values are not changed when fields are set in them
+		jsonValue, err := json.Marshal(c.defaultByName[n])
+		if err != nil {
+			c.defaultsMutex.Unlock()
+			return nil, errors.Wrapf(err, "cannot marshal default(%s)", n)
+		}
+		var value interface{}
+		if err := json.Unmarshal(jsonValue, &value); err != nil {
+			c.defaultsMutex.Unlock()
+			return nil, errors.Wrapf(err, "cannot unmarshal default(%s)", n)
+		}
+		setNested(obj, n, value)
+	}
+	c.defaultsMutex.Unlock()
+	return getInto(obj, name, tmpl)
+} //defaultsSource.GetInto()
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestDeclareDefault(t *testing.T) {
+	reset(t)
+	MustConfigure("ms.name", "")
+	MustConfigure("ms.port", 0)
+	MustConfigure("db", testValidateConfig{})
+	DeclareDefault("ms.name", "default")
+	DeclareDefault("ms.port", 80)
+	DeclareDefaults(map[string]interface{}{
+		"db":       map[string]interface{}{"host": "localhost", "name": "db", "level": "info", "code": "a,1"},
+		"db.port":  5432,
+		"db.retry": nil, //no default
+	})
+	addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{"port": 8080}})
+	if err := Load(); err == nil {
+		t.Fatalf("loaded db without retries")
+	}
+	DeclareDefault("db.retries", 3)
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	//explicit source value beats declared default
+	if Get("ms.port") != 8080 || defaultConfig.sourceNameByRef["ms.port"] != "test" {
+		t.Fatalf("ms.port=%v from %s", Get("ms.port"), defaultConfig.sourceNameByRef["ms.port"])
+	}
+	//absent source value falls back to declared default
+	if Get("ms.name") != "default" || defaultConfig.sourceNameByRef["ms.name"] != defaultsSourceName {
+		t.Fatalf("ms.name=%v from %s", Get("ms.name"), defaultConfig.sourceNameByRef["ms.name"])
+	}
+	if db := Get("db").(testValidateConfig); db.Host != "localhost" || db.Port != 5432 || *db.Retries != 3 {
+		t.Fatalf("db=%+v", db)
+	}
+
+	//overrides are applied on top of defaults
+	SetOverride("db.host", "override")
+	if db := Get("db").(testValidateConfig); db.Host != "override" || db.Port != 5432 {
+		t.Fatalf("db=%+v", db)
+	}
+
+	value, err := DefaultsSource().GetInto("db.port", 0)
+	if err != nil || value != 5432 {
+		t.Fatalf("got %v,%v", value, err)
+	}
+	DeclareDefault("db.port", nil)
+	if value, err := DefaultsSource().GetInto("db.port", 0); err != nil || value != nil {
+		t.Fatalf("removed default got %v,%v", value, err)
+	}
+	ClearDefaults()
+	if value, err := DefaultsSource().GetInto("ms.name", ""); err != nil || value != nil {
+		t.Fatalf("cleared default got %v,%v", value, err)
+	}
+}
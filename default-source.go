@@ -0,0 +1,211 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-msvc/errors"
+)
+
+// AddDefaultSource adds sources named in environment variables, so that
+// the environment decides where config comes from:
+//
+//	CONFIG_FILE=<filename>   adds a JSON or YAML file source
+//	CONFIG_URI=<uri>         adds a source with AddSourceURI()
+//	CONFIG_<suffix>=<value>  adds a source registered with RegisterAutoLoader()
+//
+// CONFIG_ETCD and CONFIG_CONSUL return an error unless a loader was registered for them
+// if none are set, it adds ./config.json, ./config.yaml or ./config.yml
+// it returns the names of the added sources, comma separated
+// sources that were already added are skipped, so it may be called more than once
+func (c *Config) AddDefaultSource() (string, error) {
+	names, err := c.addAutoSources("")
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(names, ","), nil
+} //AddDefaultSource()
+
+// AutoLoad is AddDefaultSource() with variables named <envPrefix>_CONFIG_<suffix>,
+// e.g. APP_CONFIG_FILE for prefix "APP", followed by Load()
+func (c *Config) AutoLoad(envPrefix string) error {
+	if _, err := c.addAutoSources(envPrefix); err != nil {
+		return err
+	}
+	return c.Load()
+}
+
+var (
+	autoLoaderMutex sync.Mutex
+	autoLoaders     []autoLoader //in order of registration
+)
+
+type autoLoader struct {
+	suffix  string
+	factory func(value string) (Source, error)
+}
+
+// RegisterAutoLoader registers the factory that AddDefaultSource() and AutoLoad()
+// call with the value of CONFIG_<varSuffix>, usually from init() in the package of the source,
+// e.g. config.RegisterAutoLoader("ETCD", etcd.FromEnv)
+// the source is named by the variable, and sources are added in the order they were registered
+func RegisterAutoLoader(varSuffix string, factory func(value string) (Source, error)) {
+	if varSuffix == "" || strings.ToUpper(varSuffix) != varSuffix || strings.ContainsAny(varSuffix, " =") {
+		panic(fmt.Sprintf("config.RegisterAutoLoader(%s) expects an uppercase suffix", varSuffix))
+	}
+	if varSuffix == "FILE" || varSuffix == "URI" {
+		panic(fmt.Sprintf("config.RegisterAutoLoader(%s) cannot replace the built-in loader", varSuffix))
+	}
+	if factory == nil {
+		panic(fmt.Sprintf("config.RegisterAutoLoader(%s) called with nil factory", varSuffix))
+	}
+	autoLoaderMutex.Lock()
+	defer autoLoaderMutex.Unlock()
+	for _, l := range autoLoaders {
+		if l.suffix == varSuffix {
+			panic(fmt.Sprintf("config auto loader(%s) is already registered", varSuffix))
+		}
+	}
+	autoLoaders = append(autoLoaders, autoLoader{suffix: varSuffix, factory: factory})
+}
+
+// autoVarName returns the name of the variable, e.g. "APP_CONFIG_FILE" or "CONFIG_FILE" without prefix
+func autoVarName(envPrefix, suffix string) string {
+	if envPrefix == "" {
+		return "CONFIG_" + suffix
+	}
+	return envPrefix + "_CONFIG_" + suffix
+}
+
+// addAutoSources adds the sources named in environment variables and returns their names
+func (c *Config) addAutoSources(envPrefix string) ([]string, error) {
+	names := []string{}
+	fileVar := autoVarName(envPrefix, "FILE")
+	if filename := os.Getenv(fileVar); filename != "" {
+		name, _, err := c.addFileSource(filename)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s", fileVar)
+		}
+		names = append(names, name)
+	}
+	uriVar := autoVarName(envPrefix, "URI")
+	if uri := os.Getenv(uriVar); uri != "" {
+		if _, err := c.AddSourceURI(uri); err != nil {
+			return nil, errors.Wrapf(err, "invalid %s", uriVar)
+		}
+		u, _ := url.Parse(uri) //parsed by AddSourceURI()
+		names = append(names, u.Redacted())
+	}
+
+	autoLoaderMutex.Lock()
+	loaders := append([]autoLoader{}, autoLoaders...)
+	autoLoaderMutex.Unlock()
+	registered := map[string]bool{}
+	for _, l := range loaders {
+		registered[l.suffix] = true
+		envVarName := autoVarName(envPrefix, l.suffix)
+		value := os.Getenv(envVarName)
+		if value == "" {
+			continue
+		}
+		s, err := l.factory(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s", envVarName)
+		}
+		if err := c.AddSource(envVarName, s); err != nil {
+			return nil, err
+		}
+		names = append(names, envVarName)
+	}
+	for _, suffix := range []string{"ETCD", "CONSUL"} {
+		envVarName := autoVarName(envPrefix, suffix)
+		if !registered[suffix] && os.Getenv(envVarName) != "" {
+			return nil, errors.Errorf("%s is not supported, no such source is implemented", envVarName)
+		}
+	}
+
+	if len(names) == 0 {
+		filename, err := findFile("./config")
+		if err != nil {
+			return nil, errors.Wrapf(err, "no %s and no default config file", fileVar)
+		}
+		name, _, err := c.addFileSource(filename)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, nil
+} //addAutoSources()
+
+// AddFileSource adds a source named "file:<path>" that reads a JSON or YAML file
+// the format is taken from the extension: .json, .yaml, .yml or any registered with RegisterDecoder()
+// other extensions return an error
+// an http:// or https:// URL adds a source that downloads the file and downloads it
+// again every 60s, then reloads config when it changed, and implements io.Closer to stop polling
+// a URL path without a known extension is read as JSON
+func (c *Config) AddFileSource(path string) (Source, error) {
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return c.addHTTPSource(path, defaultHTTPPollInterval)
+	}
+	if ext := filepath.Ext(path); ext == "" {
+		return nil, errors.Errorf("cannot add %s: unknown file format without extension", path)
+	} else if _, ok := LookupDecoder(ext[1:]); !ok {
+		return nil, errors.Errorf("cannot add %s: unknown file format \"%s\"", path, ext)
+	}
+	_, s, err := c.addFileSource(path)
+	return s, err
+}
+
+// addHTTPSource adds a source named after the URL, without its password,
+// that polls the URL every interval
+// a URL that was already added returns the existing source
+func (c *Config) addHTTPSource(rawURL string, interval time.Duration) (Source, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid URL")
+	}
+	for _, ns := range c.sources {
+		if ns.name == u.Redacted() {
+			return ns.source, nil //already added
+		}
+	}
+	s, err := newHTTPSource(rawURL, interval, func() error {
+		if c.Version() == 0 {
+			return nil //not yet loaded
+		}
+		return c.Reload()
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := c.AddSource(s.name, s); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// addFileSource adds a file source named "file:<filename>"
+func (c *Config) addFileSource(filename string) (string, Source, error) {
+	name := "file:" + filename
+	for _, ns := range c.sources {
+		if ns.name == name {
+			return name, ns.source, nil //already added
+		}
+	}
+	data, err := readFile(filename)
+	if err != nil {
+		return "", nil, err
+	}
+	s := file{data: data}
+	if err := c.AddSource(name, s); err != nil {
+		return "", nil, err
+	}
+	return name, s, nil
+}
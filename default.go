@@ -0,0 +1,426 @@
+package config
+
+import (
+	"context"
+	"flag"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// the package functions call the same method on the Default() config,
+// see the methods of Config for their docs
+
+func RegisterAlias(alias, original string) {
+	defaultConfig.RegisterAlias(alias, original)
+}
+
+func Alias(ref string) (string, bool) {
+	return defaultConfig.Alias(ref)
+}
+
+func MayConfigure(ref string, tmpl interface{}) {
+	defaultConfig.MayConfigure(ref, tmpl)
+}
+
+func MustConfigure(ref string, tmpl interface{}) {
+	defaultConfig.MustConfigure(ref, tmpl)
+}
+
+func MayConstruct(ref string, constructedType reflect.Type) {
+	defaultConfig.MayConstruct(ref, constructedType)
+}
+
+func MustConstruct(ref string, constructedType reflect.Type) {
+	defaultConfig.MustConstruct(ref, constructedType)
+}
+
+func RegisterConstructor(name string, tmpl interface{}) {
+	defaultConfig.RegisterConstructor(name, tmpl)
+}
+
+func AddConstructorSource(ref string, source Source) {
+	defaultConfig.AddConstructorSource(ref, source)
+}
+
+func ConstructorSources() map[string]Source {
+	return defaultConfig.ConstructorSources()
+}
+
+func DeclareDefault(name string, value interface{}) {
+	defaultConfig.DeclareDefault(name, value)
+}
+
+func DeclareDefaults(valueByName map[string]interface{}) {
+	defaultConfig.DeclareDefaults(valueByName)
+}
+
+func ClearDefaults() {
+	defaultConfig.ClearDefaults()
+}
+
+func DefaultsSource() Source {
+	return defaultConfig.DefaultsSource()
+}
+
+func Load() error {
+	return defaultConfig.Load()
+}
+
+func Get(ref string) any {
+	return defaultConfig.Get(ref)
+}
+
+func AddDefaultSource() (string, error) {
+	return defaultConfig.AddDefaultSource()
+}
+
+func AddFileSource(path string) (Source, error) {
+	return defaultConfig.AddFileSource(path)
+}
+
+func PrintDefaults(w io.Writer) {
+	defaultConfig.PrintDefaults(w)
+}
+
+func PrintDefaultsString() string {
+	return defaultConfig.PrintDefaultsString()
+}
+
+func AddUsageToFlagSet(fs *flag.FlagSet) {
+	defaultConfig.AddUsageToFlagSet(fs)
+}
+
+func Dump() map[string]interface{} {
+	return defaultConfig.Dump()
+}
+
+func DumpJSON() ([]byte, error) {
+	return defaultConfig.DumpJSON()
+}
+
+func BindEnv(configName, envVarName string) {
+	defaultConfig.BindEnv(configName, envVarName)
+}
+
+func BindEnvPrefix(prefix string) {
+	defaultConfig.BindEnvPrefix(prefix)
+}
+
+func AddEnvSource(prefix string) (Source, error) {
+	return defaultConfig.AddEnvSource(prefix)
+}
+
+func Export(w io.Writer, format string) error {
+	return defaultConfig.Export(w, format)
+}
+
+func ExportFile(path, format string) error {
+	return defaultConfig.ExportFile(path, format)
+}
+
+func ExportForDiff(w io.Writer) error {
+	return defaultConfig.ExportForDiff(w)
+}
+
+func GetStringMap(ref string) (map[string]interface{}, error) {
+	return defaultConfig.GetStringMap(ref)
+}
+
+func GetStringSlice(ref string) ([]string, error) {
+	return defaultConfig.GetStringSlice(ref)
+}
+
+func GetWithFallback(names []string, defaultValue interface{}) (interface{}, error) {
+	return defaultConfig.GetWithFallback(names, defaultValue)
+}
+
+func LazyGet(ref string, defaultValue interface{}) func() (interface{}, error) {
+	return defaultConfig.LazyGet(ref, defaultValue)
+}
+
+func LazyMust(ref string, defaultValue interface{}) func() interface{} {
+	return defaultConfig.LazyMust(ref, defaultValue)
+}
+
+func EnsureWritable(name string, ws WritableSource) {
+	defaultConfig.EnsureWritable(name, ws)
+}
+
+func Set(name string, value interface{}) error {
+	return defaultConfig.Set(name, value)
+}
+
+func SetOverride(name string, value interface{}) {
+	defaultConfig.SetOverride(name, value)
+}
+
+func ClearOverride(name string) {
+	defaultConfig.ClearOverride(name)
+}
+
+func ClearAllOverrides() {
+	defaultConfig.ClearAllOverrides()
+}
+
+func RegisterType(typeName string, constructedType reflect.Type, factory func(cfg map[string]interface{}) (interface{}, error)) {
+	defaultConfig.RegisterType(typeName, constructedType, factory)
+}
+
+func Reload() error {
+	return defaultConfig.Reload()
+}
+
+func ReloadContext(ctx context.Context) error {
+	return defaultConfig.ReloadContext(ctx)
+}
+
+func OnReloadError(fn func(error)) {
+	defaultConfig.OnReloadError(fn)
+}
+
+func WatchSignal(sig ...os.Signal) func() {
+	return defaultConfig.WatchSignal(sig...)
+}
+
+func Require(ref string, defaultValue interface{}) func() (interface{}, error) {
+	return defaultConfig.Require(ref, defaultValue)
+}
+
+func Optional(ref string, defaultValue interface{}) func() (interface{}, error) {
+	return defaultConfig.Optional(ref, defaultValue)
+}
+
+func Schema() map[string]interface{} {
+	return defaultConfig.Schema()
+}
+
+func SchemaJSON() ([]byte, error) {
+	return defaultConfig.SchemaJSON()
+}
+
+func TakeSnapshot() (Snapshot, error) {
+	return defaultConfig.TakeSnapshot()
+}
+
+func AddSource(name string, source Source, opts ...SourceOption) error {
+	return defaultConfig.AddSource(name, source, opts...)
+}
+
+func AddSourceWithPriority(priority int, name string, source Source, opts ...SourceOption) error {
+	return defaultConfig.AddSourceWithPriority(priority, name, source, opts...)
+}
+
+func Struct(prefix string, tmpl interface{}) (interface{}, error) {
+	return defaultConfig.Struct(prefix, tmpl)
+}
+
+func MustStruct(prefix string, tmpl interface{}) interface{} {
+	return defaultConfig.MustStruct(prefix, tmpl)
+}
+
+func Sub(prefix string) ISubConfig {
+	return defaultConfig.Sub(prefix)
+}
+
+func AddValidator(name string, fn func(value interface{}) error) func() {
+	return defaultConfig.AddValidator(name, fn)
+}
+
+func Version() uint64 {
+	return defaultConfig.Version()
+}
+
+func VersionOf(ref string) (uint64, bool) {
+	return defaultConfig.VersionOf(ref)
+}
+
+func OnVersion(targetVersion uint64, fn func()) {
+	defaultConfig.OnVersion(targetVersion, fn)
+}
+
+func LoadedAt() time.Time {
+	return defaultConfig.LoadedAt()
+}
+
+func Age() time.Duration {
+	return defaultConfig.Age()
+}
+
+func CheckMaxAge(maxAge time.Duration) error {
+	return defaultConfig.CheckMaxAge(maxAge)
+}
+
+func GetContext(ctx context.Context, ref string) (interface{}, error) {
+	return defaultConfig.GetContext(ctx, ref)
+}
+
+func WaitReady(ctx context.Context) error {
+	return defaultConfig.WaitReady(ctx)
+}
+
+func Ready() bool {
+	return defaultConfig.Ready()
+}
+
+func ReadyCount() (ready, total int) {
+	return defaultConfig.ReadyCount()
+}
+
+func Watch(ref string, fn func(newValue interface{})) func() {
+	return defaultConfig.Watch(ref, fn)
+}
+
+func SetLoader(l Loader) {
+	defaultConfig.SetLoader(l)
+}
+
+func ValidateAll() error {
+	return defaultConfig.ValidateAll()
+}
+
+func ValidateAllContext(ctx context.Context) error {
+	return defaultConfig.ValidateAllContext(ctx)
+}
+
+func Invalidate() {
+	defaultConfig.Invalidate()
+}
+
+func IsDirty() bool {
+	return defaultConfig.IsDirty()
+}
+
+func FlushCache() int {
+	return defaultConfig.FlushCache()
+}
+
+func FlushCacheFor(name string) int {
+	return defaultConfig.FlushCacheFor(name)
+}
+
+func Registrations() []Registration {
+	return defaultConfig.Registrations()
+}
+
+func AddSourceURI(uri string) (Source, error) {
+	return defaultConfig.AddSourceURI(uri)
+}
+
+func AddSourceURIOrPanic(uri string) Source {
+	return defaultConfig.AddSourceURIOrPanic(uri)
+}
+
+func AddTransform(ref string, fn func(value interface{}) (interface{}, error)) func() {
+	return defaultConfig.AddTransform(ref, fn)
+}
+
+func AllKeys() []string {
+	return defaultConfig.AllKeys()
+}
+
+func SetAll(values map[string]interface{}) error {
+	return defaultConfig.SetAll(values)
+}
+
+func HealthCheck(ctx context.Context) error {
+	return defaultConfig.HealthCheck(ctx)
+}
+
+func HealthCheckAll(ctx context.Context) map[string]error {
+	return defaultConfig.HealthCheckAll(ctx)
+}
+
+func AutoLoad(envPrefix string) error {
+	return defaultConfig.AutoLoad(envPrefix)
+}
+
+func SetEncryptionKey(key []byte) {
+	defaultConfig.SetEncryptionKey(key)
+}
+
+func EncryptFile(inputPath, outputPath string, key []byte) error {
+	return defaultConfig.EncryptFile(inputPath, outputPath, key)
+}
+
+func Subscribe(ref string) <-chan interface{} {
+	return defaultConfig.Subscribe(ref)
+}
+
+func Unsubscribe(ch <-chan interface{}) {
+	defaultConfig.Unsubscribe(ch)
+}
+
+func FromContext(ctx context.Context, name string, defaultValue interface{}) interface{} {
+	return defaultConfig.FromContext(ctx, name, defaultValue)
+}
+
+func Finalize() {
+	defaultConfig.Finalize()
+}
+
+func IsFinalized() bool {
+	return defaultConfig.IsFinalized()
+}
+
+func AllowReset(t testing.TB) {
+	defaultConfig.AllowReset(t)
+}
+
+func SetMergeStrategy(ms MergeStrategy) {
+	defaultConfig.SetMergeStrategy(ms)
+}
+
+func CurrentMergeStrategy() MergeStrategy {
+	return defaultConfig.CurrentMergeStrategy()
+}
+
+func AddPreLoadHook(fn func() error) {
+	defaultConfig.AddPreLoadHook(fn)
+}
+
+func AddPostLoadHook(fn func() error) {
+	defaultConfig.AddPostLoadHook(fn)
+}
+
+func ClearHooks() {
+	defaultConfig.ClearHooks()
+}
+
+func Await(ctx context.Context, ref string, cond func(interface{}) bool) (interface{}, error) {
+	return defaultConfig.Await(ctx, ref, cond)
+}
+
+func WhenReady(ref string, fn func(interface{})) {
+	defaultConfig.WhenReady(ref, fn)
+}
+
+func WhenAllReady(fn func()) {
+	defaultConfig.WhenAllReady(fn)
+}
+
+func OnLoadError(fn func(context string, err error)) func() {
+	return defaultConfig.OnLoadError(fn)
+}
+
+func LoadErrorCount() uint64 {
+	return defaultConfig.LoadErrorCount()
+}
+
+func Field(name string) (FieldInfo, error) {
+	return defaultConfig.Field(name)
+}
+
+func Fields() map[string]FieldInfo {
+	return defaultConfig.Fields()
+}
+
+func NewNamespace(prefix string) *Namespace {
+	return defaultConfig.NewNamespace(prefix)
+}
+
+func ListNamespaces() []*Namespace {
+	return defaultConfig.ListNamespaces()
+}
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// PrintDefaults prints all registered config with the type, default value
+// and doc of each field, like flag.PrintDefaults() does for flags, e.g.:
+//
+//	ms.server main.ServerConfig (required)
+//	  host string = "localhost"	server host
+//	  password string = [REDACTED]
+//	  tls *main.TLSConfig (optional)
+//	    cert string = ""
+//
+// default values are taken from the templates passed to MustConfigure() etc.
+// and secret fields show [REDACTED]
+// fields tagged doc:"-" are not printed
+func (c *Config) PrintDefaults(w io.Writer) {
+	c.moduleDataMutex.Lock()
+	defer c.moduleDataMutex.Unlock()
+
+	type entry struct {
+		required   bool
+		tmpl       interface{}            //configured template
+		tmplByName map[string]interface{} //or constructor templates
+	}
+	entries := map[string]entry{}
+	for ref, tmpl := range c.mustConfigureByRef {
+		entries[ref] = entry{required: c.requiredConfigureByRef[ref], tmpl: tmpl}
+	}
+	for _, info := range c.constructorsByType {
+		for ref, required := range info.mustConstructByRef {
+			entries[ref] = entry{required: required, tmplByName: info.tmplByName}
+		}
+	}
+	refs := make([]string, 0, len(entries))
+	for ref := range entries {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+
+	for _, ref := range refs {
+		e := entries[ref]
+		requiredText := "optional"
+		if e.required {
+			requiredText = "required"
+		}
+		if e.tmplByName == nil {
+			printDefault(w, "", ref, reflect.ValueOf(e.tmpl), "("+requiredText+")", "", false, map[reflect.Type]bool{})
+			continue
+		}
+		implNames := make([]string, 0, len(e.tmplByName))
+		for implName := range e.tmplByName {
+			implNames = append(implNames, implName)
+		}
+		sort.Strings(implNames)
+		fmt.Fprintf(w, "%s (%s, one of %s)\n", ref, requiredText, strings.Join(implNames, "|"))
+		for _, implName := range implNames {
+			printDefault(w, "  ", implName, reflect.ValueOf(e.tmplByName[implName]), "", "", false, map[reflect.Type]bool{})
+		}
+	}
+} //PrintDefaults()
+
+// PrintDefaultsString returns the output of PrintDefaults()
+func (c *Config) PrintDefaultsString() string {
+	buf := bytes.NewBuffer(nil)
+	c.PrintDefaults(buf)
+	return buf.String()
+}
+
+// AddUsageToFlagSet sets fs.Usage to print the flags followed by PrintDefaults()
+func (c *Config) AddUsageToFlagSet(fs *flag.FlagSet) {
+	fs.Usage = func() {
+		out := fs.Output()
+		fmt.Fprintf(out, "Usage of %s:\n", fs.Name())
+		fs.PrintDefaults()
+		fmt.Fprintf(out, "\nConfig:\n")
+		c.PrintDefaults(out)
+	}
+}
+
+// printDefault prints one line for the value and then one line for each field
+// of a struct value, indented by two more spaces
+// the value of a secret is not printed
+// onPath has the struct types being printed above this value, so that a
+// recursive type like Next *Node is printed as (recursive) instead of again
+func printDefault(w io.Writer, indent string, name string, v reflect.Value, annotation string, doc string, secret bool, onPath map[reflect.Type]bool) {
+	t := v.Type()
+	line := indent + name + " " + t.String()
+	if annotation != "" {
+		line += " " + annotation
+	}
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v = reflect.Zero(v.Type().Elem())
+			break
+		}
+		v = v.Elem()
+	}
+	isStruct := v.Kind() == reflect.Struct && v.Type() != timeType && v.Type() != ipNetType && v.Type() != urlType && v.Type() != envType && !secret
+	recursive := isStruct && onPath[v.Type()]
+	if secret {
+		line += " = [REDACTED]"
+	} else if recursive {
+		line += " (recursive)"
+	} else if !isStruct {
+		line += " = " + defaultText(v)
+	}
+	if doc != "" {
+		line += "\t" + doc
+	}
+	fmt.Fprintln(w, line)
+	if isStruct && !recursive {
+		onPath[v.Type()] = true
+		printFields(w, indent+"  ", v, onPath)
+		delete(onPath, v.Type())
+	}
+} //printDefault()
+
+func printFields(w io.Writer, indent string, v reflect.Value, onPath map[reflect.Type]bool) {
+	iterFields(v.Type(), func(jsonName, docString string, f reflect.StructField) {
+		annotation := ""
+		if f.Type.Kind() == reflect.Ptr {
+			annotation = "(optional)"
+		}
+		printDefault(w, indent, jsonName, v.FieldByIndex(f.Index), annotation, docString, SecretField(f), onPath)
+	})
+} //printFields()
+
+// defaultText formats a default value as JSON, e.g. "localhost", 8080 or ["a","b"]
+func defaultText(v reflect.Value) string {
+	if !v.IsValid() || !v.CanInterface() {
+		return "null"
+	}
+	jsonValue, err := json.Marshal(dumpValue(v))
+	if err != nil {
+		return fmt.Sprintf("%v", v.Interface())
+	}
+	return string(jsonValue)
+}
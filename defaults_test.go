@@ -0,0 +1,74 @@
+package config
+
+import (
+	"bytes"
+	"flag"
+	"strings"
+	"testing"
+)
+
+type testDefaultsConfig struct {
+	Host     string            `json:"host" doc:"server host"`
+	Port     int               `json:"port"`
+	Password string            `json:"password" secret:"true"`
+	TLS      *testNestedConfig `json:"tls" doc:"TLS settings"`
+}
+
+func TestPrintDefaults(t *testing.T) {
+	reset(t)
+	MustConfigure("ms.server", testDefaultsConfig{Host: "localhost", Port: 8080, Password: "secret"})
+	MayConfigure("ms.name", "abc")
+	RegisterConstructor("hello", testGreeterConfig{Greeting: "hi"})
+	MustConstruct("ms.greeter", testGreeterType)
+	expected := strings.Join([]string{
+		"ms.greeter (required, one of hello)",
+		"  hello config.testGreeterConfig",
+		"    greeting string = \"hi\"",
+		"ms.name string (optional) = \"abc\"",
+		"ms.server config.testDefaultsConfig (required)",
+		"  host string = \"localhost\"\tserver host",
+		"  port int = 8080",
+		"  password string = [REDACTED]",
+		"  tls *config.testNestedConfig (optional)\tTLS settings",
+		"    weight float64 = 0",
+		"",
+	}, "\n")
+	if s := PrintDefaultsString(); s != expected {
+		t.Fatalf("got:\n%s\nexpected:\n%s", s, expected)
+	}
+}
+
+type testDefaultsNode struct {
+	Name string            `json:"name"`
+	Next *testDefaultsNode `json:"next"`
+}
+
+func TestPrintDefaultsRecursiveType(t *testing.T) {
+	reset(t)
+	MustConfigure("ms.node", testDefaultsNode{Name: "a"})
+	expected := strings.Join([]string{
+		"ms.node config.testDefaultsNode (required)",
+		"  name string = \"a\"",
+		"  next *config.testDefaultsNode (optional) (recursive)",
+		"",
+	}, "\n")
+	if s := PrintDefaultsString(); s != expected {
+		t.Fatalf("got:\n%s\nexpected:\n%s", s, expected)
+	}
+}
+
+func TestAddUsageToFlagSet(t *testing.T) {
+	reset(t)
+	MustConfigure("ms.name", "abc")
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("mode", "fast", "processing mode")
+	buf := bytes.NewBuffer(nil)
+	fs.SetOutput(buf)
+	AddUsageToFlagSet(fs)
+	fs.Usage()
+	for _, s := range []string{"Usage of test:", "-mode", "processing mode", "Config:", "ms.name string (required) = \"abc\""} {
+		if !strings.Contains(buf.String(), s) {
+			t.Fatalf("usage does not contain %q:\n%s", s, buf.String())
+		}
+	}
+}
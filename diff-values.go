@@ -0,0 +1,109 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// FieldChange is a field that differs between two values of the same type
+// Path is the dot-notation of json field names, with [i] for slice elements,
+// e.g. "servers[1].port", or "" when the values are not structs, maps or slices
+type FieldChange struct {
+	Path     string      `json:"path"`
+	Kind     ChangeType  `json:"kind"`
+	OldValue interface{} `json:"old_value,omitempty"`
+	NewValue interface{} `json:"new_value,omitempty"`
+}
+
+// DiffValues returns the fields that differ between oldValue and newValue, sorted by path,
+// e.g. to log what changed in a Watch() callback
+// a field that is nil in oldValue is added, one that is nil in newValue is deleted,
+// and secret fields are compared, but reported as "***"
+// (Diff() compares snapshots)
+// it panics if the values do not have the same type
+func DiffValues(oldValue, newValue interface{}) []FieldChange {
+	if oldValue != nil && newValue != nil && reflect.TypeOf(oldValue) != reflect.TypeOf(newValue) {
+		panic(fmt.Sprintf("config.DiffValues(%T, %T) with different types", oldValue, newValue))
+	}
+	changes := diffFields(nil, "", dumpRaw(reflect.ValueOf(oldValue)), dumpRaw(reflect.ValueOf(newValue)))
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// DiffValuesString is DiffValues() with one line per change, e.g.
+//
+//	db.port: modified 5432 -> 5433
+//	db.user: added "admin"
+func DiffValuesString(oldValue, newValue interface{}) string {
+	lines := []string{}
+	for _, change := range DiffValues(oldValue, newValue) {
+		switch change.Kind {
+		case ChangeAdded:
+			lines = append(lines, fmt.Sprintf("%s: %s %s", change.Path, change.Kind, diffText(change.NewValue)))
+		case ChangeDeleted:
+			lines = append(lines, fmt.Sprintf("%s: %s %s", change.Path, change.Kind, diffText(change.OldValue)))
+		default:
+			lines = append(lines, fmt.Sprintf("%s: %s %s -> %s", change.Path, change.Kind, diffText(change.OldValue), diffText(change.NewValue)))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func diffText(value interface{}) string {
+	if jsonValue, err := json.Marshal(value); err == nil {
+		return string(jsonValue)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// diffFields appends the changes between two results of dumpRaw()
+func diffFields(changes []FieldChange, path string, oldValue, newValue interface{}) []FieldChange {
+	if reflect.DeepEqual(oldValue, newValue) {
+		return changes
+	}
+	switch {
+	case oldValue == nil:
+		return append(changes, FieldChange{Path: path, Kind: ChangeAdded, NewValue: redact(newValue)})
+	case newValue == nil:
+		return append(changes, FieldChange{Path: path, Kind: ChangeDeleted, OldValue: redact(oldValue)})
+	}
+	if oldObj, ok := oldValue.(map[string]interface{}); ok {
+		if newObj, ok := newValue.(map[string]interface{}); ok {
+			for name, ov := range oldObj {
+				changes = diffFields(changes, fieldPath(path, name), ov, newObj[name])
+			}
+			for name, nv := range newObj {
+				if _, ok := oldObj[name]; !ok {
+					changes = diffFields(changes, fieldPath(path, name), nil, nv)
+				}
+			}
+			return changes
+		}
+	}
+	if oldList, ok := oldValue.([]interface{}); ok {
+		if newList, ok := newValue.([]interface{}); ok {
+			for i := 0; i < len(oldList) || i < len(newList); i++ {
+				var ov, nv interface{}
+				if i < len(oldList) {
+					ov = oldList[i]
+				}
+				if i < len(newList) {
+					nv = newList[i]
+				}
+				changes = diffFields(changes, fmt.Sprintf("%s[%d]", path, i), ov, nv)
+			}
+			return changes
+		}
+	}
+	return append(changes, FieldChange{Path: path, Kind: ChangeModified, OldValue: redact(oldValue), NewValue: redact(newValue)})
+} //diffFields()
+
+func fieldPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testDiffDB struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Password string `json:"password" secret:"true"`
+}
+
+type testDiffConfig struct {
+	Name    string      `json:"name"`
+	DB      testDiffDB  `json:"db"`
+	Backup  *testDiffDB `json:"backup,omitempty"`
+	Servers []string    `json:"servers"`
+}
+
+func TestDiffValues(t *testing.T) {
+	old := testDiffConfig{
+		Name:    "shop",
+		DB:      testDiffDB{Host: "db1", Port: 5432, Password: "a"},
+		Servers: []string{"s1", "s2", "s3"},
+	}
+	changed := testDiffConfig{
+		Name:    "shop",
+		DB:      testDiffDB{Host: "db2", Port: 5432, Password: "b"},
+		Backup:  &testDiffDB{Host: "db3"},
+		Servers: []string{"s1", "s4"},
+	}
+	expected := []FieldChange{
+		{Path: "backup", Kind: ChangeAdded, NewValue: map[string]interface{}{"host": "db3", "port": 0, "password": "***"}},
+		{Path: "db.host", Kind: ChangeModified, OldValue: "db1", NewValue: "db2"},
+		{Path: "db.password", Kind: ChangeModified, OldValue: "***", NewValue: "***"},
+		{Path: "servers[1]", Kind: ChangeModified, OldValue: "s2", NewValue: "s4"},
+		{Path: "servers[2]", Kind: ChangeDeleted, OldValue: "s3"},
+	}
+	if changes := DiffValues(old, changed); !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("changes:\n%+v\nexpected:\n%+v", changes, expected)
+	}
+
+	//pointer to nil is deleted
+	changes := DiffValues(changed, testDiffConfig{Name: "shop", DB: changed.DB, Servers: changed.Servers})
+	if len(changes) != 1 || changes[0].Path != "backup" || changes[0].Kind != ChangeDeleted {
+		t.Fatalf("changes %+v", changes)
+	}
+
+	if changes := DiffValues(old, old); len(changes) != 0 {
+		t.Fatalf("changes without differences: %+v", changes)
+	}
+	if changes := DiffValues(1, 2); len(changes) != 1 || changes[0].Path != "" {
+		t.Fatalf("scalar changes %+v", changes)
+	}
+}
+
+func TestDiffValuesString(t *testing.T) {
+	old := testDiffDB{Host: "db1", Port: 5432}
+	changed := testDiffDB{Host: "db1", Port: 5433, Password: "x"}
+	expected := "password: modified \"***\" -> \"***\"\nport: modified 5432 -> 5433"
+	if s := DiffValuesString(old, changed); s != expected {
+		t.Fatalf("got:\n%s\nexpected:\n%s", s, expected)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("no panic for different types")
+		}
+	}()
+	DiffValues(old, &changed)
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"reflect"
+)
+
+// FieldDoc returns the doc:"..." tag of the struct field with the json name
+// it returns "" if there is no such field or it is hidden with doc:"-"
+// the doc may contain markdown and is used as is
+func FieldDoc(t reflect.Type, jsonTagName string) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return ""
+	}
+	doc := ""
+	iterFields(t, func(jsonName, docString string, f reflect.StructField) {
+		if jsonName == jsonTagName {
+			doc = docString
+		}
+	})
+	return doc
+}
+
+// iterFields calls fn for each field of a struct type that appears in JSON
+// with its json name and doc, flattening embedded structs like JSON does
+// f.Index is the index sequence from t, to use with reflect.Value.FieldByIndex()
+// fields tagged doc:"-" are hidden from documentation and skipped
+func iterFields(t reflect.Type, fn func(jsonName, docString string, f reflect.StructField)) {
+	iterFieldsIndex(t, nil, fn)
+}
+
+func iterFieldsIndex(t reflect.Type, index []int, fn func(jsonName, docString string, f reflect.StructField)) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		f.Index = append(append([]int{}, index...), i)
+		name, _ := JSONName(f)
+		if name == "" {
+			continue
+		}
+		if isEmbeddedStruct(f) {
+			iterFieldsIndex(f.Type, f.Index, fn) //embedded struct fields are flattened like JSON does
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		doc := DocField(f)
+		if doc == "-" {
+			continue
+		}
+		fn(name, doc, f)
+	}
+} //iterFieldsIndex()
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testDocEmbedded struct {
+	Region string `json:"region" doc:"AWS *region*"`
+}
+
+type testDocConfig struct {
+	testDocEmbedded
+	Host     string `json:"host" doc:"server host"`
+	Port     int    `doc:"server port"`
+	Internal string `json:"internal" doc:"-"`
+	Ignored  string `json:"-" doc:"not in JSON"`
+	private  string
+}
+
+func TestFieldDoc(t *testing.T) {
+	typ := reflect.TypeOf(testDocConfig{})
+	for name, expected := range map[string]string{
+		"host":     "server host",
+		"Port":     "server port",
+		"region":   "AWS *region*",
+		"internal": "",
+		"Ignored":  "",
+		"unknown":  "",
+	} {
+		if doc := FieldDoc(typ, name); doc != expected {
+			t.Fatalf("FieldDoc(%s)=%q, expected %q", name, doc, expected)
+		}
+	}
+	if doc := FieldDoc(reflect.TypeOf(&testDocConfig{}), "host"); doc != "server host" {
+		t.Fatalf("FieldDoc(pointer)=%q", doc)
+	}
+	if doc := FieldDoc(reflect.TypeOf(""), "host"); doc != "" {
+		t.Fatalf("FieldDoc(string)=%q", doc)
+	}
+}
+
+func TestIterFields(t *testing.T) {
+	v := reflect.ValueOf(testDocConfig{testDocEmbedded: testDocEmbedded{Region: "eu"}, Host: "h", Port: 1})
+	names := []string{}
+	values := []interface{}{}
+	iterFields(v.Type(), func(jsonName, docString string, f reflect.StructField) {
+		names = append(names, jsonName)
+		values = append(values, v.FieldByIndex(f.Index).Interface())
+	})
+	if !reflect.DeepEqual(names, []string{"region", "host", "Port"}) {
+		t.Fatalf("names=%+v", names)
+	}
+	if !reflect.DeepEqual(values, []interface{}{"eu", "h", 1}) {
+		t.Fatalf("values=%+v", values)
+	}
+}
+
+func TestDocHidden(t *testing.T) {
+	reset(t)
+	MustConfigure("ms.server", testDocConfig{})
+	if s := PrintDefaultsString(); strings.Contains(s, "internal") || !strings.Contains(s, "region string = \"\"\tAWS *region*") {
+		t.Fatalf("PrintDefaults:\n%s", s)
+	}
+	def := Schema()["definitions"].(map[string]interface{})["github.com/go-msvc/config.testDocConfig"].(map[string]interface{})
+	properties := def["properties"].(map[string]interface{})
+	if _, ok := properties["internal"]; ok {
+		t.Fatalf("hidden field in schema")
+	}
+	if properties["region"].(map[string]interface{})["description"] != "AWS *region*" {
+		t.Fatalf("region=%+v", properties["region"])
+	}
+}
@@ -0,0 +1,178 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Dump returns all loaded config values in a nested map for debugging
+// e.g. values loaded for "ms.server" and "ms.name" are returned as
+//
+//	{"ms":{"server":{...}, "name":...}}
+//
+// struct fields tagged with `secret:"true"` are replaced with "***"
+// it returns an empty map if config is not yet loaded
+func (c *Config) Dump() map[string]interface{} {
+	c.configByRefMutex.RLock()
+	defer c.configByRefMutex.RUnlock()
+
+	//sort the refs so that parents are set before children
+	refs := make([]string, 0, len(c.configByRef))
+	for ref := range c.configByRef {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+
+	dump := map[string]interface{}{}
+	for _, ref := range refs {
+		setNested(dump, ref, dumpValue(reflect.ValueOf(c.configByRef[ref])))
+	}
+	return dump
+} //Dump()
+
+// DumpJSON returns Dump() as indented JSON
+func (c *Config) DumpJSON() ([]byte, error) {
+	return json.MarshalIndent(c.Dump(), "", "  ")
+}
+
+// setNested sets the value in the nested map using the dot-notation ref
+// creating the parent maps where they do not yet exist
+func setNested(m map[string]interface{}, ref string, value interface{}) {
+	names := strings.Split(ref, ".")
+	for _, name := range names[:len(names)-1] {
+		sub, ok := m[name].(map[string]interface{})
+		if !ok {
+			sub = map[string]interface{}{}
+			m[name] = sub
+		}
+		m = sub
+	}
+	m[names[len(names)-1]] = value
+}
+
+// dumpValue converts a value to maps, slices and scalars
+// using json tags for struct field names and masking secret fields
+func dumpValue(v reflect.Value) interface{} {
+	return redact(dumpRaw(v))
+}
+
+// secretValue is the value of a secret field in the result of dumpRaw()
+type secretValue struct {
+	value interface{}
+}
+
+// redact replaces secretValues in the result of dumpRaw() with "***"
+func redact(value interface{}) interface{} {
+	switch value := value.(type) {
+	case secretValue:
+		return "***"
+	case map[string]interface{}:
+		obj := make(map[string]interface{}, len(value))
+		for n, v := range value {
+			obj[n] = redact(v)
+		}
+		return obj
+	case []interface{}:
+		list := make([]interface{}, len(value))
+		for i, v := range value {
+			list[i] = redact(v)
+		}
+		return list
+	default:
+		return value
+	}
+}
+
+// dumpRaw is dumpValue() with secret fields stored as secretValue
+// so that they can be compared before they are masked
+func dumpRaw(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	if v.CanInterface() && isLeaf(v.Type()) {
+		if _, ok := v.Interface().(json.Marshaler); ok {
+			return v.Interface() //e.g. time.Time
+		}
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return dumpRaw(v.Elem())
+	case reflect.Struct:
+		obj := map[string]interface{}{}
+		dumpStruct(obj, v)
+		return obj
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		obj := map[string]interface{}{}
+		iter := v.MapRange()
+		for iter.Next() {
+			obj[fmt.Sprintf("%v", iter.Key().Interface())] = dumpRaw(iter.Value())
+		}
+		return obj
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		list := make([]interface{}, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			list[i] = dumpRaw(v.Index(i))
+		}
+		return list
+	default:
+		if !v.CanInterface() {
+			return nil
+		}
+		return v.Interface()
+	}
+} //dumpRaw()
+
+// isLeaf is true for types that cannot contain secret fields,
+// i.e. not maps, slices or pointers and not structs with exported fields
+// so that a json.Marshaler can be dumped as is without leaking secrets
+func isLeaf(t reflect.Type) bool {
+	if t == ipType || t == ipNetType {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Array:
+		return false
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).IsExported() {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func dumpStruct(obj map[string]interface{}, v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _ := JSONName(f)
+		if name == "" {
+			continue
+		}
+		if isEmbeddedStruct(f) {
+			dumpStruct(obj, v.Field(i)) //embedded struct fields are flattened like JSON does
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if SecretField(f) {
+			obj[name] = secretValue{value: dumpRaw(v.Field(i))}
+			continue
+		}
+		obj[name] = dumpRaw(v.Field(i))
+	}
+} //dumpStruct()
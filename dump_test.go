@@ -0,0 +1,68 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testDBConfig struct {
+	Host     string    `json:"host"`
+	Password string    `json:"password" secret:"true"`
+	Updated  time.Time `json:"updated"`
+}
+
+// testMarshalerConfig implements json.Marshaler and must still be redacted
+type testMarshalerConfig struct {
+	User  string `json:"user"`
+	Token string `json:"token" secret:"true"`
+}
+
+func (c testMarshalerConfig) MarshalJSON() ([]byte, error) {
+	type plain testMarshalerConfig
+	return json.Marshal(plain(c))
+}
+
+func TestDump(t *testing.T) {
+	reset(t)
+	updated := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	MustConfigure("ms.db", testDBConfig{})
+	MustConfigure("ms.auth", testMarshalerConfig{})
+	MustConfigure("ms.name", "")
+	addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{
+		"db":   map[string]interface{}{"host": "db1", "password": "pass1", "updated": updated.Format(time.RFC3339)},
+		"auth": map[string]interface{}{"user": "joe", "token": "abc"},
+		"name": "test",
+	}})
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	expected := map[string]interface{}{"ms": map[string]interface{}{
+		"db":   map[string]interface{}{"host": "db1", "password": "***", "updated": updated},
+		"auth": map[string]interface{}{"user": "joe", "token": "***"},
+		"name": "test",
+	}}
+	if dump := Dump(); !reflect.DeepEqual(dump, expected) {
+		t.Fatalf("got %+v, expected %+v", dump, expected)
+	}
+
+	jsonDump, err := DumpJSON()
+	if err != nil {
+		t.Fatalf("failed to dump JSON: %+v", err)
+	}
+	var parsed map[string]interface{}
+	if err := json.Unmarshal(jsonDump, &parsed); err != nil {
+		t.Fatalf("invalid JSON: %+v", err)
+	}
+	if got := parsed["ms"].(map[string]interface{})["db"].(map[string]interface{})["updated"]; got != "2020-01-02T03:04:05Z" {
+		t.Fatalf("updated=%v", got)
+	}
+}
+
+func TestDumpNotLoaded(t *testing.T) {
+	reset(t)
+	if dump := Dump(); len(dump) != 0 {
+		t.Fatalf("got %+v before Load()", dump)
+	}
+}
@@ -0,0 +1,315 @@
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/go-msvc/data"
+	"github.com/go-msvc/errors"
+)
+
+// encryptionKeyEnv has the base64 AES key used when SetEncryptionKey() was not called
+const encryptionKeyEnv = "CONFIG_ENCRYPTION_KEY"
+
+// encryptedPrefix starts encrypted values, followed by base64(nonce+ciphertext)
+const encryptedPrefix = "enc:"
+
+// SetEncryptionKey sets the AES key of 16, 24 or 32 bytes used to decrypt
+// fields tagged `encrypted:"true"` when they are loaded
+// without it, the base64 key in CONFIG_ENCRYPTION_KEY is used
+func (c *Config) SetEncryptionKey(key []byte) {
+	if _, err := aes.NewCipher(key); err != nil {
+		panic(errors.Wrapf(err, "invalid config encryption key"))
+	}
+	c.encryptionMutex.Lock()
+	defer c.encryptionMutex.Unlock()
+	c.encryptionKey = append([]byte{}, key...)
+}
+
+func (c *Config) getEncryptionKey() ([]byte, error) {
+	c.encryptionMutex.Lock()
+	defer c.encryptionMutex.Unlock()
+	if c.encryptionKey != nil {
+		return c.encryptionKey, nil
+	}
+	s := os.Getenv(encryptionKeyEnv)
+	if s == "" {
+		return nil, errors.Errorf("no encryption key (call config.SetEncryptionKey() or set %s)", encryptionKeyEnv)
+	}
+	key, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid base64 in %s", encryptionKeyEnv)
+	}
+	return key, nil
+}
+
+// Encrypt returns the value to write in config for a field tagged `encrypted:"true"`,
+// i.e. "enc:" followed by the base64 of a random nonce and the AES-GCM ciphertext
+func Encrypt(plaintext string, key []byte) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", errors.Wrapf(err, "cannot make nonce")
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// EncryptFileContent returns the content of a file that File() reads WithDecryption(key),
+// i.e. the base64 of a random nonce and the AES-GCM ciphertext of the whole file
+func EncryptFileContent(plaintext []byte, key []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.Wrapf(err, "cannot make nonce")
+	}
+	sealed := gcm.Seal(nonce, nonce, plaintext, nil)
+	encoded := make([]byte, base64.StdEncoding.EncodedLen(len(sealed)))
+	base64.StdEncoding.Encode(encoded, sealed)
+	return encoded, nil
+}
+
+// decryptFileContent is the reverse of EncryptFileContent()
+// content that is not base64 is returned as is, so plain files can still be read
+func decryptFileContent(content []byte, key []byte) ([]byte, error) {
+	sealed, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(content)))
+	if err != nil {
+		return content, nil //not encrypted
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize()+gcm.Overhead() {
+		return nil, errors.Errorf("encrypted content too short")
+	}
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot decrypt (wrong key?)")
+	}
+	return plaintext, nil
+}
+
+// decrypt is the reverse of Encrypt()
+func decrypt(value string, key []byte) (string, error) {
+	if !strings.HasPrefix(value, encryptedPrefix) {
+		return "", errors.Errorf("value is not encrypted, expecting \"%s<base64>\" from config.Encrypt()", encryptedPrefix)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(value[len(encryptedPrefix):])
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid base64 in encrypted value")
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.Errorf("encrypted value is too short")
+	}
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot decrypt (wrong key?)")
+	}
+	return string(plaintext), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid encryption key")
+	}
+	return cipher.NewGCM(block)
+}
+
+// hasEncryptedFields is true if t has fields tagged `encrypted:"true"`, also in nested structs
+func hasEncryptedFields(t reflect.Type) bool {
+	return len(encryptedPaths(t, "")) > 0
+}
+
+// encryptedPaths returns the dot-notation json names of encrypted fields in t,
+// e.g. "db.password", not inside slices or maps
+// a recursive struct type is not walked again inside itself, e.g. for Next *Node
+// the paths in Node are listed once, not for next.next...
+func encryptedPaths(t reflect.Type, prefix string) []string {
+	return encryptedPathsOnPath(t, prefix, map[reflect.Type]bool{})
+}
+
+func encryptedPathsOnPath(t reflect.Type, prefix string, onPath map[reflect.Type]bool) []string {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct || onPath[t] {
+		return nil
+	}
+	onPath[t] = true
+	defer delete(onPath, t)
+	paths := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if isEmbeddedStruct(f) {
+			paths = append(paths, encryptedPathsOnPath(f.Type, prefix, onPath)...)
+			continue
+		}
+		name, _ := JSONName(f)
+		if name == "" || !f.IsExported() {
+			continue
+		}
+		if EncryptedField(f) {
+			paths = append(paths, prefix+name)
+			continue
+		}
+		paths = append(paths, encryptedPathsOnPath(f.Type, prefix+name+".", onPath)...)
+	}
+	return paths
+} //encryptedPathsOnPath()
+
+// decryptFields returns a copy of value with its encrypted fields decrypted
+// value is returned as is if it has no encrypted fields
+func (c *Config) decryptFields(value interface{}) (interface{}, error) {
+	if value == nil || !hasEncryptedFields(reflect.TypeOf(value)) {
+		return value, nil
+	}
+	key, err := c.getEncryptionKey()
+	if err != nil {
+		return nil, err
+	}
+	copied := reflect.New(reflect.TypeOf(value)).Elem()
+	copied.Set(reflect.ValueOf(value))
+	if err := decryptValue(copied, key, ""); err != nil {
+		return nil, err
+	}
+	return copied.Interface(), nil
+}
+
+// decryptValue decrypts the encrypted fields in v which must be settable
+// pointers are copied before changing what they point to, so that the loaded value is not changed
+func decryptValue(v reflect.Value, key []byte, path string) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		copied := reflect.New(v.Type().Elem())
+		copied.Elem().Set(v.Elem())
+		if err := decryptValue(copied.Elem(), key, path); err != nil {
+			return err
+		}
+		v.Set(copied)
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name, _ := JSONName(f)
+			fieldPath := name
+			if path != "" {
+				fieldPath = path + "." + name
+			}
+			if isEmbeddedStruct(f) {
+				fieldPath = path
+			}
+			if !EncryptedField(f) {
+				if err := decryptValue(v.Field(i), key, fieldPath); err != nil {
+					return err
+				}
+				continue
+			}
+			if f.Type.Kind() != reflect.String {
+				return errors.Errorf("encrypted field(%s) must be a string", fieldPath)
+			}
+			if v.Field(i).String() == "" {
+				continue //not configured, use validate:"required" if needed
+			}
+			plaintext, err := decrypt(v.Field(i).String(), key)
+			if err != nil {
+				return errors.Wrapf(err, "invalid encrypted field(%s)", fieldPath)
+			}
+			v.Field(i).SetString(plaintext)
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(copied, v)
+		for i := 0; i < copied.Len(); i++ {
+			if err := decryptValue(copied.Index(i), key, path); err != nil {
+				return err
+			}
+		}
+		v.Set(copied)
+	}
+	return nil
+} //decryptValue()
+
+// EncryptFile writes the JSON file inputPath to outputPath with the values of
+// all fields tagged `encrypted:"true"` in the registered config encrypted with key
+// values that are already encrypted are kept, so it can run again after adding values
+// fields inside slices and maps are not encrypted
+func (c *Config) EncryptFile(inputPath, outputPath string, key []byte) error {
+	content, err := os.ReadFile(inputPath)
+	if err != nil {
+		return errors.Wrapf(err, "cannot read %s", inputPath)
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal(content, &obj); err != nil {
+		return errors.Wrapf(err, "%s is not a JSON object", inputPath)
+	}
+	c.moduleDataMutex.Lock()
+	paths := []string{}
+	for ref, tmpl := range c.mustConfigureByRef {
+		paths = append(paths, encryptedPaths(reflect.TypeOf(tmpl), ref+".")...)
+	}
+	for _, info := range c.constructorsByType {
+		info.Lock()
+		for ref := range info.mustConstructByRef {
+			for implName, tmpl := range info.tmplByName {
+				paths = append(paths, encryptedPaths(reflect.TypeOf(tmpl), ref+"."+implName+".")...)
+			}
+		}
+		info.Unlock()
+	}
+	c.moduleDataMutex.Unlock()
+
+	for _, path := range paths {
+		value, err := data.Get(obj, path)
+		if err != nil {
+			continue //not in this file
+		}
+		s, ok := value.(string)
+		if !ok {
+			return errors.Errorf("%s: encrypted field(%s) is not a string", inputPath, path)
+		}
+		if s == "" || strings.HasPrefix(s, encryptedPrefix) {
+			continue
+		}
+		encrypted, err := Encrypt(s, key)
+		if err != nil {
+			return err
+		}
+		setNested(obj, path, encrypted)
+	}
+	content, err = json.MarshalIndent(obj, "", "  ")
+	if err != nil {
+		return errors.Wrapf(err, "cannot encode %s", outputPath)
+	}
+	if err := os.WriteFile(outputPath, content, 0600); err != nil {
+		return errors.Wrapf(err, "cannot write %s", outputPath)
+	}
+	return nil
+} //EncryptFile()
@@ -0,0 +1,189 @@
+package config
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testEncryptedDB struct {
+	Host     string `json:"host"`
+	Password string `json:"password" encrypted:"true"`
+}
+
+type testEncryptedConfig struct {
+	DB     testEncryptedDB   `json:"db"`
+	Backup *testEncryptedDB  `json:"backup"`
+	Others []testEncryptedDB `json:"others"`
+}
+
+var testEncryptionKey = []byte("0123456789abcdef0123456789abcdef")
+
+func TestEncrypt(t *testing.T) {
+	encrypted, err := Encrypt("s3cret", testEncryptionKey)
+	if err != nil || !strings.HasPrefix(encrypted, "enc:") {
+		t.Fatalf("got %s,%v", encrypted, err)
+	}
+	if again, _ := Encrypt("s3cret", testEncryptionKey); again == encrypted {
+		t.Fatalf("same result without random nonce")
+	}
+	if plaintext, err := decrypt(encrypted, testEncryptionKey); err != nil || plaintext != "s3cret" {
+		t.Fatalf("got %s,%v", plaintext, err)
+	}
+	if _, err := decrypt(encrypted, []byte("fedcba9876543210fedcba9876543210")); err == nil {
+		t.Fatalf("decrypted with wrong key")
+	}
+	if _, err := Encrypt("x", []byte("short")); err == nil {
+		t.Fatalf("encrypted with invalid key")
+	}
+}
+
+func TestEncryptedFields(t *testing.T) {
+	reset(t)
+	SetEncryptionKey(testEncryptionKey)
+	enc := func(s string) string {
+		encrypted, err := Encrypt(s, testEncryptionKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return encrypted
+	}
+	MustConfigure("app", testEncryptedConfig{})
+	s := addTestSource(t, map[string]interface{}{"app": map[string]interface{}{
+		"db":     map[string]interface{}{"host": "db1", "password": enc("a")},
+		"backup": map[string]interface{}{"host": "db2", "password": enc("b")},
+		"others": []interface{}{map[string]interface{}{"host": "db3", "password": enc("c")}},
+	}})
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	c := Get("app").(testEncryptedConfig)
+	if c.DB.Password != "a" || c.Backup.Password != "b" || c.Others[0].Password != "c" {
+		t.Fatalf("got %+v", c)
+	}
+	if dump, _ := DumpJSON(); strings.Contains(string(dump), `"a"`) {
+		t.Fatalf("decrypted value in dump: %s", dump)
+	}
+
+	//plain text is rejected
+	s.set(map[string]interface{}{"app": map[string]interface{}{
+		"db": map[string]interface{}{"host": "db1", "password": "plain"},
+	}})
+	err := Reload()
+	if err == nil || !strings.Contains(err.Error(), "invalid encrypted field(db.password)") || !strings.Contains(err.Error(), "value is not encrypted") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestEncryptedFieldsStruct(t *testing.T) {
+	reset(t)
+	SetEncryptionKey(testEncryptionKey)
+	encrypted, _ := Encrypt("a", testEncryptionKey)
+	addTestSource(t, map[string]interface{}{"db": map[string]interface{}{"host": "db1", "password": encrypted}})
+	v, err := Struct("db", testEncryptedDB{})
+	if err != nil {
+		t.Fatalf("failed to read: %+v", err)
+	}
+	if db := v.(testEncryptedDB); db.Password != "a" || db.Host != "db1" {
+		t.Fatalf("got %+v", db)
+	}
+}
+
+func TestEncryptionKeyEnv(t *testing.T) {
+	reset(t)
+	t.Setenv(encryptionKeyEnv, "")
+	MustConfigure("db", testEncryptedDB{})
+	addTestSource(t, map[string]interface{}{"db": map[string]interface{}{"password": "enc:x"}})
+	if err := Load(); err == nil || !strings.Contains(err.Error(), "no encryption key") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	reset(t)
+	t.Setenv(encryptionKeyEnv, base64.StdEncoding.EncodeToString(testEncryptionKey))
+	encrypted, _ := Encrypt("a", testEncryptionKey)
+	MustConfigure("db", testEncryptedDB{})
+	addTestSource(t, map[string]interface{}{"db": map[string]interface{}{"password": encrypted}})
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if Get("db").(testEncryptedDB).Password != "a" {
+		t.Fatalf("got %+v", Get("db"))
+	}
+}
+
+func TestEncryptFile(t *testing.T) {
+	reset(t)
+	MustConfigure("app", testEncryptedConfig{})
+	dir := t.TempDir()
+	input, output := filepath.Join(dir, "plain.json"), filepath.Join(dir, "encrypted.json")
+	if err := os.WriteFile(input, []byte(`{"app":{"db":{"host":"db1","password":"a"}},"other":{"password":"b"}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := EncryptFile(input, output, testEncryptionKey); err != nil {
+		t.Fatalf("failed to encrypt: %+v", err)
+	}
+	content, _ := os.ReadFile(output)
+	var obj struct {
+		App   map[string]map[string]string `json:"app"`
+		Other map[string]string            `json:"other"`
+	}
+	if err := json.Unmarshal(content, &obj); err != nil {
+		t.Fatalf("invalid output: %s", content)
+	}
+	if p := obj.App["db"]["password"]; !strings.HasPrefix(p, "enc:") {
+		t.Fatalf("password not encrypted: %s", content)
+	}
+	if obj.App["db"]["host"] != "db1" || obj.Other["password"] != "b" {
+		t.Fatalf("changed other values: %s", content)
+	}
+
+	SetEncryptionKey(testEncryptionKey)
+	if _, err := AddFileSource(output); err != nil {
+		t.Fatal(err)
+	}
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if p := Get("app").(testEncryptedConfig).DB.Password; p != "a" {
+		t.Fatalf("password=%s", p)
+	}
+}
+
+type testEncryptedNode struct {
+	Name     string             `json:"name"`
+	Password string             `json:"password" encrypted:"true"`
+	Next     *testEncryptedNode `json:"next"`
+}
+
+type testPlainNode struct {
+	Name string         `json:"name"`
+	Next *testPlainNode `json:"next"`
+}
+
+func TestEncryptedFieldsRecursiveType(t *testing.T) {
+	reset(t)
+	SetEncryptionKey(testEncryptionKey)
+	encrypted, _ := Encrypt("b", testEncryptionKey)
+	MustConfigure("plain", testPlainNode{})
+	MustConfigure("node", testEncryptedNode{})
+	addTestSource(t, map[string]interface{}{
+		"plain": map[string]interface{}{"name": "a", "next": map[string]interface{}{"name": "b"}},
+		"node":  map[string]interface{}{"name": "a", "next": map[string]interface{}{"name": "b", "password": encrypted}},
+	})
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if n := Get("plain").(testPlainNode); n.Next == nil || n.Next.Name != "b" {
+		t.Fatalf("got %+v", n)
+	}
+	if n := Get("node").(testEncryptedNode); n.Next == nil || n.Next.Password != "b" {
+		t.Fatalf("got %+v", n)
+	}
+	if paths := encryptedPaths(reflect.TypeOf(testEncryptedNode{}), ""); len(paths) != 1 || paths[0] != "password" {
+		t.Fatalf("paths=%v", paths)
+	}
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+
+	"github.com/go-msvc/errors"
+)
+
+// Env is a string config field that is read from an env var when it is set,
+// else from the config sources, e.g.:
+//
+//	APIKey config.Env `json:"api_key" env:"API_KEY" secret:"true"`
+//
+// the env var is only used for fields with the env tag in structs that are
+// loaded, also nested structs, but not inside slices or maps
+type Env struct {
+	value string
+}
+
+// Value returns the value from the env var, or else from the config sources
+func (e Env) Value() string {
+	return e.value
+}
+
+// Validate fails if there is no value in the env var or in the config sources
+func (e Env) Validate() error {
+	if e.value == "" {
+		return errors.Errorf("no value in env or config")
+	}
+	return nil
+}
+
+func (e Env) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.value)
+}
+
+func (e *Env) UnmarshalJSON(value []byte) error {
+	var s *string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return errors.Wrapf(err, "env value must be a string")
+	}
+	if s != nil {
+		e.value = *s
+	}
+	return nil
+}
+
+var envType = reflect.TypeOf(Env{})
+
+// hasEnvFields is true if t has Env fields with the env tag, also in nested structs
+func hasEnvFields(t reflect.Type) bool {
+	return hasEnvFieldsOnPath(t, map[reflect.Type]bool{})
+}
+
+// hasEnvFieldsOnPath does not walk a struct type again inside itself, e.g. for Next *Node,
+// because the fields of that type are already checked higher up
+func hasEnvFieldsOnPath(t reflect.Type, onPath map[reflect.Type]bool) bool {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct || onPath[t] {
+		return false
+	}
+	onPath[t] = true
+	defer delete(onPath, t)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if f.Type == envType && FieldTag(f, "env") != "" {
+			return true
+		}
+		if hasEnvFieldsOnPath(f.Type, onPath) {
+			return true
+		}
+	}
+	return false
+} //hasEnvFieldsOnPath()
+
+// resolveEnvFields returns a copy of value with the Env fields set from
+// their env vars that are not empty
+// value is returned as is if it has no Env fields with the env tag
+func resolveEnvFields(value interface{}) interface{} {
+	if value == nil || !hasEnvFields(reflect.TypeOf(value)) {
+		return value
+	}
+	copied := reflect.New(reflect.TypeOf(value)).Elem()
+	copied.Set(reflect.ValueOf(value))
+	resolveEnvValue(copied)
+	return copied.Interface()
+}
+
+// resolveEnvValue sets the Env fields in v which must be settable
+// pointers are copied before changing what they point to, so that the loaded value is not changed
+func resolveEnvValue(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() || !hasEnvFields(v.Type()) {
+			return
+		}
+		copied := reflect.New(v.Type().Elem())
+		copied.Elem().Set(v.Elem())
+		resolveEnvValue(copied.Elem())
+		v.Set(copied)
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			if f.Type != envType {
+				resolveEnvValue(v.Field(i))
+				continue
+			}
+			if name := FieldTag(f, "env"); name != "" {
+				if s := os.Getenv(name); s != "" {
+					v.Field(i).Set(reflect.ValueOf(Env{value: s}))
+				}
+			}
+		}
+	}
+} //resolveEnvValue()
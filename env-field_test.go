@@ -0,0 +1,94 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testEnvDB struct {
+	Host string `json:"host"`
+	Key  Env    `json:"key" env:"TEST_CONFIG_DB_KEY"`
+}
+
+type testEnvConfig struct {
+	APIKey Env        `json:"api_key" env:"TEST_CONFIG_API_KEY"`
+	Other  Env        `json:"other"`
+	DB     *testEnvDB `json:"db"`
+}
+
+func TestEnvField(t *testing.T) {
+	reset(t)
+	t.Setenv("TEST_CONFIG_API_KEY", "from-env")
+	t.Setenv("TEST_CONFIG_DB_KEY", "")
+	MustConfigure("service", testEnvConfig{})
+	addTestSource(t, map[string]interface{}{"service": map[string]interface{}{
+		"api_key": "from-config",
+		"other":   "other-config",
+		"db":      map[string]interface{}{"host": "db1", "key": "db-config"},
+	}})
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	cfg := Get("service").(testEnvConfig)
+	if cfg.APIKey.Value() != "from-env" {
+		t.Fatalf("env var not used: %v", cfg.APIKey.Value())
+	}
+	if cfg.Other.Value() != "other-config" || cfg.DB.Key.Value() != "db-config" {
+		t.Fatalf("config not used: %+v %+v", cfg, cfg.DB)
+	}
+	if jsonValue, err := json.Marshal(cfg.APIKey); err != nil || string(jsonValue) != `"from-env"` {
+		t.Fatalf("json %s, %v", jsonValue, err)
+	}
+}
+
+func TestEnvFieldMissing(t *testing.T) {
+	reset(t)
+	t.Setenv("TEST_CONFIG_API_KEY", "")
+	MustConfigure("service", testEnvConfig{})
+	addTestSource(t, map[string]interface{}{"service": map[string]interface{}{"other": "other-config"}})
+	err := Load()
+	if err == nil || !strings.Contains(err.Error(), "field(api_key)") || !strings.Contains(err.Error(), "no value in env or config") {
+		t.Fatalf("wrong error: %v", err)
+	}
+}
+
+type testEnvNode struct {
+	Name string       `json:"name"`
+	Key  Env          `json:"key" env:"TEST_CONFIG_NODE_KEY"`
+	Next *testEnvNode `json:"next"`
+}
+
+func TestEnvFieldRecursiveType(t *testing.T) {
+	reset(t)
+	t.Setenv("TEST_CONFIG_NODE_KEY", "from-env")
+	MustConfigure("node", testEnvNode{})
+	addTestSource(t, map[string]interface{}{"node": map[string]interface{}{
+		"name": "a",
+		"next": map[string]interface{}{"name": "b", "key": "from-config"},
+	}})
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	n := Get("node").(testEnvNode)
+	if n.Key.Value() != "from-env" || n.Next == nil || n.Next.Key.Value() != "from-env" || n.Next.Next != nil {
+		t.Fatalf("got %+v %+v", n, n.Next)
+	}
+}
+
+func TestEnvFieldSchemaAndDefaults(t *testing.T) {
+	reset(t)
+	MustConfigure("service", testEnvConfig{Other: Env{value: "x"}})
+	definitions := Schema()["definitions"].(map[string]interface{})
+	if _, ok := definitions["github.com/go-msvc/config.Env"]; ok {
+		t.Fatalf("Env has a definition")
+	}
+	properties := definitions["github.com/go-msvc/config.testEnvConfig"].(map[string]interface{})["properties"].(map[string]interface{})
+	if !reflect.DeepEqual(properties["other"], map[string]interface{}{"type": "string"}) {
+		t.Fatalf("other=%+v", properties["other"])
+	}
+	if s := PrintDefaultsString(); !strings.Contains(s, "  other config.Env = \"x\"\n") {
+		t.Fatalf("got:\n%s", s)
+	}
+}
@@ -0,0 +1,180 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-msvc/errors"
+)
+
+// BindEnv reads config name from environment variable envVarName
+// when none of the sources has the value, e.g.:
+//
+//	config.BindEnv("ms.db.host", "DB_HOST")
+//
+// a binding of a field is also used for the struct it is in,
+// e.g. "ms.db" gets {"host":...} from DB_HOST
+// numbers, booleans and JSON objects and lists are parsed, other values are strings
+func (c *Config) BindEnv(configName, envVarName string) {
+	if !validReference(configName) {
+		panic(fmt.Sprintf("invalid config reference(%s), expecting dot-notation reference", configName))
+	}
+	if envVarName == "" {
+		panic(fmt.Sprintf("config.BindEnv(%s) without environment variable name", configName))
+	}
+	c.envMutex.Lock()
+	defer c.envMutex.Unlock()
+	c.envVarByName[c.resolveAlias(configName)] = envVarName
+}
+
+// BindEnvPrefix reads config from environment variables named with the prefix
+// and the config name in uppercase with underscores instead of dots
+// when none of the sources or BindEnv() has the value,
+// e.g. with prefix "APP", "ms.db.host" is read from APP_MS_DB_HOST
+// and "ms.db" gets {"host":...} from APP_MS_DB_HOST,
+// which means names with underscores cannot be read as fields of a struct
+func (c *Config) BindEnvPrefix(prefix string) {
+	c.envMutex.Lock()
+	defer c.envMutex.Unlock()
+	c.envPrefix = strings.ToUpper(strings.TrimSuffix(prefix, "_"))
+}
+
+// EnvVarName returns the environment variable name for a config name with BindEnvPrefix()
+// e.g. "APP","ms.db.host" -> "APP_MS_DB_HOST"
+func EnvVarName(prefix, configName string) string {
+	name := strings.ToUpper(strings.ReplaceAll(configName, ".", "_"))
+	if prefix == "" {
+		return name
+	}
+	return strings.ToUpper(strings.TrimSuffix(prefix, "_")) + "_" + name
+}
+
+// envSourceName is the name of the source in Dump(), TakeSnapshot() etc.
+// for values that were read from environment variables
+const envSourceName = "env"
+
+// hasEnvBindings is true if BindEnv() or BindEnvPrefix() was called
+func (c *Config) hasEnvBindings() bool {
+	c.envMutex.Lock()
+	defer c.envMutex.Unlock()
+	return len(c.envVarByName) > 0 || c.envPrefix != ""
+}
+
+// envSource reads the values bound with BindEnv() and BindEnvPrefix()
+type envSource struct {
+	c *Config
+}
+
+func (s envSource) GetInto(name string, tmpl interface{}) (interface{}, error) {
+	c := s.c
+	c.envMutex.Lock()
+	varByName := map[string]string{}
+	for n, v := range c.envVarByName {
+		varByName[n] = v
+	}
+	prefix := c.envPrefix
+	c.envMutex.Unlock()
+
+	//explicit bindings first
+	if value, ok := envValue(varByName, name); ok {
+		return envInto(value, tmpl)
+	}
+	if prefix != "" {
+		if value, ok := envPrefixValue(prefix, name); ok {
+			return envInto(value, tmpl)
+		}
+	}
+	return nil, nil //not configured
+} //envSource.GetInto()
+
+// AddEnvSource adds a source that reads config from environment variables
+// named with the prefix, the same as BindEnvPrefix(), but as a source named "env:<PREFIX>"
+// so it is used in the order of sources instead of after all sources
+func (c *Config) AddEnvSource(prefix string) (Source, error) {
+	prefix = strings.ToUpper(strings.TrimSuffix(prefix, "_"))
+	if prefix == "" {
+		return nil, errors.Errorf("cannot add env source without prefix")
+	}
+	s := prefixEnvSource{prefix: prefix}
+	if err := c.AddSource(envSourceName+":"+prefix, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// prefixEnvSource reads environment variables with a prefix
+type prefixEnvSource struct {
+	prefix string
+}
+
+func (s prefixEnvSource) GetInto(name string, tmpl interface{}) (interface{}, error) {
+	if value, ok := envPrefixValue(s.prefix, name); ok {
+		return envInto(value, tmpl)
+	}
+	return nil, nil //not configured
+}
+
+// envPrefixValue returns the value of name from environment variables named with the prefix
+func envPrefixValue(prefix, name string) (interface{}, bool) {
+	prefixVarByName := map[string]string{}
+	varPrefix := EnvVarName(prefix, name)
+	for _, env := range os.Environ() {
+		envVarName, _, _ := strings.Cut(env, "=")
+		if envVarName == varPrefix {
+			prefixVarByName[name] = envVarName
+		} else if strings.HasPrefix(envVarName, varPrefix+"_") {
+			field := strings.ToLower(strings.ReplaceAll(strings.TrimPrefix(envVarName, varPrefix+"_"), "_", "."))
+			prefixVarByName[name+"."+field] = envVarName
+		}
+	}
+	return envValue(prefixVarByName, name)
+}
+
+// envValue returns the value of name from the environment variable bound to it
+// or combines the values of its fields
+func envValue(varByName map[string]string, name string) (interface{}, bool) {
+	if envVarName, ok := varByName[name]; ok {
+		if s, ok := os.LookupEnv(envVarName); ok {
+			return parseEnv(s), true
+		}
+	}
+	obj := map[string]interface{}{}
+	for n, envVarName := range varByName {
+		if !strings.HasPrefix(n, name+".") {
+			continue
+		}
+		if s, ok := os.LookupEnv(envVarName); ok {
+			setNested(obj, strings.TrimPrefix(n, name+"."), parseEnv(s))
+		}
+	}
+	if len(obj) == 0 {
+		return nil, false
+	}
+	return obj, true
+} //envValue()
+
+// envInto parses the value into tmpl, keeping numbers as text when tmpl is a string
+func envInto(value interface{}, tmpl interface{}) (interface{}, error) {
+	if _, ok := tmpl.(string); ok {
+		if _, isObj := value.(map[string]interface{}); !isObj {
+			if _, isList := value.([]interface{}); !isList {
+				return fmt.Sprintf("%v", value), nil
+			}
+		}
+	}
+	return getInto(value, "", tmpl)
+}
+
+// parseEnv parses numbers, booleans, objects and lists from the environment variable value
+func parseEnv(s string) interface{} {
+	var value interface{}
+	if err := json.Unmarshal([]byte(s), &value); err == nil {
+		switch value.(type) {
+		case float64, bool, map[string]interface{}, []interface{}:
+			return value
+		}
+	}
+	return s
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBindEnv(t *testing.T) {
+	reset(t)
+	MustConfigure("ms.name", "")
+	MustConfigure("ms.code", "")
+	MustConfigure("ms.db", testServerConfig{})
+	addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{"name": "source"}})
+	t.Setenv("TEST_NAME", "env")
+	t.Setenv("TEST_CODE", "123")
+	t.Setenv("TEST_DB_HOST", "db1")
+	t.Setenv("TEST_DB_PORT", "5432")
+	BindEnv("ms.name", "TEST_NAME")
+	BindEnv("ms.code", "TEST_CODE")
+	BindEnv("ms.db.host", "TEST_DB_HOST")
+	BindEnv("ms.db.port", "TEST_DB_PORT")
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if Get("ms.name") != "source" {
+		t.Fatalf("env overrode source: %v", Get("ms.name"))
+	}
+	if Get("ms.code") != "123" || defaultConfig.sourceNameByRef["ms.code"] != "env" {
+		t.Fatalf("ms.code=%v from %s", Get("ms.code"), defaultConfig.sourceNameByRef["ms.code"])
+	}
+	if v := Get("ms.db"); v != (testServerConfig{Host: "db1", Port: 5432}) {
+		t.Fatalf("ms.db=%+v", v)
+	}
+}
+
+func TestBindEnvPrefix(t *testing.T) {
+	reset(t)
+	MustConfigure("ms.db", testServerConfig{})
+	MustConfigure("ms.port", 0)
+	MustConfigure("ms.name", "")
+	MayConfigure("ms.label", "")
+	addTestSource(t, map[string]interface{}{})
+	t.Setenv("APP_MS_DB_HOST", "db1")
+	t.Setenv("APP_MS_DB_PORT", "5432")
+	t.Setenv("APP_MS_PORT", "8080")
+	t.Setenv("APP_MS_NAME", "prefix")
+	t.Setenv("NAME", "bound")
+	BindEnvPrefix("APP_")
+	BindEnv("ms.name", "NAME") //explicit binding before prefix
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if v := Get("ms.db"); v != (testServerConfig{Host: "db1", Port: 5432}) {
+		t.Fatalf("ms.db=%+v", v)
+	}
+	if v := Get("ms.port"); v != 8080 {
+		t.Fatalf("ms.port=%v", v)
+	}
+	if v := Get("ms.name"); v != "bound" {
+		t.Fatalf("ms.name=%v", v)
+	}
+	if v := Get("ms.label"); v != nil {
+		t.Fatalf("ms.label=%v", v)
+	}
+}
+
+func TestEnvVarName(t *testing.T) {
+	for _, test := range []struct{ prefix, name, expected string }{
+		{"APP", "ms.db.host", "APP_MS_DB_HOST"},
+		{"app_", "ms.db", "APP_MS_DB"},
+		{"", "ms.db", "MS_DB"},
+	} {
+		if s := EnvVarName(test.prefix, test.name); s != test.expected {
+			t.Fatalf("EnvVarName(%s,%s)=%s", test.prefix, test.name, s)
+		}
+	}
+}
+
+func TestAddEnvSource(t *testing.T) {
+	reset(t)
+	t.Setenv("SVC_MS_NAME", "env")
+	addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{"name": "test"}})
+	if _, err := AddEnvSource(""); err == nil {
+		t.Fatalf("added env source without prefix")
+	}
+	s, err := AddEnvSource("svc_")
+	if err != nil {
+		t.Fatalf("failed to add env source: %+v", err)
+	}
+	if value, err := s.GetInto("ms", map[string]interface{}{}); err != nil || !reflect.DeepEqual(value, map[string]interface{}{"name": "env"}) {
+		t.Fatalf("got %v,%v", value, err)
+	}
+	MustConfigure("ms.name", "")
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	//added after the test source, so not used
+	if Get("ms.name") != "test" || defaultConfig.sources[1].name != "env:SVC" {
+		t.Fatalf("ms.name=%v from %s", Get("ms.name"), defaultConfig.sources[1].name)
+	}
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-msvc/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Export writes all loaded config values to w in the specified format:
+//
+//	"json"  nested JSON object with sorted keys
+//	"yaml"  nested YAML mapping with sorted keys
+//	"env"   one KEY=VALUE line per value, e.g. {"db":{"host":"localhost"}} -> DB_HOST=localhost
+//
+// secret fields are redacted the same as in Dump()
+func (c *Config) Export(w io.Writer, format string) error {
+	//round-trip through JSON so all formats see the same values
+	jsonDump, err := json.Marshal(c.Dump())
+	if err != nil {
+		return errors.Wrapf(err, "failed to encode config")
+	}
+	var dump map[string]interface{}
+	if err := json.Unmarshal(jsonDump, &dump); err != nil {
+		return errors.Wrapf(err, "failed to decode config")
+	}
+
+	var out []byte
+	switch format {
+	case "json":
+		if out, err = json.MarshalIndent(dump, "", "  "); err != nil {
+			return errors.Wrapf(err, "failed to encode JSON")
+		}
+		out = append(out, '\n')
+	case "yaml":
+		if out, err = yaml.Marshal(dump); err != nil {
+			return errors.Wrapf(err, "failed to encode YAML")
+		}
+	case "env":
+		lines := []string{}
+		flattenEnv(&lines, "", dump)
+		sort.Strings(lines)
+		out = []byte(strings.Join(lines, "\n") + "\n")
+	default:
+		return errors.Errorf("unknown export format \"%s\", expecting json|yaml|env", format)
+	}
+	if _, err := w.Write(out); err != nil {
+		return errors.Wrapf(err, "failed to write config")
+	}
+	return nil
+} //Export()
+
+// ExportFile is Export() to a file
+// if format is "", it is taken from the file extension: .json, .yaml, .yml or .env
+func (c *Config) ExportFile(path, format string) error {
+	if format == "" {
+		switch ext := strings.ToLower(filepath.Ext(path)); ext {
+		case ".json", ".yaml", ".env":
+			format = ext[1:]
+		case ".yml":
+			format = "yaml"
+		default:
+			return errors.Errorf("cannot export to %s without format", path)
+		}
+	}
+	buffer := bytes.NewBuffer(nil)
+	if err := c.Export(buffer, format); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, buffer.Bytes(), 0600); err != nil {
+		return errors.Wrapf(err, "failed to write %s", path)
+	}
+	return nil
+}
+
+// ExportForDiff writes all loaded config values to w as JSON
+// indented with sorted keys, so that exports
+// from different versions or deployments can be compared with diff
+func (c *Config) ExportForDiff(w io.Writer) error {
+	return c.Export(w, "json")
+}
+
+// flattenEnv appends KEY=VALUE lines for all leaf values in value
+func flattenEnv(lines *[]string, name string, value interface{}) {
+	if obj, ok := value.(map[string]interface{}); ok && (len(obj) > 0 || name == "") {
+		for key, sub := range obj {
+			if name != "" {
+				key = name + "." + key
+			}
+			flattenEnv(lines, key, sub)
+		}
+		return
+	}
+	*lines = append(*lines, EnvVarName("", name)+"="+envText(value))
+}
+
+// envText is the inverse of parseEnv(): strings as is, other values as JSON
+func envText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		jsonValue, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(jsonValue)
+	}
+}
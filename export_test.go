@@ -0,0 +1,82 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func loadExportTest(t *testing.T) {
+	t.Helper()
+	reset(t)
+	MustConfigure("db", testDBConfig{})
+	MustConfigure("ms.name", "")
+	addTestSource(t, map[string]interface{}{
+		"db": map[string]interface{}{"host": "localhost", "password": "secret", "updated": time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+		"ms": map[string]interface{}{"name": "test"},
+	})
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+}
+
+func TestExport(t *testing.T) {
+	loadExportTest(t)
+	tests := map[string]string{
+		"json": `{
+  "db": {
+    "host": "localhost",
+    "password": "***",
+    "updated": "2020-01-02T03:04:05Z"
+  },
+  "ms": {
+    "name": "test"
+  }
+}
+`,
+		"yaml": `db:
+    host: localhost
+    password: '***'
+    updated: "2020-01-02T03:04:05Z"
+ms:
+    name: test
+`,
+		"env": `DB_HOST=localhost
+DB_PASSWORD=***
+DB_UPDATED=2020-01-02T03:04:05Z
+MS_NAME=test
+`,
+	}
+	for format, expected := range tests {
+		buffer := bytes.NewBuffer(nil)
+		if err := Export(buffer, format); err != nil {
+			t.Fatalf("failed to export %s: %+v", format, err)
+		}
+		if buffer.String() != expected {
+			t.Fatalf("%s:\n%s\n!=\n%s", format, buffer.String(), expected)
+		}
+	}
+	if err := Export(bytes.NewBuffer(nil), "xml"); err == nil {
+		t.Fatalf("exported unknown format")
+	}
+}
+
+func TestExportFile(t *testing.T) {
+	loadExportTest(t)
+	filename := filepath.Join(t.TempDir(), "config.env")
+	if err := ExportFile(filename, ""); err != nil {
+		t.Fatalf("failed to export: %+v", err)
+	}
+	out, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("failed to read: %+v", err)
+	}
+	if !bytes.HasPrefix(out, []byte("DB_HOST=localhost\n")) {
+		t.Fatalf("wrong export:\n%s", out)
+	}
+	if err := ExportFile(filepath.Join(t.TempDir(), "config.txt"), ""); err == nil {
+		t.Fatalf("exported without format")
+	}
+}
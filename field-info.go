@@ -0,0 +1,163 @@
+package config
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-msvc/errors"
+)
+
+// FieldInfo describes a registered config value or a field inside it
+type FieldInfo struct {
+	Type         reflect.Type
+	Required     bool              //MustConfigure() for values, validate:"required" for fields
+	CurrentValue interface{}       //loaded value with secrets masked, nil before Load()
+	SourceName   string            //name of the source that provided the value
+	LoadedAt     time.Time         //when config was last loaded, see LoadedAt()
+	DocString    string            //doc:"..." tag of fields
+	Tags         map[string]string //all tags of fields
+}
+
+// Field describes name, which is a ref registered with MustConfigure() or
+// MayConfigure(), or the json name of a field in it, e.g. "db.host" when "db"
+// is registered as a struct
+func (c *Config) Field(name string) (FieldInfo, error) {
+	name = c.resolveAlias(name)
+	c.moduleDataMutex.Lock()
+	ref, tmpl, required := name, interface{}(nil), false
+	for {
+		var ok bool
+		if tmpl, ok = c.mustConfigureByRef[ref]; ok {
+			required = c.requiredConfigureByRef[ref]
+			break
+		}
+		i := strings.LastIndex(ref, ".")
+		if i < 0 {
+			c.moduleDataMutex.Unlock()
+			return FieldInfo{}, errors.Errorf("config(%s) is not registered", name)
+		}
+		ref = ref[:i]
+	}
+	c.moduleDataMutex.Unlock()
+
+	info := FieldInfo{
+		Type:     reflect.TypeOf(tmpl),
+		Required: required,
+		LoadedAt: c.LoadedAt(),
+	}
+	var current reflect.Value
+	c.configByRefMutex.RLock()
+	if value, ok := c.configByRef[ref]; ok && value != nil {
+		current = reflect.ValueOf(MaskSecrets(value))
+		info.SourceName = c.sourceNameByRef[ref]
+	}
+	c.configByRefMutex.RUnlock()
+
+	if name != ref {
+		for _, fieldName := range strings.Split(strings.TrimPrefix(name, ref+"."), ".") {
+			t := info.Type
+			for t.Kind() == reflect.Ptr {
+				t = t.Elem()
+			}
+			for current.IsValid() && (current.Kind() == reflect.Ptr || current.Kind() == reflect.Interface) {
+				if current.IsNil() {
+					current = reflect.Value{}
+				} else {
+					current = current.Elem()
+				}
+			}
+			switch t.Kind() {
+			case reflect.Struct:
+				found := false
+				iterFields(t, func(jsonName, docString string, f reflect.StructField) {
+					if jsonName != fieldName || found {
+						return
+					}
+					found = true
+					info.Type = f.Type
+					info.DocString = docString
+					info.Tags = fieldTags(f)
+					info.Required = false
+					for _, constraint := range parseValidateTag(FieldTag(f, "validate")) {
+						if constraint.name == "required" {
+							info.Required = true
+						}
+					}
+					if current.IsValid() {
+						current = current.FieldByIndex(f.Index)
+					}
+				})
+				if !found {
+					return FieldInfo{}, errors.Errorf("config(%s) has no field(%s)", name, fieldName)
+				}
+			case reflect.Map:
+				if t.Key().Kind() != reflect.String {
+					return FieldInfo{}, errors.Errorf("config(%s) has no field(%s) in %v", name, fieldName, t)
+				}
+				info.Type, info.DocString, info.Tags, info.Required = t.Elem(), "", nil, false
+				if current.IsValid() {
+					current = current.MapIndex(reflect.ValueOf(fieldName).Convert(t.Key()))
+				}
+			default:
+				return FieldInfo{}, errors.Errorf("config(%s) has no field(%s) in %v", name, fieldName, t)
+			}
+		}
+	}
+	if current.IsValid() && current.CanInterface() {
+		info.CurrentValue = current.Interface()
+	}
+	return info, nil
+} //Field()
+
+// Fields returns Field() of all refs registered with MustConfigure() and MayConfigure()
+func (c *Config) Fields() map[string]FieldInfo {
+	c.moduleDataMutex.Lock()
+	refs := make([]string, 0, len(c.mustConfigureByRef))
+	for ref := range c.mustConfigureByRef {
+		refs = append(refs, ref)
+	}
+	c.moduleDataMutex.Unlock()
+
+	fields := make(map[string]FieldInfo, len(refs))
+	for _, ref := range refs {
+		if info, err := c.Field(ref); err == nil {
+			fields[ref] = info
+		}
+	}
+	return fields
+}
+
+// fieldTags returns all `key:"value"` tags of the field
+func fieldTags(f reflect.StructField) map[string]string {
+	tags := map[string]string{}
+	tag := string(f.Tag)
+	for {
+		tag = strings.TrimLeft(tag, " ")
+		i := strings.Index(tag, ":\"")
+		if i <= 0 {
+			break
+		}
+		key := tag[:i]
+		rest := tag[i+1:]
+		//find the closing quote, skipping escaped quotes
+		j := 1
+		for j < len(rest) && rest[j] != '"' {
+			if rest[j] == '\\' {
+				j++
+			}
+			j++
+		}
+		if j >= len(rest) {
+			break
+		}
+		value, err := strconv.Unquote(rest[:j+1])
+		if err != nil {
+			break
+		}
+		tags[key] = value
+		tag = rest[j+1:]
+	}
+	return tags
+} //fieldTags()
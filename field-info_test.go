@@ -0,0 +1,65 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testFieldDB struct {
+	Host     string `json:"host" doc:"database host" validate:"required"`
+	Port     int    `json:"port"`
+	Password string `json:"password" secret:"true"`
+}
+
+func TestField(t *testing.T) {
+	reset(t)
+	MustConfigure("db", testFieldDB{Port: 5432})
+	MayConfigure("labels", map[string]string{})
+	if err := AddSource("mem", &testSource{value: map[string]interface{}{
+		"db":     map[string]interface{}{"host": "db1", "password": "secret"},
+		"labels": map[string]interface{}{"team": "x"},
+	}}); err != nil {
+		t.Fatalf("failed to add source: %+v", err)
+	}
+
+	//before Load()
+	info, err := Field("db.host")
+	if err != nil || info.CurrentValue != nil || info.SourceName != "" {
+		t.Fatalf("before load: %+v, %+v", info, err)
+	}
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+
+	info, err = Field("db.host")
+	if err != nil {
+		t.Fatalf("failed to get field: %+v", err)
+	}
+	if info.SourceName != "mem" || info.CurrentValue != "db1" || info.Type != reflect.TypeOf("") ||
+		!info.Required || info.DocString != "database host" || info.LoadedAt.IsZero() {
+		t.Fatalf("db.host %+v", info)
+	}
+	if expected := map[string]string{"json": "host", "doc": "database host", "validate": "required"}; !reflect.DeepEqual(info.Tags, expected) {
+		t.Fatalf("tags %v", info.Tags)
+	}
+	if info, err = Field("db.password"); err != nil || info.CurrentValue != "***" {
+		t.Fatalf("password %+v, %+v", info, err)
+	}
+	if info, err = Field("db"); err != nil || !info.Required || info.Type != reflect.TypeOf(testFieldDB{}) ||
+		info.CurrentValue.(testFieldDB).Password != "***" || info.CurrentValue.(testFieldDB).Port != 5432 {
+		t.Fatalf("db %+v, %+v", info, err)
+	}
+	if info, err = Field("labels.team"); err != nil || info.CurrentValue != "x" || info.SourceName != "mem" {
+		t.Fatalf("labels.team %+v, %+v", info, err)
+	}
+	for _, name := range []string{"other", "db.other", "db.host.x"} {
+		if _, err := Field(name); err == nil {
+			t.Fatalf("got unknown field %s", name)
+		}
+	}
+
+	fields := Fields()
+	if len(fields) != 2 || fields["db"].SourceName != "mem" || fields["labels"].Required {
+		t.Fatalf("fields %+v", fields)
+	}
+}
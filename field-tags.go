@@ -0,0 +1,59 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// JSONName returns the name of the field in JSON and if it is tagged omitempty
+// it returns "" for fields tagged json:"-" which are not in JSON,
+// and the field name for fields without a json name
+func JSONName(f reflect.StructField) (name string, omitempty bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	name, options, _ := strings.Cut(tag, ",")
+	for _, option := range strings.Split(options, ",") {
+		if option == "omitempty" {
+			omitempty = true
+		}
+	}
+	if name == "" {
+		name = f.Name
+	}
+	return name, omitempty
+}
+
+// SecretField is true for struct fields tagged `secret:"true"` or `encrypted:"true"`
+// which are masked in logs, Dump(), TakeSnapshot() and PrintDefaults()
+func SecretField(f reflect.StructField) bool {
+	return f.Tag.Get("secret") == "true" || EncryptedField(f)
+}
+
+// EncryptedField is true for string fields tagged `encrypted:"true"`
+// which are decrypted when loaded, see SetEncryptionKey()
+func EncryptedField(f reflect.StructField) bool {
+	return f.Tag.Get("encrypted") == "true"
+}
+
+// DocField returns the `doc:"..."` tag of the field
+// "-" means the field is hidden from documentation
+func DocField(f reflect.StructField) string {
+	return f.Tag.Get("doc")
+}
+
+// FieldTag returns the value of any tag on the field, e.g. FieldTag(f, "validate")
+func FieldTag(f reflect.StructField, tag string) string {
+	return f.Tag.Get(tag)
+}
+
+// isEmbeddedStruct is true for an embedded struct without a json name
+// of which the fields are flattened into the parent like JSON does
+func isEmbeddedStruct(f reflect.StructField) bool {
+	if !f.Anonymous || f.Type.Kind() != reflect.Struct {
+		return false
+	}
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	return name == ""
+}
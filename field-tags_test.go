@@ -0,0 +1,57 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFieldTags(t *testing.T) {
+	type testEmbedded struct {
+		Inner string `json:"inner"`
+	}
+	type testTags struct {
+		testEmbedded
+		Named    string `json:"named,omitempty" doc:"named field" validate:"required"`
+		Untagged string
+		Options  string `json:",omitempty"`
+		Skipped  string `json:"-"`
+		Dash     string `json:"-," doc:"-"`
+		Password string `json:"password" secret:"true"`
+	}
+	typ := reflect.TypeOf(testTags{})
+	tests := map[string]struct {
+		name      string
+		omitempty bool
+		secret    bool
+		doc       string
+	}{
+		"testEmbedded": {name: "testEmbedded"},
+		"Named":        {name: "named", omitempty: true, doc: "named field"},
+		"Untagged":     {name: "Untagged"},
+		"Options":      {name: "Options", omitempty: true},
+		"Skipped":      {name: ""},
+		"Dash":         {name: "-", doc: "-"},
+		"Password":     {name: "password", secret: true},
+	}
+	for fieldName, test := range tests {
+		f, _ := typ.FieldByName(fieldName)
+		name, omitempty := JSONName(f)
+		if name != test.name || omitempty != test.omitempty {
+			t.Errorf("%s: JSONName()=%q,%v", fieldName, name, omitempty)
+		}
+		if SecretField(f) != test.secret {
+			t.Errorf("%s: SecretField()=%v", fieldName, SecretField(f))
+		}
+		if DocField(f) != test.doc {
+			t.Errorf("%s: DocField()=%q", fieldName, DocField(f))
+		}
+	}
+	f, _ := typ.FieldByName("Named")
+	if FieldTag(f, "validate") != "required" || FieldTag(f, "unknown") != "" {
+		t.Errorf("FieldTag()=%q", FieldTag(f, "validate"))
+	}
+	f, _ = typ.FieldByName("testEmbedded")
+	if !isEmbeddedStruct(f) {
+		t.Errorf("embedded struct not flattened")
+	}
+}
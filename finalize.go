@@ -0,0 +1,46 @@
+package config
+
+import (
+	"fmt"
+	"testing"
+)
+
+// Finalize prevents any more sources from being added, so that sources
+// are not added in goroutines after the application started
+// AddSource(), AddSourceWithPriority() and AddConstructorSource() panic after it,
+// including the sources added by AddFileSource(), AddSourceURI() etc.
+// call it in main() after all sources were added
+func (c *Config) Finalize() {
+	c.finalizeMutex.Lock()
+	defer c.finalizeMutex.Unlock()
+	c.finalized = true
+}
+
+// IsFinalized is true after Finalize()
+func (c *Config) IsFinalized() bool {
+	c.finalizeMutex.Lock()
+	defer c.finalizeMutex.Unlock()
+	return c.finalized
+}
+
+// AllowReset allows sources to be added after Finalize() until the test ends,
+// e.g. for tests of code that calls Finalize()
+func (c *Config) AllowReset(t testing.TB) {
+	t.Helper()
+	c.finalizeMutex.Lock()
+	finalized := c.finalized
+	c.finalized = false
+	c.finalizeMutex.Unlock()
+	t.Cleanup(func() {
+		c.finalizeMutex.Lock()
+		defer c.finalizeMutex.Unlock()
+		c.finalized = finalized
+	})
+}
+
+// checkNotFinalized panics after Finalize()
+func (c *Config) checkNotFinalized(call string) {
+	if c.IsFinalized() {
+		panic(fmt.Sprintf("config.%s called after config.Finalize(), add all sources before finalizing the config", call))
+	}
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFinalize(t *testing.T) {
+	reset(t)
+	addTestSource(t, map[string]interface{}{})
+	Finalize()
+	if !IsFinalized() {
+		t.Fatalf("not finalized")
+	}
+	for call, fn := range map[string]func(){
+		"AddSource":             func() { AddSource("other", &testSource{}) },
+		"AddSourceWithPriority": func() { AddSourceWithPriority(1, "other", &testSource{}) },
+		"AddConstructorSource":  func() { AddConstructorSource("ms.greeter", &testSource{}) },
+	} {
+		func() {
+			defer func() {
+				if r := recover(); r == nil || !strings.Contains(r.(string), "Finalize()") {
+					t.Fatalf("%s after Finalize(): %v", call, r)
+				}
+			}()
+			fn()
+		}()
+	}
+
+	t.Run("AllowReset", func(t *testing.T) {
+		AllowReset(t)
+		if IsFinalized() {
+			t.Fatalf("still finalized")
+		}
+		if err := AddSource("other", &testSource{}); err != nil {
+			t.Fatalf("failed to add source: %+v", err)
+		}
+	})
+	if !IsFinalized() {
+		t.Fatalf("not finalized after test with AllowReset()")
+	}
+}
@@ -0,0 +1,48 @@
+package config
+
+// CacheableSource is a source that caches values, e.g. source/cache,
+// so that FlushCache() can make it read them again
+type CacheableSource interface {
+	Source
+	FlushCache() int               //removes all cached values and returns the nr removed
+	FlushCacheFor(name string) int //removes cached values of name, its parents and children
+}
+
+// FlushCache removes all cached values from the sources that implement CacheableSource
+// and returns the total nr of values removed
+// if config was loaded, the next Get() reloads, see Invalidate(), so it does not wait for the cache TTL,
+// e.g. after a remote source was updated manually
+func (c *Config) FlushCache() int {
+	return c.flushCache(func(cs CacheableSource) int { return cs.FlushCache() })
+}
+
+// FlushCacheFor is FlushCache() for only the cached values of name
+func (c *Config) FlushCacheFor(name string) int {
+	return c.flushCache(func(cs CacheableSource) int { return cs.FlushCacheFor(name) })
+}
+
+func (c *Config) flushCache(flush func(CacheableSource) int) int {
+	c.moduleDataMutex.Lock()
+	sources := []Source{}
+	for _, ns := range c.allSources() {
+		sources = append(sources, ns.source)
+	}
+	for _, s := range c.constructorSourceByRef {
+		sources = append(sources, s)
+	}
+	c.moduleDataMutex.Unlock()
+
+	n := 0
+	for _, s := range sources {
+		if cs, ok := s.(CacheableSource); ok {
+			n += flush(cs)
+		}
+	}
+	c.configByRefMutex.RLock()
+	loaded := c.loaded
+	c.configByRefMutex.RUnlock()
+	if loaded {
+		c.Invalidate()
+	}
+	return n
+}
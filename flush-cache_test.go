@@ -0,0 +1,93 @@
+package config
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+// testCachingSource caches the values of a testSource until flushed
+type testCachingSource struct {
+	inner  *testSource
+	mutex  sync.Mutex
+	cached map[string]interface{}
+}
+
+func (s *testCachingSource) GetInto(name string, tmpl interface{}) (interface{}, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if value, ok := s.cached[name]; ok {
+		return value, nil
+	}
+	value, err := s.inner.GetInto(name, tmpl)
+	if err != nil {
+		return nil, err
+	}
+	s.cached[name] = value
+	return value, nil
+}
+
+func (s *testCachingSource) FlushCache() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	n := len(s.cached)
+	s.cached = map[string]interface{}{}
+	return n
+}
+
+func (s *testCachingSource) FlushCacheFor(name string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	n := 0
+	for cachedName := range s.cached {
+		if cachedName == name || strings.HasPrefix(cachedName, name+".") {
+			delete(s.cached, cachedName)
+			n++
+		}
+	}
+	return n
+}
+
+func TestFlushCache(t *testing.T) {
+	reset(t)
+	MustConfigure("ms.server", testServerConfig{})
+	MustConfigure("ms.client", testServerConfig{})
+	inner := &testSource{value: map[string]interface{}{"ms": map[string]interface{}{
+		"server": map[string]interface{}{"port": 8080},
+		"client": map[string]interface{}{"port": 80},
+	}}}
+	if err := AddSource("cached", &testCachingSource{inner: inner, cached: map[string]interface{}{}}); err != nil {
+		t.Fatalf("failed to add source: %+v", err)
+	}
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+
+	inner.set(map[string]interface{}{"ms": map[string]interface{}{
+		"server": map[string]interface{}{"port": 9090},
+		"client": map[string]interface{}{"port": 90},
+	}})
+	if err := Reload(); err != nil {
+		t.Fatalf("failed to reload: %+v", err)
+	}
+	if port := Get("ms.server").(testServerConfig).Port; port != 8080 {
+		t.Fatalf("port=%d before flush", port)
+	}
+
+	if n := FlushCacheFor("ms.server"); n != 1 {
+		t.Fatalf("flushed %d for ms.server", n)
+	}
+	if port := Get("ms.server").(testServerConfig).Port; port != 9090 {
+		t.Fatalf("server port=%d after flush", port)
+	}
+	if port := Get("ms.client").(testServerConfig).Port; port != 80 {
+		t.Fatalf("client port=%d after flushing ms.server", port)
+	}
+
+	if n := FlushCache(); n != 2 {
+		t.Fatalf("flushed %d", n)
+	}
+	if port := Get("ms.client").(testServerConfig).Port; port != 90 {
+		t.Fatalf("client port=%d after flush", port)
+	}
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/go-msvc/errors"
+)
+
+// GetStringMap reads a whole sub-tree of config from the sources
+// for dynamic config where the field names are not known at compile time
+// unlike Get(), it reads directly from the sources and need not be
+// registered with MustConfigure() and may be called before Load()
+// it returns an empty map if ref is not configured in any source
+func (c *Config) GetStringMap(ref string) (map[string]interface{}, error) {
+	ref = c.resolveAlias(ref)
+	value, ns, err := c.getFromSources(ref, map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return map[string]interface{}{}, nil
+	}
+	if obj, ok := value.(map[string]interface{}); ok {
+		return obj, nil
+	}
+
+	//source returned another type, e.g. a struct, convert it with JSON
+	jsonValue, err := json.Marshal(value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot marshal source(%s).config(%s) %T to JSON", ns.name, ref, value)
+	}
+	obj := map[string]interface{}{}
+	if err := json.Unmarshal(jsonValue, &obj); err != nil {
+		return nil, errors.Wrapf(err, "source(%s).config(%s) %T is not an object", ns.name, ref, value)
+	}
+	return obj, nil
+} //GetStringMap()
+
+// GetStringSlice reads a list of strings from the sources
+// list items that are not strings are formatted as strings
+// and a string value is split on commas, e.g. "a,b,c" -> []string{"a","b","c"}
+// it returns an empty slice if ref is not configured in any source
+func (c *Config) GetStringSlice(ref string) ([]string, error) {
+	ref = c.resolveAlias(ref)
+	value, _, err := c.getFromSources(ref, []interface{}{})
+	if err != nil {
+		//not a list, try a single string value
+		s, ns, stringErr := c.getFromSources(ref, "")
+		if stringErr != nil || s == nil {
+			return nil, err
+		}
+		str, ok := s.(string)
+		if !ok {
+			return nil, errors.Errorf("source(%s).config(%s) %T is not a list or string", ns.name, ref, s)
+		}
+		value = strings.Split(str, ",")
+	}
+
+	list := []string{}
+	switch value := value.(type) {
+	case nil:
+	case []string:
+		for _, item := range value {
+			list = append(list, strings.TrimSpace(item))
+		}
+	case []interface{}:
+		for _, item := range value {
+			list = append(list, fmt.Sprintf("%v", item))
+		}
+	default:
+		return nil, errors.Errorf("config(%s) %T is not a list", ref, value)
+	}
+	return list, nil
+} //GetStringSlice()
+
+// GetWithFallback reads the first of names that is configured in any source,
+// e.g. while a key is renamed and some sources still have the old name:
+//
+//	v, err := config.GetWithFallback([]string{"db.address", "db.host"}, "localhost")
+//
+// names after the first are deprecated and a warning is logged when they are used
+// it returns defaultValue when none of the names are configured
+// and the value has the type of defaultValue, so it must not be nil
+// like GetStringMap(), it reads directly from the sources and may be called before Load()
+func (c *Config) GetWithFallback(names []string, defaultValue interface{}) (interface{}, error) {
+	if len(names) == 0 {
+		return nil, errors.Errorf("GetWithFallback() called without names")
+	}
+	if defaultValue == nil {
+		return nil, errors.Errorf("GetWithFallback(%s) called with nil default value", names[0])
+	}
+	for i, name := range names {
+		ref := c.resolveAlias(name)
+		value, _, err := c.getFromSources(ref, defaultValue)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			continue
+		}
+		if i > 0 {
+			Logger().Infof("config: found value at deprecated key %q, please migrate to %q", name, names[0])
+		}
+		return value, nil
+	}
+	return defaultValue, nil
+} //GetWithFallback()
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-msvc/errors"
+)
+
+// testStructSource returns its value without converting into tmpl
+// like a source that ignores the template
+type testStructSource struct {
+	value interface{}
+}
+
+func (s testStructSource) GetInto(name string, tmpl interface{}) (interface{}, error) {
+	return s.value, nil
+}
+
+func TestGetStringMap(t *testing.T) {
+	reset(t)
+	addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{"labels": map[string]interface{}{"a": "1", "b": 2.0}}})
+	m, err := GetStringMap("ms.labels")
+	if err != nil {
+		t.Fatalf("failed: %+v", err)
+	}
+	if !reflect.DeepEqual(m, map[string]interface{}{"a": "1", "b": 2.0}) {
+		t.Fatalf("got %+v", m)
+	}
+	if m, err := GetStringMap("ms.other"); err != nil || len(m) != 0 {
+		t.Fatalf("absent got %+v,%v", m, err)
+	}
+}
+
+func TestGetStringMapConvertsStruct(t *testing.T) {
+	reset(t)
+	if err := AddSource("struct", testStructSource{value: testServerConfig{Host: "a", Port: 1}}); err != nil {
+		t.Fatalf("failed to add source: %+v", err)
+	}
+	m, err := GetStringMap("ms.server")
+	if err != nil {
+		t.Fatalf("failed: %+v", err)
+	}
+	if !reflect.DeepEqual(m, map[string]interface{}{"host": "a", "port": 1.0}) {
+		t.Fatalf("got %+v", m)
+	}
+}
+
+func TestGetStringSlice(t *testing.T) {
+	reset(t)
+	addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{
+		"list":   []interface{}{"a", 1.0, true},
+		"string": "a,b,c",
+	}})
+	for ref, expected := range map[string][]string{
+		"ms.list":   {"a", "1", "true"},
+		"ms.string": {"a", "b", "c"},
+		"ms.other":  {},
+	} {
+		list, err := GetStringSlice(ref)
+		if err != nil {
+			t.Fatalf("%s failed: %+v", ref, err)
+		}
+		if !reflect.DeepEqual(list, expected) {
+			t.Fatalf("%s got %+v, expected %+v", ref, list, expected)
+		}
+	}
+}
+
+// testNumberSource fails to get a list and returns a number instead of a string
+type testNumberSource struct{}
+
+func (testNumberSource) GetInto(name string, tmpl interface{}) (interface{}, error) {
+	if _, ok := tmpl.([]interface{}); ok {
+		return nil, errors.Errorf("not a list")
+	}
+	return 123, nil
+}
+
+func TestGetStringSliceNotString(t *testing.T) {
+	reset(t)
+	if err := AddSource("number", testNumberSource{}); err != nil {
+		t.Fatalf("failed to add source: %+v", err)
+	}
+	if list, err := GetStringSlice("ms.list"); err == nil {
+		t.Fatalf("got %+v instead of error", list)
+	}
+}
+
+func TestGetWithFallback(t *testing.T) {
+	tests := map[string]struct {
+		value      map[string]interface{}
+		expected   interface{}
+		deprecated bool
+	}{
+		"primary":         {value: map[string]interface{}{"address": "primary"}, expected: "primary"},
+		"fallback":        {value: map[string]interface{}{"host": "fallback"}, expected: "fallback", deprecated: true},
+		"neither":         {value: map[string]interface{}{}, expected: "localhost"},
+		"primary and old": {value: map[string]interface{}{"address": "primary", "host": "fallback"}, expected: "primary"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			reset(t)
+			addTestSource(t, map[string]interface{}{"db": test.value})
+			w := captureLog(t)
+			value, err := GetWithFallback([]string{"db.address", "db.host"}, "localhost")
+			if err != nil || value != test.expected {
+				t.Fatalf("got %v,%v", value, err)
+			}
+			deprecated := false
+			for _, msg := range w.messages {
+				if msg == `config: found value at deprecated key "db.host", please migrate to "db.address"` {
+					deprecated = true
+				}
+			}
+			if deprecated != test.deprecated {
+				t.Fatalf("deprecated=%v, logged %q", deprecated, w.messages)
+			}
+		})
+	}
+	if _, err := GetWithFallback(nil, ""); err == nil {
+		t.Fatalf("no error without names")
+	}
+	if _, err := GetWithFallback([]string{"db.host"}, nil); err == nil {
+		t.Fatalf("no error without default value")
+	}
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/go-msvc/errors"
+)
+
+// HealthChecker is implemented by sources that can check if they are reachable,
+// e.g. a database source that pings the database
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// SourceError is the error of one source
+type SourceError struct {
+	Source string
+	Err    error
+}
+
+func (e SourceError) Error() string {
+	return "source(" + e.Source + "): " + e.Err.Error()
+}
+
+func (e SourceError) Unwrap() error {
+	return e.Err
+}
+
+// SourceErrors is returned from HealthCheck() with all unhealthy sources, sorted by name
+type SourceErrors []SourceError
+
+// Error lists all errors, one per line
+func (errs SourceErrors) Error() string {
+	s := make([]string, len(errs))
+	for i, err := range errs {
+		s[i] = err.Error()
+	}
+	return strings.Join(s, "\n")
+}
+
+func (errs SourceErrors) Unwrap() []error {
+	list := make([]error, len(errs))
+	for i, err := range errs {
+		list[i] = err
+	}
+	return list
+}
+
+// HealthCheck checks all sources that implement HealthChecker at the same time
+// and returns SourceErrors for those that failed or did not respond before ctx is done
+// other sources are assumed to be healthy
+func (c *Config) HealthCheck(ctx context.Context) error {
+	errs := SourceErrors{}
+	for name, err := range c.HealthCheckAll(ctx) {
+		if err != nil {
+			errs = append(errs, SourceError{Source: name, Err: err})
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Source < errs[j].Source })
+	return errs
+}
+
+// HealthCheckAll is HealthCheck() that returns the result of each source by name,
+// with nil for healthy sources, e.g. for an admin endpoint
+func (c *Config) HealthCheckAll(ctx context.Context) map[string]error {
+	c.moduleDataMutex.Lock()
+	sourceByName := map[string]Source{}
+	for _, ns := range c.allSources() {
+		sourceByName[ns.name] = ns.source
+	}
+	for ref, s := range c.constructorSourceByRef {
+		sourceByName["constructor:"+ref] = s
+	}
+	c.moduleDataMutex.Unlock()
+
+	type result struct {
+		name string
+		err  error
+	}
+	resultByName := map[string]error{}
+	results := make(chan result, len(sourceByName)) //buffered so late checks do not block
+	pending := map[string]bool{}
+	for name, s := range sourceByName {
+		resultByName[name] = nil
+		hc, ok := s.(HealthChecker)
+		if !ok {
+			continue
+		}
+		pending[name] = true
+		go func(name string, hc HealthChecker) {
+			results <- result{name: name, err: hc.HealthCheck(ctx)}
+		}(name, hc)
+	}
+	for len(pending) > 0 {
+		select {
+		case r := <-results:
+			resultByName[r.name] = r.err
+			delete(pending, r.name)
+		case <-ctx.Done():
+			for name := range pending {
+				resultByName[name] = errors.Wrapf(ctx.Err(), "no response")
+			}
+			return resultByName
+		}
+	}
+	return resultByName
+} //HealthCheckAll()
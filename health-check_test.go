@@ -0,0 +1,64 @@
+package config
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-msvc/errors"
+)
+
+// testHealthSource is a testSource with a health check
+type testHealthSource struct {
+	testSource
+	err   error
+	delay time.Duration
+}
+
+func (s *testHealthSource) HealthCheck(ctx context.Context) error {
+	select {
+	case <-time.After(s.delay):
+		return s.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestHealthCheck(t *testing.T) {
+	reset(t)
+	addTestSource(t, nil) //not a HealthChecker
+	if err := AddSource("ok", &testHealthSource{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := HealthCheck(context.Background()); err != nil {
+		t.Fatalf("unhealthy: %+v", err)
+	}
+
+	if err := AddSource("down", &testHealthSource{err: errors.Errorf("connection refused")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddSource("slow", &testHealthSource{delay: time.Minute}); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := HealthCheck(ctx)
+	errs, ok := err.(SourceErrors)
+	if !ok || len(errs) != 2 || errs[0].Source != "down" || errs[1].Source != "slow" {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if !strings.Contains(err.Error(), "source(down): connection refused") {
+		t.Fatalf("error: %v", err)
+	}
+	if len(errs.Unwrap()) != 2 {
+		t.Fatalf("unwrapped %d errors", len(errs.Unwrap()))
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	results := HealthCheckAll(ctx)
+	if len(results) != 4 || results["test"] != nil || results["ok"] != nil || results["down"] == nil || results["slow"] == nil {
+		t.Fatalf("results %v", results)
+	}
+}
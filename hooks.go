@@ -0,0 +1,56 @@
+package config
+
+import (
+	"github.com/go-msvc/errors"
+)
+
+// AddPreLoadHook adds a function that is called at the start of Load(),
+// before any source is read, e.g. to set the decryption key with SetEncryptionKey()
+// hooks are called in the order they were added and
+// Load() fails without reading the sources when one fails
+func (c *Config) AddPreLoadHook(fn func() error) {
+	if fn == nil {
+		panic("config.AddPreLoadHook(nil)")
+	}
+	c.hookMutex.Lock()
+	defer c.hookMutex.Unlock()
+	c.preLoadHooks = append(c.preLoadHooks, fn)
+}
+
+// AddPostLoadHook adds a function that is called after Load() loaded and validated all config,
+// so it may call Get()
+// hooks are called in the order they were added and Load() returns the error
+// when one fails, but the loaded config remains available
+func (c *Config) AddPostLoadHook(fn func() error) {
+	if fn == nil {
+		panic("config.AddPostLoadHook(nil)")
+	}
+	c.hookMutex.Lock()
+	defer c.hookMutex.Unlock()
+	c.postLoadHooks = append(c.postLoadHooks, fn)
+}
+
+// ClearHooks removes all pre- and post-load hooks, e.g. between tests
+func (c *Config) ClearHooks() {
+	c.hookMutex.Lock()
+	defer c.hookMutex.Unlock()
+	c.preLoadHooks = nil
+	c.postLoadHooks = nil
+}
+
+// hooks returns copies of the pre- and post-load hooks
+func (c *Config) hooks() (preLoad, postLoad []func() error) {
+	c.hookMutex.Lock()
+	defer c.hookMutex.Unlock()
+	return append([]func() error{}, c.preLoadHooks...), append([]func() error{}, c.postLoadHooks...)
+}
+
+// runHooks calls the hooks in order and stops at the first error
+func runHooks(kind string, hooks []func() error) error {
+	for i, fn := range hooks {
+		if err := fn(); err != nil {
+			return errors.Wrapf(err, "%s hook[%d] failed", kind, i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-msvc/errors"
+)
+
+func TestLoadHooks(t *testing.T) {
+	reset(t)
+	calls := []string{}
+	AddPreLoadHook(func() error {
+		calls = append(calls, "pre1")
+		return nil
+	})
+	AddPreLoadHook(func() error {
+		calls = append(calls, "pre2")
+		return nil
+	})
+	AddPostLoadHook(func() error {
+		calls = append(calls, "post:"+Get("ms.name").(string))
+		return nil
+	})
+	addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{"name": "shop"}})
+	MustConfigure("ms.name", "")
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if strings.Join(calls, ",") != "pre1,pre2,post:shop" {
+		t.Fatalf("calls %v", calls)
+	}
+	if err := Load(); err != nil || len(calls) != 3 {
+		t.Fatalf("hooks called again: %v,%+v", calls, err)
+	}
+}
+
+func TestPreLoadHookError(t *testing.T) {
+	reset(t)
+	AddPreLoadHook(func() error { return errors.Errorf("no key") })
+	postCalled := false
+	AddPostLoadHook(func() error {
+		postCalled = true
+		return nil
+	})
+	calls := 0
+	AddSource("test", sourceFunc(func(name string, tmpl interface{}) (interface{}, error) {
+		calls++
+		return "shop", nil
+	}))
+	MustConfigure("ms.name", "")
+	err := Load()
+	if err == nil || !strings.Contains(err.Error(), "no key") {
+		t.Fatalf("load error %v", err)
+	}
+	if calls != 0 || postCalled || defaultConfig.loaded {
+		t.Fatalf("loaded after pre-load hook failed: %d calls", calls)
+	}
+
+	ClearHooks()
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load without hooks: %+v", err)
+	}
+	if postCalled {
+		t.Fatalf("cleared hook called")
+	}
+}
+
+func TestPostLoadHookError(t *testing.T) {
+	reset(t)
+	AddPostLoadHook(func() error { return errors.Errorf("not ready") })
+	addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{"name": "shop"}})
+	MustConfigure("ms.name", "")
+	err := Load()
+	if err == nil || !strings.Contains(err.Error(), "not ready") {
+		t.Fatalf("load error %v", err)
+	}
+	if v := Get("ms.name"); v != "shop" {
+		t.Fatalf("name %v after post-load hook failed", v)
+	}
+}
+
+func TestPreLoadHookUsesConfig(t *testing.T) {
+	reset(t)
+	AddPreLoadHook(func() error {
+		SetLoader(nil)
+		if !strings.Contains(PrintDefaultsString(), "ms.name") {
+			return errors.Errorf("ms.name not registered")
+		}
+		return nil
+	})
+	addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{"name": "shop"}})
+	MustConfigure("ms.name", "")
+	done := make(chan error, 1)
+	go func() { done <- Load() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("failed to load: %+v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Load() deadlocked when pre-load hook used the config")
+	}
+}
+
+func TestPreLoadHookConcurrentLoad(t *testing.T) {
+	reset(t)
+	var calls int32
+	AddPreLoadHook(func() error {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	})
+	addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{"name": "shop"}})
+	MustConfigure("ms.name", "")
+	wg := sync.WaitGroup{}
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := Load(); err != nil {
+				t.Errorf("failed to load: %+v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Fatalf("pre-load hook called %d times", n)
+	}
+}
+
+type sourceFunc func(name string, tmpl interface{}) (interface{}, error)
+
+func (fn sourceFunc) GetInto(name string, tmpl interface{}) (interface{}, error) {
+	return fn(name, tmpl)
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Config is a set of sources, registered config and loaded values
+// the package functions, e.g. config.Load(), use the Default() config
+// which is enough for most programs, while New() creates an independent
+// config, e.g. to isolate tests or to load config for another service
+type Config struct {
+	moduleDataMutex        sync.Mutex
+	mustConfigureByRef     map[string]interface{}
+	requiredConfigureByRef map[string]bool //true for MustConfigure(), false for MayConfigure()
+	constructorsByType     map[reflect.Type]*constructorInfo
+	sources                []namedSource
+	loader                 Loader //nil for defaultLoader
+	constructorSourceByRef map[string]Source
+
+	mergeMutex    sync.Mutex
+	mergeStrategy MergeStrategy //nil for FirstWinStrategy
+
+	finalizeMutex sync.Mutex
+	finalized     bool //set by Finalize() to prevent adding sources
+
+	configByRefMutex       sync.RWMutex
+	loaded                 bool
+	loadedChan             chan struct{}                //closed when loaded
+	configByRef            map[string]interface{}       //loaded or constructed values from Load()
+	sourceNameByRef        map[string]string            //name of the source that provided each value in configByRef
+	constructorConfigByRef map[string]constructorConfig //config used to construct the items in configByRef
+
+	registrationsMutex sync.Mutex
+	registrations      []Registration
+
+	namespaceMutex sync.Mutex
+	namespaces     []*Namespace
+
+	aliasMutex      sync.Mutex
+	originalByAlias map[string]string
+
+	reloadMutex   sync.Mutex
+	pendingReload *reloadCall
+	dirty         bool //set by Invalidate() to reload on the next Get()
+
+	hookMutex     sync.Mutex
+	preLoadHooks  []func() error
+	postLoadHooks []func() error
+	loadMutex     sync.Mutex //held by Load() while running pre-load hooks and loading
+
+	reloadErrorMutex   sync.Mutex
+	reloadErrorHandler func(error)
+
+	loadErrorMutex    sync.Mutex
+	loadErrorHandlers []*loadErrorHandler
+	loadErrorCount    uint64
+
+	watchMutex    sync.Mutex
+	watchersByRef map[string][]*watcher
+
+	subscriptionMutex  sync.Mutex
+	subscriptionByChan map[<-chan interface{}]*subscription
+
+	versionMutex   sync.Mutex
+	version        uint64
+	versionByRef   map[string]uint64
+	loadedAt       time.Time
+	versionWaiters []versionWaiter
+
+	overrideMutex  sync.RWMutex
+	overrideByName map[string]interface{}
+
+	envMutex     sync.Mutex
+	envVarByName map[string]string
+	envPrefix    string
+
+	validatorMutex   sync.Mutex
+	validatorsByName map[string][]*validator
+
+	transformMutex  sync.Mutex
+	transformsByRef map[string][]*transform
+
+	writableMutex  sync.Mutex
+	writableByName map[string]WritableSource
+
+	encryptionMutex sync.Mutex
+	encryptionKey   []byte //nil to use CONFIG_ENCRYPTION_KEY
+
+	defaultsMutex sync.Mutex
+	defaultByName map[string]interface{}
+}
+
+// New creates a config without any sources or registered config
+func New() *Config {
+	return &Config{
+		mustConfigureByRef:     map[string]interface{}{},
+		requiredConfigureByRef: map[string]bool{},
+		constructorsByType:     map[reflect.Type]*constructorInfo{},
+		sources:                []namedSource{},
+		constructorSourceByRef: map[string]Source{},
+		loadedChan:             make(chan struct{}),
+		configByRef:            map[string]interface{}{},
+		sourceNameByRef:        map[string]string{},
+		constructorConfigByRef: map[string]constructorConfig{},
+		originalByAlias:        map[string]string{},
+		reloadErrorHandler:     logReloadError,
+		watchersByRef:          map[string][]*watcher{},
+		versionByRef:           map[string]uint64{},
+		overrideByName:         map[string]interface{}{},
+		envVarByName:           map[string]string{},
+		transformsByRef:        map[string][]*transform{},
+		subscriptionByChan:     map[<-chan interface{}]*subscription{},
+		validatorsByName:       map[string][]*validator{},
+		writableByName:         map[string]WritableSource{},
+		defaultByName:          map[string]interface{}{},
+	}
+} //New()
+
+// Default returns the config used by the package functions
+func Default() *Config {
+	return defaultConfig
+}
+
+var defaultConfig = New()
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestNewIsolated(t *testing.T) {
+	reset(t)
+	a := New()
+	b := New()
+	a.MustConfigure("ms.name", "")
+	b.MustConfigure("ms.name", "")
+	b.MustConfigure("ms.port", 0)
+	if err := a.AddSource("test", &testSource{value: map[string]interface{}{"ms": map[string]interface{}{"name": "a"}}}); err != nil {
+		t.Fatalf("failed to add source: %+v", err)
+	}
+	if err := b.AddSource("test", &testSource{value: map[string]interface{}{"ms": map[string]interface{}{"name": "b", "port": 2}}}); err != nil {
+		t.Fatalf("failed to add source: %+v", err)
+	}
+	if err := a.Load(); err != nil {
+		t.Fatalf("failed to load a: %+v", err)
+	}
+	if b.Ready() {
+		t.Fatalf("b ready before load")
+	}
+	if err := b.Load(); err != nil {
+		t.Fatalf("failed to load b: %+v", err)
+	}
+	if a.Get("ms.name") != "a" || b.Get("ms.name") != "b" || b.Get("ms.port") != 2 {
+		t.Fatalf("a=%v b=%v,%v", a.Get("ms.name"), b.Get("ms.name"), b.Get("ms.port"))
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatalf("a has b's port: %v", a.Get("ms.port"))
+			}
+		}()
+		a.Get("ms.port")
+	}()
+	if Ready() || len(defaultConfig.sources) != 0 {
+		t.Fatalf("default config changed")
+	}
+	if Default() != defaultConfig {
+		t.Fatalf("Default() is not the default config")
+	}
+}
@@ -0,0 +1,37 @@
+package config
+
+// Invalidate marks the loaded config as stale without reading the sources,
+// so that the next Get() reloads before it returns,
+// e.g. when another process updated a source and the next use must see it
+// without paying for the reload now
+// if that reload fails, the error goes to the OnReloadError() handler
+// and Get() returns the previously loaded value
+func (c *Config) Invalidate() {
+	c.reloadMutex.Lock()
+	defer c.reloadMutex.Unlock()
+	c.dirty = true
+}
+
+// IsDirty is true after Invalidate() until the next reload
+func (c *Config) IsDirty() bool {
+	c.reloadMutex.Lock()
+	defer c.reloadMutex.Unlock()
+	return c.dirty
+}
+
+// reloadIfDirty reloads once after Invalidate()
+func (c *Config) reloadIfDirty() {
+	c.reloadMutex.Lock()
+	dirty := c.dirty
+	c.dirty = false //cleared before reload so that Invalidate() during reload is kept
+	c.reloadMutex.Unlock()
+	if !dirty {
+		return
+	}
+	if err := c.Reload(); err != nil {
+		c.reloadErrorMutex.Lock()
+		fn := c.reloadErrorHandler
+		c.reloadErrorMutex.Unlock()
+		fn(err)
+	}
+}
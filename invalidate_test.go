@@ -0,0 +1,60 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestInvalidate(t *testing.T) {
+	reset(t)
+	MustConfigure("ms.server", testServerConfig{})
+	s := addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{"server": map[string]interface{}{"port": 8080}}})
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+
+	s.set(map[string]interface{}{"ms": map[string]interface{}{"server": map[string]interface{}{"port": 9090}}})
+	if port := Get("ms.server").(testServerConfig).Port; port != 8080 {
+		t.Fatalf("port=%d before Invalidate()", port)
+	}
+	if IsDirty() {
+		t.Fatalf("dirty before Invalidate()")
+	}
+	v := Version()
+
+	Invalidate()
+	if !IsDirty() {
+		t.Fatalf("not dirty after Invalidate()")
+	}
+	if Version() != v {
+		t.Fatalf("Invalidate() reloaded")
+	}
+	if port := Get("ms.server").(testServerConfig).Port; port != 9090 {
+		t.Fatalf("port=%d after Invalidate()", port)
+	}
+	if IsDirty() {
+		t.Fatalf("dirty after Get()")
+	}
+	if Version() != v+1 {
+		t.Fatalf("version=%d, expected %d", Version(), v+1)
+	}
+}
+
+func TestInvalidateReloadError(t *testing.T) {
+	reset(t)
+	MustConfigure("ms.server", testServerConfig{})
+	s := addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{"server": map[string]interface{}{"port": 8080}}})
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	var reloadErr error
+	OnReloadError(func(err error) { reloadErr = err })
+
+	s.set(map[string]interface{}{})
+	Invalidate()
+	if port := Get("ms.server").(testServerConfig).Port; port != 8080 {
+		t.Fatalf("port=%d after failed reload", port)
+	}
+	if reloadErr == nil {
+		t.Fatalf("reload error not reported")
+	}
+}
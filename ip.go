@@ -0,0 +1,135 @@
+package config
+
+import (
+	"encoding/json"
+	"net"
+	"reflect"
+
+	"github.com/go-msvc/errors"
+)
+
+// IP is a net.IP config field written as a string in JSON,
+// e.g. "10.0.0.1" or "::1" instead of the default base64 of net.IP bytes
+//
+//	Listen config.IP `json:"listen" validate:"required"`
+type IP net.IP
+
+// MustParseIP parses s or panics, e.g. for defaults and tests
+func MustParseIP(s string) IP {
+	ip := net.ParseIP(s)
+	if ip == nil {
+		panic(errors.Errorf("invalid IP \"%s\"", s))
+	}
+	return IP(ip)
+}
+
+func (ip IP) String() string {
+	if len(ip) == 0 {
+		return ""
+	}
+	return net.IP(ip).String()
+}
+
+// Validate fails if the IP is set but does not have IPv4 or IPv6 length
+func (ip IP) Validate() error {
+	if len(ip) != 0 && len(ip) != net.IPv4len && len(ip) != net.IPv6len {
+		return errors.Errorf("invalid IP length %d", len(ip))
+	}
+	return nil
+}
+
+func (ip IP) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ip.String())
+}
+
+func (ip *IP) UnmarshalJSON(value []byte) error {
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return errors.Wrapf(err, "IP must be a string")
+	}
+	if s == "" {
+		*ip = nil
+		return nil
+	}
+	parsed := net.ParseIP(s)
+	if parsed == nil {
+		return errors.Errorf("invalid IP \"%s\"", s)
+	}
+	*ip = IP(parsed)
+	return nil
+}
+
+// IPNet is a net.IPNet config field written as a CIDR string in JSON,
+// e.g. "10.0.0.0/8" or "fd00::/8"
+//
+//	AllowedCIDR config.IPNet `json:"allowed_cidr" validate:"required"`
+type IPNet net.IPNet
+
+// MustParseIPNet parses the CIDR s or panics, e.g. for defaults and tests
+func MustParseIPNet(s string) IPNet {
+	_, ipNet, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(errors.Wrapf(err, "invalid CIDR \"%s\"", s))
+	}
+	return IPNet(*ipNet)
+}
+
+// Contains is true if ip is in the network
+func (n IPNet) Contains(ip net.IP) bool {
+	if n.IP == nil {
+		return false
+	}
+	ipNet := net.IPNet(n)
+	return ipNet.Contains(ip)
+}
+
+func (n IPNet) String() string {
+	if n.IP == nil {
+		return ""
+	}
+	ipNet := net.IPNet(n)
+	return ipNet.String()
+}
+
+// Validate fails if the network is set but the IP and mask do not match
+func (n IPNet) Validate() error {
+	if n.IP == nil && n.Mask == nil {
+		return nil
+	}
+	if n.Mask == nil {
+		return errors.Errorf("missing network mask")
+	}
+	if _, bits := n.Mask.Size(); bits == 0 {
+		return errors.Errorf("non-canonical network mask %s", n.Mask)
+	}
+	if len(n.IP) != len(n.Mask) {
+		return errors.Errorf("network IP length %d does not match mask length %d", len(n.IP), len(n.Mask))
+	}
+	return nil
+}
+
+func (n IPNet) MarshalJSON() ([]byte, error) {
+	return json.Marshal(n.String())
+}
+
+func (n *IPNet) UnmarshalJSON(value []byte) error {
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return errors.Wrapf(err, "CIDR must be a string")
+	}
+	if s == "" {
+		*n = IPNet{}
+		return nil
+	}
+	_, ipNet, err := net.ParseCIDR(s)
+	if err != nil {
+		return errors.Wrapf(err, "invalid CIDR \"%s\"", s)
+	}
+	*n = IPNet(*ipNet)
+	return nil
+}
+
+var (
+	ipType    = reflect.TypeOf(IP{})
+	ipNetType = reflect.TypeOf(IPNet{})
+)
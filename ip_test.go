@@ -0,0 +1,107 @@
+package config
+
+import (
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+)
+
+type testNetworkConfig struct {
+	Listen      IP    `json:"listen"`
+	AllowedCIDR IPNet `json:"allowed_cidr" validate:"required"`
+}
+
+func TestIPNetConfig(t *testing.T) {
+	reset(t)
+	MustConfigure("net", testNetworkConfig{})
+	addTestSource(t, map[string]interface{}{"net": map[string]interface{}{
+		"listen":       "::1",
+		"allowed_cidr": "10.1.0.0/16",
+	}})
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	c := Get("net").(testNetworkConfig)
+	if !net.IP(c.Listen).Equal(net.IPv6loopback) {
+		t.Fatalf("listen=%s", c.Listen)
+	}
+	if !c.AllowedCIDR.Contains(net.ParseIP("10.1.2.3")) || c.AllowedCIDR.Contains(net.ParseIP("10.2.0.1")) {
+		t.Fatalf("allowed_cidr=%s", c.AllowedCIDR)
+	}
+
+	//dumped as strings, not as bytes
+	dumped, err := DumpJSON()
+	if err != nil || !strings.Contains(string(dumped), `"allowed_cidr": "10.1.0.0/16"`) {
+		t.Fatalf("dump=%s err=%v", dumped, err)
+	}
+
+	//round-trip through JSON
+	jsonValue, err := json.Marshal(c)
+	if err != nil {
+		t.Fatalf("failed to marshal: %+v", err)
+	}
+	if string(jsonValue) != `{"listen":"::1","allowed_cidr":"10.1.0.0/16"}` {
+		t.Fatalf("json=%s", jsonValue)
+	}
+	var parsed testNetworkConfig
+	if err := json.Unmarshal(jsonValue, &parsed); err != nil {
+		t.Fatalf("failed to unmarshal: %+v", err)
+	}
+	if parsed.Listen.String() != "::1" || parsed.AllowedCIDR.String() != "10.1.0.0/16" {
+		t.Fatalf("parsed=%+v", parsed)
+	}
+}
+
+func TestIPNetInvalid(t *testing.T) {
+	tests := map[string]struct {
+		value map[string]interface{}
+		err   string
+	}{
+		"malformed CIDR": {value: map[string]interface{}{"allowed_cidr": "10.1.0.0/33"}, err: "invalid CIDR \"10.1.0.0/33\""},
+		"malformed IP":   {value: map[string]interface{}{"listen": "10.1.0", "allowed_cidr": "10.0.0.0/8"}, err: "invalid IP \"10.1.0\""},
+		"missing CIDR":   {value: map[string]interface{}{"listen": "10.1.0.1"}, err: "invalid field(allowed_cidr) because required"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			reset(t)
+			MustConfigure("net", testNetworkConfig{})
+			addTestSource(t, map[string]interface{}{"net": test.value})
+			err := Load()
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("err=%v, expected %s", err, test.err)
+			}
+		})
+	}
+}
+
+func TestIPValidate(t *testing.T) {
+	if err := MustParseIP("1.2.3.4").Validate(); err != nil {
+		t.Fatalf("valid IP: %+v", err)
+	}
+	if err := (IP{1, 2, 3}).Validate(); err == nil {
+		t.Fatalf("3 byte IP is valid")
+	}
+	if err := MustParseIPNet("fd00::/8").Validate(); err != nil {
+		t.Fatalf("valid network: %+v", err)
+	}
+	if err := (IPNet{IP: net.IP{10, 0, 0, 0}}).Validate(); err == nil {
+		t.Fatalf("network without mask is valid")
+	}
+	//nested fields are validated
+	type testNested struct {
+		Net testNetworkConfig `json:"net"`
+	}
+	err := validateStruct(testNested{Net: testNetworkConfig{Listen: IP{1}, AllowedCIDR: MustParseIPNet("10.0.0.0/8")}})
+	if err == nil || !strings.Contains(err.Error(), "invalid field(net.listen) because invalid IP length 1") {
+		t.Fatalf("err=%v", err)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatalf("MustParseIPNet() did not panic")
+			}
+		}()
+		MustParseIPNet("10.0.0.0")
+	}()
+}
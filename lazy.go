@@ -0,0 +1,54 @@
+package config
+
+import (
+	"fmt"
+	"sync"
+)
+
+// LazyGet returns a func that reads ref from the sources on the first call
+// and returns the same value on subsequent calls until the next Reload()
+// defaultValue is used as template to parse the value into
+// and is returned if ref is not configured in any source
+// unlike Get(), ref need not be registered with MustConfigure()
+// the returned func is safe for concurrent use
+func (c *Config) LazyGet(ref string, defaultValue interface{}) func() (interface{}, error) {
+	if defaultValue == nil {
+		panic(fmt.Sprintf("config.LazyGet(%s) cannot use default nil", ref))
+	}
+	ref = c.resolveAlias(ref)
+	var (
+		mutex         sync.Mutex
+		cached        bool
+		cachedVersion uint64
+		cachedValue   interface{}
+	)
+	return func() (interface{}, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		v := c.Version()
+		if cached && cachedVersion == v {
+			return cachedValue, nil
+		}
+		value, _, err := c.getFromSources(ref, defaultValue)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			value = defaultValue
+		}
+		cached, cachedVersion, cachedValue = true, v, value
+		return value, nil
+	}
+} //LazyGet()
+
+// LazyMust is LazyGet() that panics on error
+func (c *Config) LazyMust(ref string, defaultValue interface{}) func() interface{} {
+	get := c.LazyGet(ref, defaultValue)
+	return func() interface{} {
+		value, err := get()
+		if err != nil {
+			panic(fmt.Sprintf("config(%s) failed: %+v", ref, err))
+		}
+		return value
+	}
+}
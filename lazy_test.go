@@ -0,0 +1,78 @@
+package config
+
+import (
+	"sync"
+	"testing"
+)
+
+// testCountingSource counts the calls to GetInto()
+type testCountingSource struct {
+	testSource
+	mutex sync.Mutex
+	calls int
+}
+
+func (s *testCountingSource) GetInto(name string, tmpl interface{}) (interface{}, error) {
+	s.mutex.Lock()
+	s.calls++
+	s.mutex.Unlock()
+	return s.testSource.GetInto(name, tmpl)
+}
+
+func (s *testCountingSource) count() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.calls
+}
+
+func TestLazyGet(t *testing.T) {
+	reset(t)
+	MustConfigure("ms.name", "")
+	s := &testCountingSource{testSource: testSource{value: map[string]interface{}{"ms": map[string]interface{}{"name": "a", "port": 1}}}}
+	if err := AddSource("test", s); err != nil {
+		t.Fatalf("failed to add source: %+v", err)
+	}
+	port := LazyGet("ms.port", 80)
+	other := LazyMust("ms.other", 123)
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	calls := s.count()
+
+	//concurrent calls read the source once
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := port(); err != nil || v != 1 {
+				t.Errorf("port=%v,%v", v, err)
+			}
+			if v := other(); v != 123 {
+				t.Errorf("other=%v", v)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := s.count() - calls; n != 2 {
+		t.Fatalf("source called %d times instead of 2", n)
+	}
+
+	//read again after reload
+	s.set(map[string]interface{}{"ms": map[string]interface{}{"name": "a", "port": 2}})
+	if err := Reload(); err != nil {
+		t.Fatalf("failed to reload: %+v", err)
+	}
+	if v, err := port(); err != nil || v != 2 {
+		t.Fatalf("port=%v,%v after reload", v, err)
+	}
+}
+
+func TestLazyGetNilDefault(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("LazyGet() with nil default did not panic")
+		}
+	}()
+	LazyGet("ms.name", nil)
+}
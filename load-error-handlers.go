@@ -0,0 +1,75 @@
+package config
+
+import "sync"
+
+// OnLoadError calls fn for each error of Reload(), also when it was called
+// in the background, e.g. by a source that detected a change
+// context is the ref of the value that failed, or "reload" when the reload
+// failed as a whole, e.g. when a source is not available
+// without any fn the errors are not logged here, because Reload() returns them
+// and reloads in the background log them with the OnReloadError() handler
+// call the returned func to stop calling fn
+func (c *Config) OnLoadError(fn func(context string, err error)) func() {
+	if fn == nil {
+		panic("config.OnLoadError() called with nil func")
+	}
+	h := &loadErrorHandler{fn: fn}
+
+	c.loadErrorMutex.Lock()
+	defer c.loadErrorMutex.Unlock()
+	c.loadErrorHandlers = append(c.loadErrorHandlers, h)
+
+	var stopOnce sync.Once
+	return func() {
+		stopOnce.Do(func() {
+			c.loadErrorMutex.Lock()
+			defer c.loadErrorMutex.Unlock()
+			for i, existing := range c.loadErrorHandlers {
+				if existing == h {
+					c.loadErrorHandlers = append(c.loadErrorHandlers[:i:i], c.loadErrorHandlers[i+1:]...)
+					break
+				}
+			}
+		})
+	}
+} //OnLoadError()
+
+// LoadErrorCount returns the number of errors passed to OnLoadError() handlers
+// since the config was created, also when there were no handlers
+func (c *Config) LoadErrorCount() uint64 {
+	c.loadErrorMutex.Lock()
+	defer c.loadErrorMutex.Unlock()
+	return c.loadErrorCount
+}
+
+type loadErrorHandler struct {
+	fn func(context string, err error)
+}
+
+// notifyLoadError calls the OnLoadError() handlers for err from reload()
+// with each failed value of ConfigErrors, or else the whole err
+func (c *Config) notifyLoadError(err error) {
+	errs, ok := err.(ConfigErrors)
+	if !ok {
+		errs = ConfigErrors{{Key: "reload", Err: err}}
+	}
+	c.loadErrorMutex.Lock()
+	c.loadErrorCount += uint64(len(errs))
+	handlers := append([]*loadErrorHandler{}, c.loadErrorHandlers...)
+	c.loadErrorMutex.Unlock()
+
+	for _, e := range errs {
+		for _, h := range handlers {
+			h.call(e.Key, e.Err)
+		}
+	}
+} //notifyLoadError()
+
+func (h *loadErrorHandler) call(context string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			Logger().Errorf("config load error handler panic on config(%s): %v", context, r)
+		}
+	}()
+	h.fn(context, err)
+}
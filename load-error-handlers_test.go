@@ -0,0 +1,90 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-msvc/errors"
+)
+
+func TestOnLoadError(t *testing.T) {
+	reset(t)
+	MustConfigure("db.port", 0)
+	s := addTestSource(t, map[string]interface{}{"db": map[string]interface{}{"port": 8080}})
+	AddValidator("db.port", func(v interface{}) error {
+		if v.(int) < 1024 {
+			return errors.Errorf("port must be >= 1024")
+		}
+		return nil
+	})
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+
+	type loadError struct {
+		context string
+		err     error
+	}
+	loadErrors := []loadError{}
+	stop := OnLoadError(func(context string, err error) {
+		loadErrors = append(loadErrors, loadError{context, err})
+	})
+	OnLoadError(func(context string, err error) {
+		panic("handler failed")
+	})
+
+	//invalid value fails the reload
+	s.set(map[string]interface{}{"db": map[string]interface{}{"port": 80}})
+	w := captureLog(t)
+	if err := Reload(); err == nil {
+		t.Fatalf("reloaded invalid port")
+	}
+	if len(loadErrors) != 1 || loadErrors[0].context != "db.port" || !strings.Contains(loadErrors[0].err.Error(), ">= 1024") {
+		t.Fatalf("load errors %+v", loadErrors)
+	}
+	if n := LoadErrorCount(); n != 1 {
+		t.Fatalf("count %d", n)
+	}
+	w.Lock()
+	if len(w.messages) == 0 || !strings.Contains(w.messages[len(w.messages)-1], "handler failed") {
+		t.Fatalf("panic not logged: %v", w.messages)
+	}
+	w.Unlock()
+	if Get("db.port") != 8080 {
+		t.Fatalf("invalid port was used")
+	}
+
+	//stopped handler is not called
+	stop()
+	stop()
+	if err := Reload(); err == nil {
+		t.Fatalf("reloaded invalid port")
+	}
+	if len(loadErrors) != 1 {
+		t.Fatalf("stopped handler called: %+v", loadErrors)
+	}
+	if n := LoadErrorCount(); n != 2 {
+		t.Fatalf("count %d", n)
+	}
+}
+
+func TestLoadErrorNotLoggedByReload(t *testing.T) {
+	reset(t)
+	MustConfigure("db.port", 0)
+	s := addTestSource(t, map[string]interface{}{"db": map[string]interface{}{"port": 8080}})
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	s.set(map[string]interface{}{"db": map[string]interface{}{"port": "x"}})
+	w := captureLog(t)
+	if err := Reload(); err == nil {
+		t.Fatalf("reloaded invalid port")
+	}
+	w.Lock()
+	defer w.Unlock()
+	for _, msg := range w.messages {
+		if strings.Contains(msg, "failed") {
+			t.Fatalf("error logged and returned: %v", w.messages)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"strings"
+)
+
+// ConfigError is the error of one value that Load() or Reload() failed to read
+type ConfigError struct {
+	Key string
+	Err error
+}
+
+func (e ConfigError) Error() string {
+	return e.Err.Error()
+}
+
+func (e ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// ConfigErrors is returned from Load() and Reload() with all values
+// that are missing or invalid, sorted by key, so they can all be fixed at once
+type ConfigErrors []ConfigError
+
+// Error lists all errors, one per line
+func (errs ConfigErrors) Error() string {
+	s := make([]string, len(errs))
+	for i, err := range errs {
+		s[i] = err.Error()
+	}
+	return strings.Join(s, "\n")
+}
+
+func (errs ConfigErrors) Unwrap() []error {
+	list := make([]error, len(errs))
+	for i, err := range errs {
+		list[i] = err
+	}
+	return list
+}
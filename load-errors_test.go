@@ -0,0 +1,37 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestLoadAllErrors(t *testing.T) {
+	reset(t)
+	MustConfigure("ms.name", "")
+	MustConfigure("ms.code", "")
+	MustConfigure("ms.server", testValidateConfig{})
+	MayConfigure("ms.optional", "")
+	MustConstruct("ms.greeter", testGreeterType)
+	addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{"server": map[string]interface{}{"port": 80, "name": "abc", "level": "info", "code": "abc,1", "retries": 0}}})
+	err := Load()
+	errs, ok := err.(ConfigErrors)
+	if !ok {
+		t.Fatalf("expected ConfigErrors, got %T: %v", err, err)
+	}
+	keys := []string{}
+	for _, e := range errs {
+		keys = append(keys, e.Key)
+	}
+	if len(keys) != 4 || keys[0] != "ms.code" || keys[1] != "ms.greeter" || keys[2] != "ms.name" || keys[3] != "ms.server" {
+		t.Fatalf("wrong keys: %v", keys)
+	}
+	if len(errs.Unwrap()) != 4 {
+		t.Fatalf("wrong unwrap: %v", errs.Unwrap())
+	}
+	expected := "config(ms.code) not found in any source\n" +
+		"config(ms.greeter) cannot load without any registered constructors for config.testGreeter\n" +
+		"config(ms.name) not found in any source\n" +
+		"invalid source(test).config(ms.server) because invalid field(host) because required"
+	if err.Error() != expected {
+		t.Fatalf("wrong error:\n%s\nexpected:\n%s", err, expected)
+	}
+}
@@ -0,0 +1,248 @@
+package config
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-msvc/errors"
+)
+
+// Loader reads the values registered with MustConfigure() and MayConfigure()
+// from the sources during Load() and Reload()
+// the default loader reads one value at a time, use SetLoader() to change it,
+// e.g. config.SetLoader(config.NewParallelLoader(10))
+//
+// sources are in the order they must be used, and the first source that
+// has a value is used, or values are merged with the MergeStrategy of each source,
+// unless the loader implements another strategy
+// tmplByRef has the registered template of each value, see Source.GetInto()
+// the result must not have values that are not configured in any source
+// errors of single values are returned as ConfigErrors with the values that were read,
+// other errors, e.g. when ctx is done, fail the whole load
+type Loader interface {
+	Load(ctx context.Context, tmplByRef map[string]interface{}, sources []NamedSource) (map[string]LoadedValue, error)
+}
+
+// NamedSource is a source and the name it was added with
+// MergeStrategy combines its value with the value from the sources before it,
+// it is nil for overrides, env and defaults that are only used when no other source has the value
+type NamedSource struct {
+	Name          string
+	Source        Source
+	MergeStrategy MergeStrategy
+}
+
+// LoadedValue is a value read by a Loader and the name of the source that had it
+type LoadedValue struct {
+	Value      interface{}
+	SourceName string
+}
+
+// SetLoader replaces the loader used by Load() and Reload()
+// nil restores the default loader
+func (c *Config) SetLoader(l Loader) {
+	c.moduleDataMutex.Lock()
+	defer c.moduleDataMutex.Unlock()
+	c.loader = l
+}
+
+// defaultLoader reads one value at a time
+type defaultLoader struct{}
+
+func (defaultLoader) Load(ctx context.Context, tmplByRef map[string]interface{}, sources []NamedSource) (map[string]LoadedValue, error) {
+	loadedByRef := map[string]LoadedValue{}
+	errs := ConfigErrors{}
+	for ref, tmpl := range tmplByRef {
+		if err := ctx.Err(); err != nil {
+			return nil, errors.Wrapf(err, "stopped before config(%s)", ref)
+		}
+		loaded, ok, err := readValue(sources, ref, tmpl)
+		if err != nil {
+			errs = append(errs, ConfigError{Key: ref, Err: err})
+			continue
+		}
+		if ok {
+			loadedByRef[ref] = loaded
+		}
+	}
+	if len(errs) > 0 {
+		return loadedByRef, errs
+	}
+	return loadedByRef, nil
+} //defaultLoader.Load()
+
+// NewParallelLoader returns a loader that reads up to concurrency values at the same time,
+// e.g. when sources are remote and there are many values
+// all sources must allow GetInto() to be called concurrently
+func NewParallelLoader(concurrency int) Loader {
+	if concurrency < 1 {
+		panic("config.NewParallelLoader() needs concurrency > 0")
+	}
+	return parallelLoader{concurrency: concurrency}
+}
+
+type parallelLoader struct {
+	concurrency int
+}
+
+func (l parallelLoader) Load(ctx context.Context, tmplByRef map[string]interface{}, sources []NamedSource) (map[string]LoadedValue, error) {
+	var (
+		mutex       sync.Mutex
+		wg          sync.WaitGroup
+		loadedByRef = map[string]LoadedValue{}
+		errs        = ConfigErrors{}
+		stopErr     error
+		slots       = make(chan struct{}, l.concurrency)
+	)
+	for ref, tmpl := range tmplByRef {
+		if err := ctx.Err(); err != nil {
+			stopErr = errors.Wrapf(err, "stopped before config(%s)", ref)
+			break
+		}
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(ref string, tmpl interface{}) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			loaded, ok, err := readValue(sources, ref, tmpl)
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err != nil {
+				errs = append(errs, ConfigError{Key: ref, Err: err})
+				return
+			}
+			if ok {
+				loadedByRef[ref] = loaded
+			}
+		}(ref, tmpl)
+	}
+	wg.Wait()
+	if stopErr != nil {
+		return nil, stopErr
+	}
+	if len(errs) > 0 {
+		return loadedByRef, errs
+	}
+	return loadedByRef, nil
+} //parallelLoader.Load()
+
+// NewCircuitBreakerLoader returns a loader that uses inner to read the values,
+// but stops calling a source after maxErrors consecutive errors from it,
+// e.g. so that a source that cannot connect is not called for each value
+// an open source fails without being called, until retryAfter has passed,
+// then it is called once more to see if it recovered
+func NewCircuitBreakerLoader(inner Loader, maxErrors int, retryAfter time.Duration) *CircuitBreakerLoader {
+	if inner == nil {
+		panic("config.NewCircuitBreakerLoader(nil)")
+	}
+	if maxErrors < 1 {
+		panic("config.NewCircuitBreakerLoader() needs maxErrors > 0")
+	}
+	return &CircuitBreakerLoader{
+		inner:         inner,
+		maxErrors:     maxErrors,
+		retryAfter:    retryAfter,
+		now:           time.Now,
+		breakerByName: map[string]*breakerSource{},
+	}
+}
+
+// CircuitBreakerLoader implements Loader
+type CircuitBreakerLoader struct {
+	inner         Loader
+	maxErrors     int
+	retryAfter    time.Duration
+	now           func() time.Time
+	mutex         sync.Mutex
+	breakerByName map[string]*breakerSource
+}
+
+func (l *CircuitBreakerLoader) Load(ctx context.Context, tmplByRef map[string]interface{}, sources []NamedSource) (map[string]LoadedValue, error) {
+	wrapped := make([]NamedSource, len(sources))
+	l.mutex.Lock()
+	for i, ns := range sources {
+		b, ok := l.breakerByName[ns.Name]
+		if !ok {
+			b = &breakerSource{l: l, name: ns.Name}
+			l.breakerByName[ns.Name] = b
+		}
+		b.source = ns.Source //the source may be replaced, keep its state by name
+		wrapped[i] = ns
+		wrapped[i].Source = b
+	}
+	l.mutex.Unlock()
+	return l.inner.Load(ctx, tmplByRef, wrapped)
+}
+
+// OpenSources returns the names of sources that are currently not called
+func (l *CircuitBreakerLoader) OpenSources() []string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	names := []string{}
+	for name, b := range l.breakerByName {
+		if b.isOpen() {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Reset closes all circuits so that all sources are called again
+func (l *CircuitBreakerLoader) Reset() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	for _, b := range l.breakerByName {
+		b.mutex.Lock()
+		b.errors = 0
+		b.mutex.Unlock()
+	}
+}
+
+// breakerSource counts the consecutive errors of a source
+type breakerSource struct {
+	l        *CircuitBreakerLoader
+	name     string
+	source   Source
+	mutex    sync.Mutex
+	errors   int
+	openedAt time.Time
+}
+
+func (b *breakerSource) isOpen() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.errors >= b.l.maxErrors
+}
+
+func (b *breakerSource) GetInto(name string, tmpl interface{}) (interface{}, error) {
+	b.mutex.Lock()
+	if b.errors >= b.l.maxErrors {
+		if b.l.now().Sub(b.openedAt) < b.l.retryAfter {
+			b.mutex.Unlock()
+			return nil, errors.Errorf("source(%s) not called after %d consecutive errors", b.name, b.l.maxErrors)
+		}
+		//try once more, other calls fail until this one returns
+		b.openedAt = b.l.now()
+	}
+	b.mutex.Unlock()
+
+	value, err := b.source.GetInto(name, tmpl)
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if err == nil {
+		b.errors = 0
+		return value, nil
+	}
+	b.errors++
+	if b.errors >= b.l.maxErrors {
+		if b.errors == b.l.maxErrors {
+			Logger().Errorf("source(%s) not called for %v after %d consecutive errors", b.name, b.l.retryAfter, b.errors)
+		}
+		b.openedAt = b.l.now()
+	}
+	return nil, err
+} //breakerSource.GetInto()
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-msvc/errors"
+)
+
+// testFailingSource counts calls and fails while err is set
+type testFailingSource struct {
+	mutex sync.Mutex
+	calls int
+	err   error
+}
+
+func (s *testFailingSource) GetInto(name string, tmpl interface{}) (interface{}, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.calls++
+	return nil, s.err
+}
+
+func (s *testFailingSource) count() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.calls
+}
+
+// loadWithLoader loads the same config with the loader and returns the loaded values and their sources
+func loadWithLoader(t *testing.T, l Loader) (map[string]interface{}, map[string]string) {
+	t.Helper()
+	reset(t)
+	if l != nil {
+		SetLoader(l)
+	}
+	MustConfigure("ms.name", "")
+	MustConfigure("ms.port", 0)
+	MayConfigure("ms.debug", false)
+	MustConfigure("db", testValidateConfig{})
+	SetOverride("ms.port", 8080)
+	if err := AddSource("first", &testSource{value: map[string]interface{}{"ms": map[string]interface{}{"name": "first"}}}); err != nil {
+		t.Fatalf("failed to add source: %+v", err)
+	}
+	if err := AddSource("second", &testSource{value: map[string]interface{}{
+		"ms": map[string]interface{}{"name": "second", "port": 1},
+		"db": map[string]interface{}{"host": "localhost", "port": 5432, "name": "db", "level": "info", "code": "a,1", "retries": 3},
+	}}); err != nil {
+		t.Fatalf("failed to add source: %+v", err)
+	}
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	return defaultConfig.configByRef, defaultConfig.sourceNameByRef
+}
+
+func TestLoaders(t *testing.T) {
+	expectedValues, expectedSources := loadWithLoader(t, nil)
+	if expectedValues["ms.name"] != "first" || expectedValues["ms.port"] != 8080 || expectedValues["ms.debug"] != nil {
+		t.Fatalf("values=%+v", expectedValues)
+	}
+	if expectedSources["ms.name"] != "first" || expectedSources["ms.port"] != overrideSourceName || expectedSources["db"] != "second" {
+		t.Fatalf("sources=%+v", expectedSources)
+	}
+	for name, l := range map[string]Loader{
+		"default":         defaultLoader{},
+		"parallel":        NewParallelLoader(2),
+		"circuit breaker": NewCircuitBreakerLoader(NewParallelLoader(3), 1, time.Minute),
+	} {
+		t.Run(name, func(t *testing.T) {
+			values, sources := loadWithLoader(t, l)
+			if !reflect.DeepEqual(values, expectedValues) || !reflect.DeepEqual(sources, expectedSources) {
+				t.Fatalf("values=%+v sources=%+v", values, sources)
+			}
+		})
+	}
+}
+
+// testLoader returns fixed results
+type testLoader struct {
+	loaded map[string]LoadedValue
+	err    error
+}
+
+func (l testLoader) Load(ctx context.Context, tmplByRef map[string]interface{}, sources []NamedSource) (map[string]LoadedValue, error) {
+	return l.loaded, l.err
+}
+
+func TestSetLoader(t *testing.T) {
+	setup := func(l Loader) {
+		reset(t)
+		SetLoader(l)
+		MustConfigure("ms.name", "")
+		MustConfigure("ms.port", 0)
+		addTestSource(t, map[string]interface{}{})
+	}
+	setup(testLoader{loaded: map[string]LoadedValue{
+		"ms.name": {Value: "test", SourceName: "mock"},
+		"ms.port": {Value: 1, SourceName: "mock"},
+	}})
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if Get("ms.name") != "test" || defaultConfig.sourceNameByRef["ms.name"] != "mock" {
+		t.Fatalf("ms.name=%v from %s", Get("ms.name"), defaultConfig.sourceNameByRef["ms.name"])
+	}
+
+	//errors of single values are reported with missing values
+	setup(testLoader{
+		loaded: map[string]LoadedValue{},
+		err:    ConfigErrors{{Key: "ms.name", Err: errors.Errorf("failed")}},
+	})
+	err := Load()
+	errs, ok := err.(ConfigErrors)
+	if !ok || len(errs) != 2 || errs[0].Key != "ms.name" || errs[0].Error() != "failed" || errs[1].Key != "ms.port" {
+		t.Fatalf("err=%v", err)
+	}
+
+	//other errors fail the load
+	setup(testLoader{err: errors.Errorf("broken")})
+	if err := Load(); err == nil || err.Error() != "broken" {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestParallelLoaderStopped(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := NewParallelLoader(1).Load(ctx, map[string]interface{}{"ms.name": ""}, nil)
+	if err == nil || !strings.Contains(err.Error(), "stopped before config(ms.name)") {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestCircuitBreakerLoader(t *testing.T) {
+	failing := &testFailingSource{err: errors.Errorf("cannot connect")}
+	l := NewCircuitBreakerLoader(defaultLoader{}, 2, time.Minute)
+	now := time.Now()
+	l.now = func() time.Time { return now }
+	sources := []NamedSource{{Name: "remote", Source: failing}}
+	tmplByRef := map[string]interface{}{"a": "", "b": "", "c": "", "d": ""}
+
+	_, err := l.Load(context.Background(), tmplByRef, sources)
+	if errs, ok := err.(ConfigErrors); !ok || len(errs) != 4 {
+		t.Fatalf("err=%v", err)
+	}
+	if failing.count() != 2 || !reflect.DeepEqual(l.OpenSources(), []string{"remote"}) {
+		t.Fatalf("%d calls, open %v", failing.count(), l.OpenSources())
+	}
+
+	//called once more after retryAfter, and stays open if it still fails
+	now = now.Add(time.Minute)
+	l.Load(context.Background(), tmplByRef, sources)
+	if failing.count() != 3 {
+		t.Fatalf("%d calls after retryAfter", failing.count())
+	}
+
+	//closed when it recovers
+	now = now.Add(time.Minute)
+	failing.mutex.Lock()
+	failing.err = nil
+	failing.mutex.Unlock()
+	if _, err := l.Load(context.Background(), tmplByRef, sources); err != nil {
+		t.Fatalf("failed after recovery: %+v", err)
+	}
+	if failing.count() != 7 || len(l.OpenSources()) != 0 {
+		t.Fatalf("%d calls, open %v", failing.count(), l.OpenSources())
+	}
+
+	//reset closes open circuits
+	failing.mutex.Lock()
+	failing.err = errors.Errorf("cannot connect")
+	failing.mutex.Unlock()
+	l.Load(context.Background(), tmplByRef, sources)
+	l.Reset()
+	if len(l.OpenSources()) != 0 {
+		t.Fatalf("open after reset %v", l.OpenSources())
+	}
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/go-msvc/logger"
+)
+
+// logLevelEnv can be set to debug, info or error to change the default log level
+const logLevelEnv = "CONFIG_LOG_LEVEL"
+
+var (
+	logMutex sync.RWMutex
+	log      = logger.New().WithLevel(defaultLogLevel())
+)
+
+// defaultLogLevel is info, because this package has no warning level and logs
+// warnings like deprecated keys as info, unless changed with CONFIG_LOG_LEVEL
+func defaultLogLevel() logger.Level {
+	switch strings.ToLower(os.Getenv(logLevelEnv)) {
+	case "debug":
+		return logger.LevelDebug
+	case "error":
+		return logger.LevelError
+	default:
+		return logger.LevelInfo
+	}
+}
+
+// SetLogger replaces the logger used by this package and the source packages,
+// it may be called while config is used, e.g. while sources are watched
+// nil restores the default logger
+func SetLogger(l logger.Logger) {
+	if l == nil {
+		l = logger.New().WithLevel(defaultLogLevel())
+	}
+	logMutex.Lock()
+	defer logMutex.Unlock()
+	log = l
+}
+
+// SetLogLevel changes the level of the logger used by this package,
+// e.g. config.SetLogLevel(logger.LevelDebug) to see where each value was read from
+func SetLogLevel(level logger.Level) {
+	logMutex.Lock()
+	defer logMutex.Unlock()
+	log = log.WithLevel(level)
+}
+
+// Logger returns the logger used by this package,
+// source packages log with it too, so that SetLogLevel() also applies to them
+func Logger() logger.Logger {
+	logMutex.RLock()
+	defer logMutex.RUnlock()
+	return log
+}
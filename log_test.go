@@ -0,0 +1,54 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/go-msvc/logger"
+)
+
+func TestSetLogLevel(t *testing.T) {
+	reset(t)
+	original := Logger()
+	defer SetLogger(original)
+
+	w := &testLogWriter{}
+	l := logger.New().New(t.Name())
+	l.SetWriter(w)
+	SetLogger(l)
+	if Logger().Name() != l.Name() {
+		t.Fatalf("Logger() is %s instead of %s", Logger().Name(), l.Name())
+	}
+
+	SetLogLevel(logger.LevelError)
+	MustConfigure("ms.server", testServerConfig{})
+	addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{"server": map[string]interface{}{"port": 8080}}})
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	_ = Get("ms.server")
+	if len(w.messages) != 0 {
+		t.Fatalf("logged %d messages at error level: %v", len(w.messages), w.messages)
+	}
+
+	reset(t)
+	SetLogLevel(logger.LevelDebug)
+	MustConfigure("ms.server", testServerConfig{})
+	addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{"server": map[string]interface{}{"port": 8080}}})
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if len(w.messages) == 0 {
+		t.Fatalf("nothing logged at debug level")
+	}
+}
+
+func TestDefaultLogLevel(t *testing.T) {
+	t.Setenv(logLevelEnv, "")
+	if l := defaultLogLevel(); l != logger.LevelInfo {
+		t.Fatalf("default level %v", l)
+	}
+	t.Setenv(logLevelEnv, "DEBUG")
+	if l := defaultLogLevel(); l != logger.LevelDebug {
+		t.Fatalf("debug level %v", l)
+	}
+}
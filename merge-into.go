@@ -0,0 +1,158 @@
+package config
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+
+	"github.com/go-msvc/errors"
+)
+
+// MergeOption changes how MergeInto() merges, and is passed with the sources
+type MergeOption func(*mergeOptions)
+
+type mergeOptions struct {
+	appendSlices bool
+}
+
+// WithAppendSlices makes MergeInto() append slices from later sources
+// instead of replacing them
+func WithAppendSlices() MergeOption {
+	return func(o *mergeOptions) {
+		o.appendSlices = true
+	}
+}
+
+// MergeInto merges the sources in order into target, which must be a pointer to a struct
+// and already has the values that sources may override, e.g.
+//
+//	cfg := baseCfg
+//	err := config.MergeInto(&cfg, envOverrideCfg, fileCfg)
+//
+// a source may be a struct or pointer to a struct of the target type,
+// or any value that can be converted to it with JSON, like map[string]interface{}
+// fields are only overridden by non-zero values, nested structs are merged field by field,
+// maps are merged by key, with map[string]interface{} values merged recursively,
+// and slices are replaced by the last non-nil slice, unless WithAppendSlices() is passed
+// nil sources are skipped
+func MergeInto(target interface{}, sources ...interface{}) error {
+	targetValue := reflect.ValueOf(target)
+	if targetValue.Kind() != reflect.Ptr || targetValue.IsNil() || targetValue.Elem().Kind() != reflect.Struct {
+		return errors.Errorf("MergeInto(%T) needs a pointer to a struct", target)
+	}
+	targetValue = targetValue.Elem()
+	options := mergeOptions{}
+	for _, source := range sources {
+		if opt, ok := source.(MergeOption); ok {
+			opt(&options)
+		}
+	}
+	for i, source := range sources {
+		if _, ok := source.(MergeOption); ok || source == nil {
+			continue
+		}
+		sourceValue, err := mergeSourceValue(targetValue.Type(), source)
+		if err != nil {
+			return errors.Wrapf(err, "cannot merge source[%d]", i)
+		}
+		if sourceValue.IsValid() {
+			mergeValue(targetValue, sourceValue, options)
+		}
+	}
+	return nil
+} //MergeInto()
+
+// mergeSourceValue returns the source as a value of type t,
+// or an invalid value if it is a nil pointer
+func mergeSourceValue(t reflect.Type, source interface{}) (reflect.Value, error) {
+	v := reflect.ValueOf(source)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}, nil
+		}
+		v = v.Elem()
+	}
+	if v.Type() == t {
+		return v, nil
+	}
+	jsonValue, err := json.Marshal(source)
+	if err != nil {
+		return reflect.Value{}, errors.Wrapf(err, "cannot marshal %T to JSON", source)
+	}
+	converted := reflect.New(t)
+	if err := json.Unmarshal(jsonValue, converted.Interface()); err != nil {
+		return reflect.Value{}, errors.Wrapf(err, "cannot convert %T to %v", source, t)
+	}
+	return converted.Elem(), nil
+}
+
+// mergeValue merges source into the settable target of the same type
+func mergeValue(target, source reflect.Value, options mergeOptions) {
+	switch {
+	case source.IsZero():
+		return //keep target
+	case isMergeableStruct(source.Type()):
+		for i := 0; i < source.NumField(); i++ {
+			if target.Field(i).CanSet() {
+				mergeValue(target.Field(i), source.Field(i), options)
+			}
+		}
+	case source.Kind() == reflect.Ptr:
+		if target.IsNil() || !isMergeableStruct(source.Type().Elem()) {
+			copied := reflect.New(source.Type().Elem())
+			copied.Elem().Set(source.Elem())
+			target.Set(copied)
+			return
+		}
+		merged := reflect.New(source.Type().Elem())
+		merged.Elem().Set(target.Elem())
+		mergeValue(merged.Elem(), source.Elem(), options)
+		target.Set(merged)
+	case source.Kind() == reflect.Map:
+		merged := reflect.MakeMapWithSize(source.Type(), target.Len()+source.Len())
+		iter := target.MapRange()
+		for iter.Next() {
+			merged.SetMapIndex(iter.Key(), iter.Value())
+		}
+		iter = source.MapRange()
+		for iter.Next() {
+			value := iter.Value()
+			if existing := merged.MapIndex(iter.Key()); existing.IsValid() && value.Kind() == reflect.Interface && !existing.IsNil() && !value.IsNil() {
+				value = reflect.ValueOf(DeepMergeStrategy(existing.Interface(), value.Interface()))
+			}
+			merged.SetMapIndex(iter.Key(), value)
+		}
+		target.Set(merged)
+	case source.Kind() == reflect.Slice && options.appendSlices:
+		target.Set(reflect.AppendSlice(reflect.MakeSlice(source.Type(), 0, target.Len()+source.Len()), target))
+		target.Set(reflect.AppendSlice(target, source))
+	case source.Kind() == reflect.Interface:
+		if !target.IsNil() {
+			target.Set(reflect.ValueOf(DeepMergeStrategy(target.Interface(), source.Interface())))
+			return
+		}
+		target.Set(source)
+	default:
+		target.Set(source)
+	}
+} //mergeValue()
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// isMergeableStruct is true for structs that are merged field by field,
+// not for types like time.Time that are values without exported fields
+func isMergeableStruct(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) ||
+		reflect.PtrTo(t).Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType) {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() {
+			return true
+		}
+	}
+	return false
+}
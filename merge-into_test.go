@@ -0,0 +1,100 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testMergeDB struct {
+	Host    string `json:"host"`
+	Port    int    `json:"port"`
+	Options map[string]interface{}
+}
+
+type testMergeConfig struct {
+	Name     string            `json:"name"`
+	Debug    bool              `json:"debug"`
+	DB       testMergeDB       `json:"db"`
+	Cache    *testMergeDB      `json:"cache"`
+	Tags     []string          `json:"tags"`
+	Labels   map[string]string `json:"labels"`
+	Started  time.Time         `json:"started"`
+	internal string
+}
+
+func TestMergeInto(t *testing.T) {
+	started := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	base := testMergeConfig{
+		Name:   "base",
+		DB:     testMergeDB{Host: "localhost", Port: 5432, Options: map[string]interface{}{"ssl": map[string]interface{}{"mode": "disable", "ca": "ca.pem"}}},
+		Tags:   []string{"a"},
+		Labels: map[string]string{"team": "x", "env": "dev"},
+	}
+	env := &testMergeConfig{
+		Debug:   true,
+		DB:      testMergeDB{Host: "db1", Options: map[string]interface{}{"ssl": map[string]interface{}{"mode": "require"}, "timeout": 5}},
+		Cache:   &testMergeDB{Host: "cache1"},
+		Labels:  map[string]string{"env": "prod"},
+		Started: started,
+	}
+	file := map[string]interface{}{
+		"name":  "file",
+		"cache": map[string]interface{}{"port": 6379},
+		"tags":  []string{"b", "c"},
+	}
+
+	cfg := base
+	if err := MergeInto(&cfg, env, nil, (*testMergeConfig)(nil), file); err != nil {
+		t.Fatalf("failed to merge: %+v", err)
+	}
+	expected := testMergeConfig{
+		Name:  "file",
+		Debug: true,
+		DB: testMergeDB{Host: "db1", Port: 5432, Options: map[string]interface{}{
+			"ssl":     map[string]interface{}{"mode": "require", "ca": "ca.pem"},
+			"timeout": 5,
+		}},
+		Cache:   &testMergeDB{Host: "cache1", Port: 6379},
+		Tags:    []string{"b", "c"},
+		Labels:  map[string]string{"team": "x", "env": "prod"},
+		Started: started,
+	}
+	if !reflect.DeepEqual(cfg, expected) {
+		t.Fatalf("merged\n%+v\nexpected\n%+v", cfg, expected)
+	}
+
+	//sources are not changed
+	if env.Cache.Port != 0 || len(base.Labels) != 2 || base.Labels["env"] != "dev" ||
+		base.DB.Options["ssl"].(map[string]interface{})["mode"] != "disable" {
+		t.Fatalf("sources changed: %+v %+v", base, env.Cache)
+	}
+
+	//zero values do not override
+	if err := MergeInto(&cfg, testMergeConfig{Name: "", Debug: false}); err != nil || cfg.Name != "file" || !cfg.Debug {
+		t.Fatalf("zero values merged: %+v, %+v", cfg, err)
+	}
+}
+
+func TestMergeIntoAppendSlices(t *testing.T) {
+	cfg := testMergeConfig{Tags: []string{"a"}}
+	if err := MergeInto(&cfg, testMergeConfig{Tags: []string{"b"}}, WithAppendSlices(), testMergeConfig{Tags: []string{"c"}}); err != nil {
+		t.Fatalf("failed to merge: %+v", err)
+	}
+	if !reflect.DeepEqual(cfg.Tags, []string{"a", "b", "c"}) {
+		t.Fatalf("tags %v", cfg.Tags)
+	}
+}
+
+func TestMergeIntoErrors(t *testing.T) {
+	cfg := testMergeConfig{}
+	if err := MergeInto(cfg); err == nil {
+		t.Fatalf("merged into struct that is not a pointer")
+	}
+	if err := MergeInto((*testMergeConfig)(nil)); err == nil {
+		t.Fatalf("merged into nil")
+	}
+	if err := MergeInto(&cfg, map[string]interface{}{"name": 1}); err == nil {
+		t.Fatalf("merged number into string")
+	}
+}
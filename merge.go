@@ -0,0 +1,195 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/go-msvc/errors"
+)
+
+// MergeStrategy combines the value from a source with the value from the sources before it
+// existing is the value from the sources before it and incoming is the value from the source,
+// both not nil, and it returns the value to use
+// structs and maps are passed as map[string]interface{} so that only the
+// fields configured in each source are merged
+type MergeStrategy func(existing, incoming interface{}) interface{}
+
+// FirstWinStrategy uses the value from the first source that has it
+// and does not read the other sources, this is the default
+func FirstWinStrategy(existing, incoming interface{}) interface{} {
+	return existing
+}
+
+// LastWinStrategy reads all sources and uses the value from the last source that has it
+func LastWinStrategy(existing, incoming interface{}) interface{} {
+	return incoming
+}
+
+// DeepMergeStrategy reads all sources and merges objects, e.g.
+// {"db":{"host":"a"}} and {"db":{"port":1}} -> {"db":{"host":"a","port":1}}
+// other values, like strings and lists, use the last source that has it
+func DeepMergeStrategy(existing, incoming interface{}) interface{} {
+	existingObj, ok := existing.(map[string]interface{})
+	if !ok {
+		return incoming
+	}
+	incomingObj, ok := incoming.(map[string]interface{})
+	if !ok {
+		return incoming
+	}
+	merged := make(map[string]interface{}, len(existingObj)+len(incomingObj))
+	for name, value := range existingObj {
+		merged[name] = value
+	}
+	for name, value := range incomingObj {
+		if value == nil {
+			continue
+		}
+		if existingValue, ok := merged[name]; ok && existingValue != nil {
+			value = DeepMergeStrategy(existingValue, value)
+		}
+		merged[name] = value
+	}
+	return merged
+} //DeepMergeStrategy()
+
+// SetMergeStrategy sets how the values of added sources are combined
+// when more than one has a value, nil restores FirstWinStrategy
+// use WithMergeStrategy() to set it for one source
+// overrides are always used as is, and environment variables and defaults
+// are only used when no added source has the value
+// it applies from the next Load() or Reload()
+func (c *Config) SetMergeStrategy(ms MergeStrategy) {
+	if ms == nil {
+		ms = FirstWinStrategy
+	}
+	c.mergeMutex.Lock()
+	defer c.mergeMutex.Unlock()
+	c.mergeStrategy = ms
+}
+
+// CurrentMergeStrategy returns the strategy set with SetMergeStrategy()
+func (c *Config) CurrentMergeStrategy() MergeStrategy {
+	c.mergeMutex.Lock()
+	defer c.mergeMutex.Unlock()
+	if c.mergeStrategy == nil {
+		return FirstWinStrategy
+	}
+	return c.mergeStrategy
+}
+
+// SourceOption is an option of AddSource()
+type SourceOption func(*namedSource)
+
+// WithMergeStrategy sets how the value of this source is combined
+// with the value from the sources before it, instead of the strategy
+// set with SetMergeStrategy()
+func WithMergeStrategy(ms MergeStrategy) SourceOption {
+	return func(ns *namedSource) {
+		ns.mergeStrategy = ms
+	}
+}
+
+// loaderSources returns the sources for readValue() with the merge strategy of each
+// the builtin sources (overrides, env and defaults) keep a nil strategy
+func (c *Config) loaderSources(list []namedSource) []NamedSource {
+	strategy := c.CurrentMergeStrategy()
+	sources := make([]NamedSource, len(list))
+	for i, ns := range list {
+		sources[i] = NamedSource{Name: ns.name, Source: ns.source, MergeStrategy: ns.mergeStrategy}
+		if ns.mergeStrategy == nil && !ns.builtin {
+			sources[i].MergeStrategy = strategy
+		}
+	}
+	return sources
+}
+
+func isFirstWin(ms MergeStrategy) bool {
+	return ms == nil || reflect.ValueOf(ms).Pointer() == reflect.ValueOf(FirstWinStrategy).Pointer()
+}
+
+// readValue returns the value of ref from the sources
+// a value from a source without MergeStrategy is used as is and other sources are not read,
+// or if there is already a value, the source is not used at all,
+// else the value is combined with the value from the sources before it
+// it returns false if ref is not configured in any source
+func readValue(sources []NamedSource, ref string, tmpl interface{}) (LoadedValue, bool, error) {
+	//objects are merged as maps, so that fields not configured in a source
+	//do not replace the values from other sources
+	convert := canMerge(sources) && isObject(tmpl)
+	var value interface{}
+	names := []string{}
+	mergeable := false //true if value may be merged with more sources
+	for i, ns := range sources {
+		if value != nil && (!mergeable || !canMerge(sources[i:])) {
+			break
+		}
+		if value != nil && isFirstWin(ns.MergeStrategy) {
+			continue
+		}
+		readTmpl := tmpl
+		if convert {
+			readTmpl = map[string]interface{}{} //new map for each source, as GetInto() may fill it
+		}
+		v, err := ns.Source.GetInto(ref, readTmpl)
+		if err != nil {
+			return LoadedValue{}, false, errors.Wrapf(err, "failed to get source(%s).config(%s)", ns.Name, ref)
+		}
+		if v == nil {
+			continue
+		}
+		if value == nil {
+			value, mergeable = v, ns.MergeStrategy != nil
+		} else {
+			value = ns.MergeStrategy(value, v)
+		}
+		names = append(names, ns.Name)
+	}
+	if value == nil {
+		return LoadedValue{}, false, nil
+	}
+	if convert {
+		converted, err := getInto(value, "", tmpl)
+		if err != nil {
+			return LoadedValue{}, false, errors.Wrapf(err, "invalid merged source(%s).config(%s)", strings.Join(names, "+"), ref)
+		}
+		value = converted
+	}
+	return LoadedValue{Value: value, SourceName: strings.Join(names, "+")}, true, nil
+} //readValue()
+
+// mergedSource has the value of ref that was merged from multiple sources,
+// so that fields of it can be read like from a single source
+type mergedSource struct {
+	ref   string
+	value interface{}
+}
+
+func (s mergedSource) GetInto(name string, tmpl interface{}) (interface{}, error) {
+	if name == s.ref {
+		return getInto(s.value, "", tmpl)
+	}
+	if strings.HasPrefix(name, s.ref+".") {
+		return getInto(s.value, strings.TrimPrefix(name, s.ref+"."), tmpl)
+	}
+	return nil, nil
+}
+
+// canMerge is true if any of the sources merges its value with the values before it
+func canMerge(sources []NamedSource) bool {
+	for _, ns := range sources {
+		if !isFirstWin(ns.MergeStrategy) {
+			return true
+		}
+	}
+	return false
+}
+
+// isObject is true for struct and map templates
+func isObject(tmpl interface{}) bool {
+	t := reflect.TypeOf(tmpl)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t != nil && (t.Kind() == reflect.Struct || t.Kind() == reflect.Map)
+}
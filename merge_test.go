@@ -0,0 +1,111 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func addMergeTestSources(t *testing.T, opts ...SourceOption) {
+	t.Helper()
+	addTestSource(t, map[string]interface{}{
+		"ms": map[string]interface{}{
+			"server": map[string]interface{}{"host": "localhost"},
+			"name":   "first",
+			"tags":   map[string]interface{}{"a": "1", "nested": map[string]interface{}{"x": 1}},
+		},
+	})
+	if err := AddSource("test2", &testSource{value: map[string]interface{}{
+		"ms": map[string]interface{}{
+			"server": map[string]interface{}{"port": 9090},
+			"name":   "second",
+			"tags":   map[string]interface{}{"b": "2", "nested": map[string]interface{}{"y": 2}},
+		},
+	}}, opts...); err != nil {
+		t.Fatalf("failed to add source: %+v", err)
+	}
+}
+
+func TestMergeStrategy(t *testing.T) {
+	for name, test := range map[string]struct {
+		strategy MergeStrategy
+		server   testServerConfig
+		name     string
+		tags     map[string]interface{}
+	}{
+		"default": {nil, testServerConfig{Host: "localhost"}, "first",
+			map[string]interface{}{"a": "1", "nested": map[string]interface{}{"x": 1.0}}},
+		"first": {FirstWinStrategy, testServerConfig{Host: "localhost"}, "first",
+			map[string]interface{}{"a": "1", "nested": map[string]interface{}{"x": 1.0}}},
+		"last": {LastWinStrategy, testServerConfig{Port: 9090}, "second",
+			map[string]interface{}{"b": "2", "nested": map[string]interface{}{"y": 2.0}}},
+		"deep": {DeepMergeStrategy, testServerConfig{Host: "localhost", Port: 9090}, "second",
+			map[string]interface{}{"a": "1", "b": "2", "nested": map[string]interface{}{"x": 1.0, "y": 2.0}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			reset(t)
+			if test.strategy != nil {
+				SetMergeStrategy(test.strategy)
+			}
+			addMergeTestSources(t)
+			MustConfigure("ms.server", testServerConfig{})
+			MustConfigure("ms.name", "")
+			MustConfigure("ms.tags", map[string]interface{}{})
+			if err := Load(); err != nil {
+				t.Fatalf("failed to load: %+v", err)
+			}
+			if v := Get("ms.server"); v != test.server {
+				t.Fatalf("server %+v", v)
+			}
+			if v := Get("ms.name"); v != test.name {
+				t.Fatalf("name %v", v)
+			}
+			if v := Get("ms.tags"); !reflect.DeepEqual(v, test.tags) {
+				t.Fatalf("tags %v", v)
+			}
+			if m, err := GetStringMap("ms.tags"); err != nil || !reflect.DeepEqual(m, test.tags) {
+				t.Fatalf("string map %v,%+v", m, err)
+			}
+		})
+	}
+}
+
+func TestWithMergeStrategy(t *testing.T) {
+	reset(t)
+	addMergeTestSources(t, WithMergeStrategy(DeepMergeStrategy))
+	MustConfigure("ms.server", testServerConfig{})
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if v := Get("ms.server").(testServerConfig); v.Host != "localhost" || v.Port != 9090 {
+		t.Fatalf("server %+v", v)
+	}
+	if !isFirstWin(CurrentMergeStrategy()) {
+		t.Fatalf("config strategy changed")
+	}
+}
+
+func TestMergeStrategyBuiltinSources(t *testing.T) {
+	reset(t)
+	SetMergeStrategy(LastWinStrategy)
+	addMergeTestSources(t)
+	DeclareDefault("ms.name", "default")
+	DeclareDefault("ms.other", "default")
+	MustConfigure("ms.name", "")
+	MustConfigure("ms.other", "")
+	MustConfigure("ms.server", testServerConfig{})
+	SetOverride("ms.server.host", "override")
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	//defaults only when no source has the value
+	if v := Get("ms.name"); v != "second" {
+		t.Fatalf("name %v", v)
+	}
+	if v := Get("ms.other"); v != "default" {
+		t.Fatalf("other %v", v)
+	}
+	//overrides apply to the merged value
+	if v := Get("ms.server").(testServerConfig); v.Host != "override" || v.Port != 9090 {
+		t.Fatalf("server %+v", v)
+	}
+}
@@ -0,0 +1,26 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Must panics if err is not nil, else returns val
+// to use when config is required for the program to continue, e.g.:
+//
+//	db := config.Must(config.GetStringMap("db"))
+func Must[T any](val T, err error) T {
+	if err != nil {
+		panic(fmt.Sprintf("config.Must[%v] failed (check your config sources): %+v", reflect.TypeOf((*T)(nil)).Elem(), err))
+	}
+	return val
+}
+
+// MustErr panics if err is not nil, e.g.:
+//
+//	config.MustErr(config.Load())
+func MustErr(err error) {
+	if err != nil {
+		panic(fmt.Sprintf("config failed (check your config sources): %+v", err))
+	}
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-msvc/errors"
+)
+
+func TestMust(t *testing.T) {
+	if v := Must(123, nil); v != 123 {
+		t.Fatalf("got %v", v)
+	}
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatalf("Must() with error did not panic")
+		}
+		if s := fmt.Sprintf("%v", r); !strings.Contains(s, "config.Must[map[string]interface {}] failed") || !strings.Contains(s, "no db") {
+			t.Fatalf("panic: %s", s)
+		}
+	}()
+	Must(map[string]interface{}(nil), errors.Errorf("no db"))
+}
+
+func TestMustErr(t *testing.T) {
+	MustErr(nil)
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(fmt.Sprintf("%v", r), "no db") {
+			t.Fatalf("panic: %v", r)
+		}
+	}()
+	MustErr(errors.Errorf("no db"))
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Namespace registers and gets config under a prefix, e.g. in a library:
+//
+//	var ns = config.NewNamespace("mylib")
+//
+//	func init() {
+//		ns.MustConfigure("db", dbConfig{}) //reads "mylib.db"
+//	}
+//
+// unlike Sub(), it can have its own sources with UseOwnSources()
+type Namespace struct {
+	mutex      sync.Mutex
+	c          *Config
+	prefix     string
+	registered bool
+}
+
+// NewNamespace creates a namespace for refs starting with prefix + "."
+// that uses the sources of this config
+func (c *Config) NewNamespace(prefix string) *Namespace {
+	if !validReference(prefix) {
+		panic(fmt.Sprintf("invalid config namespace(%s), expecting dot-notation reference", prefix))
+	}
+	ns := &Namespace{c: c, prefix: prefix}
+	c.namespaceMutex.Lock()
+	defer c.namespaceMutex.Unlock()
+	c.namespaces = append(c.namespaces, ns)
+	return ns
+}
+
+// ListNamespaces returns all namespaces created with NewNamespace() in the order they were created
+func (c *Config) ListNamespaces() []*Namespace {
+	c.namespaceMutex.Lock()
+	defer c.namespaceMutex.Unlock()
+	return append([]*Namespace{}, c.namespaces...)
+}
+
+// Prefix returns the prefix of the namespace without the trailing dot
+func (ns *Namespace) Prefix() string {
+	return ns.prefix
+}
+
+// UseOwnSources gives the namespace its own sources, so values are read
+// from sources added with ns.AddSource() and loaded with ns.Load(),
+// independent of the config it was created from
+// it must be called before anything is registered in the namespace
+func (ns *Namespace) UseOwnSources() *Namespace {
+	ns.mutex.Lock()
+	defer ns.mutex.Unlock()
+	if ns.registered {
+		panic(fmt.Sprintf("config namespace(%s).UseOwnSources() called after config was registered", ns.prefix))
+	}
+	ns.c = New()
+	return ns
+}
+
+// Config returns the config that the namespace uses
+func (ns *Namespace) Config() *Config {
+	ns.mutex.Lock()
+	defer ns.mutex.Unlock()
+	return ns.c
+}
+
+// MayConfigure is Config.MayConfigure() of prefix + "." + ref, or of prefix if ref is ""
+func (ns *Namespace) MayConfigure(ref string, tmpl interface{}) {
+	ns.register().MayConfigure(ns.ref(ref), tmpl)
+}
+
+// MustConfigure is Config.MustConfigure() of prefix + "." + ref, or of prefix if ref is ""
+func (ns *Namespace) MustConfigure(ref string, tmpl interface{}) {
+	ns.register().MustConfigure(ns.ref(ref), tmpl)
+}
+
+// Get is Config.Get() of prefix + "." + ref, or of prefix if ref is ""
+func (ns *Namespace) Get(ref string) any {
+	return ns.Config().Get(ns.ref(ref))
+}
+
+// AddSource adds a source to the config that the namespace uses
+// values are read with the prefix, like other config
+func (ns *Namespace) AddSource(name string, source Source, opts ...SourceOption) error {
+	return ns.Config().AddSource(name, source, opts...)
+}
+
+// Load loads the config that the namespace uses
+func (ns *Namespace) Load() error {
+	return ns.Config().Load()
+}
+
+func (ns *Namespace) register() *Config {
+	ns.mutex.Lock()
+	defer ns.mutex.Unlock()
+	ns.registered = true
+	return ns.c
+}
+
+func (ns *Namespace) ref(ref string) string {
+	if ref == "" {
+		return ns.prefix
+	}
+	return ns.prefix + "." + ref
+}
@@ -0,0 +1,58 @@
+package config
+
+import "testing"
+
+func TestNamespace(t *testing.T) {
+	reset(t)
+	ns := NewNamespace("mylib")
+	ns.MustConfigure("db", testServerConfig{})
+	ns.MayConfigure("name", "")
+	addTestSource(t, map[string]interface{}{"mylib": map[string]interface{}{
+		"db":   map[string]interface{}{"host": "db1", "port": 5432},
+		"name": "shop",
+	}})
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if db := ns.Get("db").(testServerConfig); db.Host != "db1" || db.Port != 5432 {
+		t.Fatalf("db %+v", db)
+	}
+	if name := Get("mylib.name"); name != "shop" || ns.Get("name") != name {
+		t.Fatalf("name %v", name)
+	}
+	if list := ListNamespaces(); len(list) != 1 || list[0] != ns || list[0].Prefix() != "mylib" {
+		t.Fatalf("namespaces %+v", list)
+	}
+}
+
+func TestNamespaceOwnSources(t *testing.T) {
+	reset(t)
+	ns := NewNamespace("mylib").UseOwnSources()
+	ns.MustConfigure("", testServerConfig{})
+	if err := ns.AddSource("own", &testSource{value: map[string]interface{}{
+		"mylib": map[string]interface{}{"host": "own", "port": 1},
+	}}); err != nil {
+		t.Fatalf("failed to add source: %+v", err)
+	}
+	MustConfigure("mylib.host", "")
+	addTestSource(t, map[string]interface{}{"mylib": map[string]interface{}{"host": "shared"}})
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if err := ns.Load(); err != nil {
+		t.Fatalf("failed to load namespace: %+v", err)
+	}
+	if cfg := ns.Get("").(testServerConfig); cfg.Host != "own" || cfg.Port != 1 {
+		t.Fatalf("namespace %+v", cfg)
+	}
+	if host := Get("mylib.host"); host != "shared" {
+		t.Fatalf("shared %v", host)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("UseOwnSources() after register did not panic")
+		}
+	}()
+	ns.UseOwnSources()
+}
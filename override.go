@@ -0,0 +1,175 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-msvc/data"
+	"github.com/go-msvc/errors"
+)
+
+// SetOverride sets a value that takes priority over all sources
+// e.g. in tests, or to change a value at runtime
+// name may be a whole config value like "ms.db" or a field in it like "ms.db.host"
+// if config is already loaded, it is reloaded so that Get() and Watch() see the override
+// and reload errors are passed to OnReloadError()
+// in tests, clear it when done:
+//
+//	config.SetOverride("ms.db.host", "localhost")
+//	t.Cleanup(func() { config.ClearOverride("ms.db.host") })
+func (c *Config) SetOverride(name string, value interface{}) {
+	if !validReference(name) {
+		panic(fmt.Sprintf("invalid config override(%s), expecting dot-notation reference", name))
+	}
+	name = c.resolveAlias(name)
+	c.overrideMutex.Lock()
+	c.overrideByName[name] = value
+	c.overrideMutex.Unlock()
+	c.reloadOverrides()
+}
+
+// ClearOverride removes an override set with SetOverride()
+func (c *Config) ClearOverride(name string) {
+	name = c.resolveAlias(name)
+	c.overrideMutex.Lock()
+	_, ok := c.overrideByName[name]
+	delete(c.overrideByName, name)
+	c.overrideMutex.Unlock()
+	if ok {
+		c.reloadOverrides()
+	}
+}
+
+// ClearAllOverrides removes all overrides set with SetOverride()
+func (c *Config) ClearAllOverrides() {
+	c.overrideMutex.Lock()
+	n := len(c.overrideByName)
+	c.overrideByName = map[string]interface{}{}
+	c.overrideMutex.Unlock()
+	if n > 0 {
+		c.reloadOverrides()
+	}
+}
+
+func (c *Config) reloadOverrides() {
+	c.configByRefMutex.RLock()
+	isLoaded := c.loaded
+	c.configByRefMutex.RUnlock()
+	if !isLoaded {
+		return
+	}
+	if err := c.Reload(); err != nil {
+		c.reloadErrorMutex.Lock()
+		fn := c.reloadErrorHandler
+		c.reloadErrorMutex.Unlock()
+		fn(errors.Wrapf(err, "failed to apply config override"))
+	}
+}
+
+// overrideSourceName is the name of the source in Dump(), TakeSnapshot() etc.
+// for values that were overridden
+const overrideSourceName = "override"
+
+// hasOverrides is true if SetOverride() was called for any name
+func (c *Config) hasOverrides() bool {
+	c.overrideMutex.RLock()
+	defer c.overrideMutex.RUnlock()
+	return len(c.overrideByName) > 0
+}
+
+// hasOverride is true if there is an override for name, its parent or its fields
+func (c *Config) hasOverride(name string) bool {
+	c.overrideMutex.RLock()
+	defer c.overrideMutex.RUnlock()
+	return hasOverrideFor(c.overrideByName, name)
+}
+
+func hasOverrideFor(overrideByName map[string]interface{}, name string) bool {
+	for n := range overrideByName {
+		if n == name || strings.HasPrefix(name, n+".") || strings.HasPrefix(n, name+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// overrideSource is used before all other sources when there are overrides
+// it applies the overrides to the value from the first other source that has it
+// and returns nil if name has no overrides
+type overrideSource struct {
+	c *Config
+}
+
+func (s overrideSource) GetInto(name string, tmpl interface{}) (interface{}, error) {
+	c := s.c
+	if !c.hasOverride(name) {
+		return nil, nil
+	}
+	c.overrideMutex.RLock()
+	overrideByName := make(map[string]interface{}, len(c.overrideByName))
+	for n, v := range c.overrideByName {
+		overrideByName[n] = v
+	}
+	c.overrideMutex.RUnlock()
+	return getWithOverrides(overrideByName, c.loaderSources(c.sourcesAndEnv()), name, tmpl)
+}
+
+// getWithOverrides applies the overrides to the value from the sources
+// it is used by overrideSource and for context overrides
+func getWithOverrides(overrideByName map[string]interface{}, sources []NamedSource, name string, tmpl interface{}) (interface{}, error) {
+	base, hasBase, err := readValue(sources, name, tmpl) //hasBase is false if no other source has the value
+	if err != nil {
+		return nil, err
+	}
+
+	parentName, parentValue := "", interface{}(nil)
+	fieldValueByName := map[string]interface{}{}
+	for n, v := range overrideByName {
+		if (n == name || strings.HasPrefix(name, n+".")) && len(n) > len(parentName) {
+			parentName, parentValue = n, v //nearest
+		}
+		if strings.HasPrefix(n, name+".") {
+			fieldValueByName[strings.TrimPrefix(n, name+".")] = v
+		}
+	}
+
+	//the whole value is overridden
+	if parentName != "" {
+		return getInto(parentValue, strings.TrimPrefix(strings.TrimPrefix(name, parentName), "."), tmpl)
+	}
+	if len(fieldValueByName) == 0 {
+		if !hasBase {
+			return nil, nil
+		}
+		return base.Value, nil
+	}
+
+	//fields are overridden, set them in a copy of the value from base
+	obj := map[string]interface{}{}
+	if hasBase {
+		baseValue, _, err := readValue(sources, name, map[string]interface{}{})
+		if err != nil {
+			return nil, err
+		}
+		if baseValue.Value != nil {
+			jsonValue, err := json.Marshal(baseValue.Value)
+			if err != nil {
+				return nil, errors.Wrapf(err, "cannot copy config(%s)", name)
+			}
+			if err := json.Unmarshal(jsonValue, &obj); err != nil {
+				return nil, errors.Wrapf(err, "config(%s) is not an object", name)
+			}
+		}
+	}
+	fieldNames := make([]string, 0, len(fieldValueByName))
+	for n := range fieldValueByName {
+		fieldNames = append(fieldNames, n)
+	}
+	sort.Strings(fieldNames) //parents before children
+	for _, n := range fieldNames {
+		setNested(obj, n, fieldValueByName[n])
+	}
+	return data.GetInto(obj, "", tmpl)
+} //getWithOverrides()
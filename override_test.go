@@ -0,0 +1,87 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestOverride(t *testing.T) {
+	reset(t)
+	MustConfigure("ms.db", testServerConfig{})
+	MustConfigure("ms.name", "")
+	if err := AddSource("file", File(writeTestFile(t, "config.json", `{"ms":{"db":{"host":"db1","port":5432},"name":"file"}}`))); err != nil {
+		t.Fatalf("failed to add source: %+v", err)
+	}
+	SetOverride("ms.name", "override") //before load
+	t.Cleanup(ClearAllOverrides)
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if Get("ms.name") != "override" || defaultConfig.sourceNameByRef["ms.name"] != "override" {
+		t.Fatalf("ms.name=%v from %s", Get("ms.name"), defaultConfig.sourceNameByRef["ms.name"])
+	}
+
+	//override a field after load: reloaded and watched
+	changed := make(chan interface{}, 1)
+	defer Watch("ms.db", func(v interface{}) { changed <- v })()
+	SetOverride("ms.db.host", "localhost")
+	if v := waitFor(t, changed); v != (testServerConfig{Host: "localhost", Port: 5432}) {
+		t.Fatalf("watcher got %+v", v)
+	}
+
+	//whole value overrides fields
+	SetOverride("ms.db", map[string]interface{}{"host": "other", "port": 1})
+	if v := Get("ms.db"); v != (testServerConfig{Host: "other", Port: 1}) {
+		t.Fatalf("ms.db=%+v", v)
+	}
+
+	//clear reverts to the file
+	ClearOverride("ms.db")
+	ClearOverride("ms.db.host")
+	if v := Get("ms.db"); v != (testServerConfig{Host: "db1", Port: 5432}) {
+		t.Fatalf("ms.db=%+v after clear", v)
+	}
+	ClearAllOverrides()
+	if Get("ms.name") != "file" {
+		t.Fatalf("ms.name=%v after clear all", Get("ms.name"))
+	}
+}
+
+func TestOverrideConstructed(t *testing.T) {
+	reset(t)
+	RegisterConstructor("hello", testGreeterConfig{})
+	MustConstruct("ms.greeter", testGreeterType)
+	addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{"greeter": map[string]interface{}{"hello": map[string]interface{}{"greeting": "hi"}}}})
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	SetOverride("ms.greeter.hello.greeting", "howzit")
+	if g := Get("ms.greeter").(testGreeter).Greet(); g != "howzit" {
+		t.Fatalf("greet=%s", g)
+	}
+}
+
+func TestOverrideWithoutSourceValue(t *testing.T) {
+	reset(t)
+	MustConfigure("ms.db", testServerConfig{Port: 80})
+	addTestSource(t, map[string]interface{}{})
+	SetOverride("ms.db.host", "localhost")
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if v := Get("ms.db"); v != (testServerConfig{Host: "localhost", Port: 80}) {
+		t.Fatalf("ms.db=%+v", v)
+	}
+}
+
+func TestOverrideNumber(t *testing.T) {
+	reset(t)
+	MustConfigure("ms.port", 0)
+	addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{"port": 1}})
+	SetOverride("ms.port", 8080.0) //e.g. a number parsed from JSON
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if v := Get("ms.port"); v != 8080 {
+		t.Fatalf("ms.port=%v", v)
+	}
+}
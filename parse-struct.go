@@ -0,0 +1,42 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/go-msvc/data"
+	"github.com/go-msvc/errors"
+)
+
+// ParseStruct converts values, e.g. from a source backend, into target,
+// which must be a pointer to a struct, with the same JSON rules as sources use
+// fields not in values keep their value in target, and pointer fields are
+// allocated when their key is in values
+// the struct is then validated like in Load(): the `validate:"..."` tags,
+// fields that implement Validate() and Validate() of target itself
+func ParseStruct(values map[string]interface{}, target interface{}) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return errors.Errorf("config.ParseStruct() needs a pointer to a struct, not %T", target)
+	}
+	jsonValue, err := json.Marshal(values)
+	if err != nil {
+		return errors.Wrapf(err, "cannot encode values for %T", target)
+	}
+	if err := json.Unmarshal(jsonValue, target); err != nil {
+		return errors.Wrapf(err, "cannot parse %T", target)
+	}
+	if err := validateStruct(target); err != nil {
+		return errors.Wrapf(err, "invalid %T", target)
+	}
+	validator, ok := target.(data.Validator)
+	if !ok {
+		validator, ok = v.Elem().Interface().(data.Validator)
+	}
+	if ok {
+		if err := validator.Validate(); err != nil {
+			return errors.Wrapf(err, "invalid %T", target)
+		}
+	}
+	return nil
+} //ParseStruct()
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-msvc/errors"
+)
+
+type testParseDB struct {
+	Host string `json:"host" validate:"required"`
+	Port int    `json:"port"`
+}
+
+type testParseConfig struct {
+	Name    string       `json:"name"`
+	Timeout int          `json:"timeout"`
+	DB      testParseDB  `json:"db"`
+	Cache   *testParseDB `json:"cache"`
+	Backup  *testParseDB `json:"backup"`
+}
+
+func (c testParseConfig) Validate() error {
+	if c.Name == "invalid" {
+		return errors.Errorf("invalid name")
+	}
+	return nil
+}
+
+func TestParseStruct(t *testing.T) {
+	cfg := testParseConfig{Timeout: 10}
+	if err := ParseStruct(map[string]interface{}{
+		"name":  "shop",
+		"db":    map[string]interface{}{"host": "db1", "port": 5432},
+		"cache": map[string]interface{}{"host": "cache1"},
+	}, &cfg); err != nil {
+		t.Fatalf("failed to parse: %+v", err)
+	}
+	if cfg.Name != "shop" || cfg.DB.Host != "db1" || cfg.DB.Port != 5432 {
+		t.Fatalf("parsed %+v", cfg)
+	}
+	if cfg.Cache == nil || cfg.Cache.Host != "cache1" || cfg.Backup != nil {
+		t.Fatalf("pointers %+v %+v", cfg.Cache, cfg.Backup)
+	}
+	if cfg.Timeout != 10 {
+		t.Fatalf("missing field changed to %d", cfg.Timeout)
+	}
+}
+
+func TestParseStructInvalid(t *testing.T) {
+	for _, test := range []struct {
+		values map[string]interface{}
+		err    string
+	}{
+		{map[string]interface{}{"name": "invalid", "db": map[string]interface{}{"host": "db1"}}, "invalid name"},
+		{map[string]interface{}{"name": "shop"}, "field(db.host)"},
+		{map[string]interface{}{"name": 1}, "cannot parse"},
+	} {
+		cfg := testParseConfig{}
+		if err := ParseStruct(test.values, &cfg); err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Fatalf("%v: wrong error: %v", test.values, err)
+		}
+	}
+	if err := ParseStruct(map[string]interface{}{}, testParseConfig{}); err == nil {
+		t.Fatalf("parsed into struct that is not a pointer")
+	}
+}
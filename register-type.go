@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/go-msvc/errors"
+)
+
+// RegisterType registers a constructor implementation for types where config
+// cannot be a struct with a Create() method, e.g. when the config struct is
+// from another library, so the factory gets the config as a map:
+//
+//	config.RegisterType("http", reflect.TypeOf((*Server)(nil)).Elem(), func(cfg map[string]interface{}) (interface{}, error) { ... })
+//
+// then MustConstruct() works the same as for RegisterConstructor() with config
+// {"http":{...}} where {...} is passed to factory
+// the value returned from factory must implement constructedType
+func (c *Config) RegisterType(typeName string, constructedType reflect.Type, factory func(cfg map[string]interface{}) (interface{}, error)) {
+	if c.loaded {
+		panic(fmt.Sprintf("config.RegisterType(%s) called after config.Load()", typeName))
+	}
+	if constructedType == nil || constructedType.Kind() != reflect.Interface {
+		panic(fmt.Sprintf("config.RegisterType(%s) constructed type %v is not an interface", typeName, constructedType))
+	}
+	if factory == nil {
+		panic(fmt.Sprintf("config.RegisterType(%s) called with nil factory", typeName))
+	}
+	info := c.constructorInfoFor(constructedType)
+	info.Lock()
+	defer info.Unlock()
+	if _, ok := info.tmplByName[typeName]; ok {
+		panic(fmt.Sprintf("%v constructor(name=\"%s\") is already registered!", constructedType, typeName))
+	}
+	info.tmplByName[typeName] = factoryConstructor{info: info, typeName: typeName, constructedType: constructedType}
+	info.factoryByName[typeName] = factory
+	Logger().Debugf("Registered %s factory(name=\"%s\")", constructedType, typeName)
+} //RegisterType()
+
+// factoryConstructor is the constructor config of types registered with RegisterType()
+// it does not store the factory, so that reload can compare it to keep unchanged items
+type factoryConstructor struct {
+	info            *constructorInfo
+	typeName        string
+	constructedType reflect.Type
+	cfg             map[string]interface{}
+}
+
+func (fc factoryConstructor) Create() (interface{}, error) {
+	fc.info.Lock()
+	factory := fc.info.factoryByName[fc.typeName]
+	fc.info.Unlock()
+	created, err := factory(fc.cfg)
+	if err != nil {
+		return nil, err
+	}
+	if created != nil && !reflect.TypeOf(created).Implements(fc.constructedType) {
+		return nil, errors.Errorf("factory(%s) created %T which is not a %v", fc.typeName, created, fc.constructedType)
+	}
+	return created, nil
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/go-msvc/errors"
+)
+
+// testFactoryGreeter is constructed by a factory from a map
+type testFactoryGreeter struct {
+	greeting string
+}
+
+func (g testFactoryGreeter) Greet() string { return g.greeting }
+
+func TestRegisterType(t *testing.T) {
+	reset(t)
+	creates := 0
+	RegisterType("factory", testGreeterType, func(cfg map[string]interface{}) (interface{}, error) {
+		creates++
+		greeting, ok := cfg["greeting"].(string)
+		if !ok {
+			return nil, errors.Errorf("missing greeting")
+		}
+		return testFactoryGreeter{greeting: greeting}, nil
+	})
+	RegisterConstructor("hello", testGreeterConfig{})
+	MustConstruct("ms.greeter", testGreeterType)
+	s := addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{"greeter": map[string]interface{}{"factory": map[string]interface{}{"greeting": "howdy"}}}})
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if g := Get("ms.greeter").(testGreeter).Greet(); g != "howdy" {
+		t.Fatalf("got %s", g)
+	}
+
+	//unchanged config is not constructed again
+	if err := Reload(); err != nil || creates != 1 {
+		t.Fatalf("reload: %v, %d creates", err, creates)
+	}
+
+	//change to the other implementation
+	s.set(map[string]interface{}{"ms": map[string]interface{}{"greeter": map[string]interface{}{"hello": map[string]interface{}{"greeting": "hi"}}}})
+	if err := Reload(); err != nil {
+		t.Fatalf("failed to reload: %+v", err)
+	}
+	if g := Get("ms.greeter").(testGreeter).Greet(); g != "hi" {
+		t.Fatalf("got %s", g)
+	}
+
+	//factory errors fail the reload
+	s.set(map[string]interface{}{"ms": map[string]interface{}{"greeter": map[string]interface{}{"factory": map[string]interface{}{}}}})
+	if err := Reload(); err == nil {
+		t.Fatalf("reloaded without greeting")
+	}
+}
+
+func TestRegisterTypeWrongType(t *testing.T) {
+	reset(t)
+	RegisterType("factory", testGreeterType, func(cfg map[string]interface{}) (interface{}, error) {
+		return "not a greeter", nil
+	})
+	MustConstruct("ms.greeter", testGreeterType)
+	addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{"greeter": map[string]interface{}{"factory": map[string]interface{}{}}}})
+	if err := Load(); err == nil {
+		t.Fatalf("loaded wrong type")
+	}
+}
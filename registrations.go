@@ -0,0 +1,60 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// Registration is one call to MustConfigure() or MayConfigure()
+// with the file and line it was called from, to find conflicting
+// registrations of the same ref in different packages
+type Registration struct {
+	Ref        string
+	Required   bool
+	Type       reflect.Type
+	CallerFile string
+	CallerLine int
+}
+
+// Registrations returns all calls to MustConfigure() and MayConfigure() in the order they were made
+func (c *Config) Registrations() []Registration {
+	c.registrationsMutex.Lock()
+	defer c.registrationsMutex.Unlock()
+	return append([]Registration{}, c.registrations...)
+}
+
+func (c *Config) addRegistration(ref string, required bool, t reflect.Type) {
+	file, line := externalCaller()
+	c.registrationsMutex.Lock()
+	defer c.registrationsMutex.Unlock()
+	c.registrations = append(c.registrations, Registration{
+		Ref:        ref,
+		Required:   required,
+		Type:       t,
+		CallerFile: file,
+		CallerLine: line,
+	})
+}
+
+// packageDir is the directory of this package's source files
+var packageDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+// externalCaller returns the first caller outside this package,
+// because the nr of frames differs between the package funcs and Config methods
+// tests of this package count as outside
+func externalCaller() (string, int) {
+	for skip := 2; ; skip++ {
+		_, file, line, ok := runtime.Caller(skip)
+		if !ok {
+			return "", 0
+		}
+		if filepath.Dir(file) != packageDir || strings.HasSuffix(file, "_test.go") {
+			return file, line
+		}
+	}
+}
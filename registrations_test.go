@@ -0,0 +1,39 @@
+package config
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestRegistrations(t *testing.T) {
+	reset(t)
+	w := captureLog(t)
+	MayConfigure("ms.server", testServerConfig{})
+	_, file, line, _ := runtime.Caller(0)
+	defaultConfig.MustConfigure("ms.server", testServerConfig{})
+	MayConfigure("ms.server", testServerConfig{})
+
+	if !defaultConfig.requiredConfigureByRef["ms.server"] {
+		t.Fatalf("not required after MustConfigure()")
+	}
+	found := 0
+	for _, m := range w.messages {
+		if strings.Contains(m, "config(ms.server) registered as optional is now required") {
+			found++
+		}
+	}
+	if found != 1 {
+		t.Fatalf("logged upgrade %d times: %v", found, w.messages)
+	}
+
+	expected := []Registration{
+		{Ref: "ms.server", Required: false, Type: reflect.TypeOf(testServerConfig{}), CallerFile: file, CallerLine: line - 1},
+		{Ref: "ms.server", Required: true, Type: reflect.TypeOf(testServerConfig{}), CallerFile: file, CallerLine: line + 1},
+		{Ref: "ms.server", Required: false, Type: reflect.TypeOf(testServerConfig{}), CallerFile: file, CallerLine: line + 2},
+	}
+	if r := Registrations(); !reflect.DeepEqual(r, expected) {
+		t.Fatalf("registrations:\n%+v\nexpected:\n%+v", r, expected)
+	}
+}
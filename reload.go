@@ -0,0 +1,139 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/go-msvc/errors"
+)
+
+// Reload reads all config from the sources again, the same way Load() did
+// items are only constructed again if their constructor config changed
+// if anything fails, the live config is not updated and the error is returned
+// so Get() keeps on returning the previously loaded values
+// on success, the Watch() callbacks are called for values that changed
+func (c *Config) Reload() error {
+	return c.ReloadContext(context.Background())
+}
+
+// ReloadContext is Reload() that stops when ctx is done
+// ctx is checked between reading values from the sources and before any
+// items are constructed, so it does not interrupt a source that is busy
+// if ctx is done before all was loaded, the live config is not updated
+// calls made while a reload is busy are coalesced: they wait for one more
+// reload after the busy one and all get its result, using the ctx of the first
+func (c *Config) ReloadContext(ctx context.Context) error {
+	c.reloadMutex.Lock()
+	if call := c.pendingReload; call != nil {
+		c.reloadMutex.Unlock()
+		select {
+		case <-call.done:
+			return call.err
+		case <-ctx.Done():
+			return errors.Wrapf(ctx.Err(), "failed to reload")
+		}
+	}
+	call := &reloadCall{done: make(chan struct{})}
+	c.pendingReload = call
+	c.reloadMutex.Unlock()
+
+	c.moduleDataMutex.Lock()
+	defer c.moduleDataMutex.Unlock()
+
+	//started, so later calls need another reload to see changes made after this point
+	c.reloadMutex.Lock()
+	c.pendingReload = nil
+	c.dirty = false
+	c.reloadMutex.Unlock()
+
+	call.err = c.reload(ctx)
+	close(call.done)
+	return call.err
+} //ReloadContext()
+
+type reloadCall struct {
+	done chan struct{}
+	err  error
+}
+
+// reload must be called with moduleDataMutex locked
+func (c *Config) reload(ctx context.Context) error {
+
+	if !c.loaded {
+		return errors.Errorf("config.Load() not yet called")
+	}
+
+	newConfig, err := c.load(ctx, true)
+	if err != nil {
+		c.notifyLoadError(err)
+		return errors.Wrapf(err, "failed to reload")
+	}
+
+	c.configByRefMutex.Lock()
+	oldConfigByRef := c.configByRef
+	newConfigByRef := newConfig.configByRef
+	c.configByRef = newConfigByRef
+	c.sourceNameByRef = newConfig.sourceNameByRef
+	c.constructorConfigByRef = newConfig.constructorConfigByRef
+	c.configByRefMutex.Unlock()
+	Logger().Debugf("Reloaded %d config values", len(newConfigByRef))
+
+	c.nextVersion(oldConfigByRef, newConfigByRef)
+	c.notifyWatchers(oldConfigByRef, newConfigByRef)
+	return nil
+} //reload()
+
+// OnReloadError sets the handler for errors from reloads that are
+// not called directly, e.g. from WatchSignal()
+// by default the error is just logged
+func (c *Config) OnReloadError(fn func(error)) {
+	c.reloadErrorMutex.Lock()
+	defer c.reloadErrorMutex.Unlock()
+	if fn == nil {
+		fn = logReloadError
+	}
+	c.reloadErrorHandler = fn
+}
+
+// WatchSignal calls Reload() each time one of the signals is received
+// if no signal is specified, it watches syscall.SIGHUP
+// call the returned func to stop watching
+func (c *Config) WatchSignal(sig ...os.Signal) func() {
+	if len(sig) == 0 {
+		sig = []os.Signal{syscall.SIGHUP}
+	}
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, sig...)
+	stopChan := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case s := <-signalChan:
+				Logger().Debugf("Reload on signal(%v)", s)
+				if err := c.Reload(); err != nil {
+					c.reloadErrorMutex.Lock()
+					fn := c.reloadErrorHandler
+					c.reloadErrorMutex.Unlock()
+					fn(err)
+				}
+			case <-stopChan:
+				return
+			}
+		}
+	}()
+
+	var stopOnce sync.Once
+	return func() {
+		stopOnce.Do(func() {
+			signal.Stop(signalChan)
+			close(stopChan)
+		})
+	}
+} //WatchSignal()
+
+func logReloadError(err error) {
+	Logger().Errorf("config reload failed: %+v", err)
+}
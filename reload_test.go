@@ -0,0 +1,257 @@
+package config
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type testGreeter interface {
+	Greet() string
+}
+
+type testGreeterConfig struct {
+	Greeting string `json:"greeting"`
+}
+
+var testGreeterCreates int32
+
+func (c testGreeterConfig) Create() (testGreeter, error) {
+	atomic.AddInt32(&testGreeterCreates, 1)
+	return &testGreeterImpl{greeting: c.Greeting}, nil
+}
+
+type testGreeterImpl struct {
+	greeting string
+}
+
+func (g *testGreeterImpl) Greet() string { return g.greeting }
+
+func loadTestGreeter(t *testing.T) *testSource {
+	t.Helper()
+	reset(t)
+	atomic.StoreInt32(&testGreeterCreates, 0)
+	RegisterConstructor("hello", testGreeterConfig{})
+	MustConstruct("ms.greeter", reflect.TypeOf((*testGreeter)(nil)).Elem())
+	s := addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{"greeter": map[string]interface{}{"hello": map[string]interface{}{"greeting": "hi"}}}})
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	return s
+}
+
+func TestReloadUnchangedKeepsConstructed(t *testing.T) {
+	loadTestGreeter(t)
+	g := Get("ms.greeter")
+	watched := int32(0)
+	defer Watch("ms.greeter", func(interface{}) { atomic.AddInt32(&watched, 1) })()
+
+	if err := Reload(); err != nil {
+		t.Fatalf("failed to reload: %+v", err)
+	}
+	if n := atomic.LoadInt32(&testGreeterCreates); n != 1 {
+		t.Fatalf("creates=%d, expected 1", n)
+	}
+	if Get("ms.greeter") != g {
+		t.Fatalf("constructed item replaced on reload with unchanged config")
+	}
+	if v, _ := VersionOf("ms.greeter"); v != 1 {
+		t.Fatalf("VersionOf=%d, expected 1", v)
+	}
+	if Version() != 2 {
+		t.Fatalf("Version=%d, expected 2", Version())
+	}
+	time.Sleep(10 * time.Millisecond)
+	if n := atomic.LoadInt32(&watched); n != 0 {
+		t.Fatalf("watcher called %d times for unchanged config", n)
+	}
+}
+
+func TestReloadChangedConstructs(t *testing.T) {
+	s := loadTestGreeter(t)
+	changed := make(chan interface{}, 1)
+	defer Watch("ms.greeter", func(v interface{}) { changed <- v })()
+
+	s.set(map[string]interface{}{"ms": map[string]interface{}{"greeter": map[string]interface{}{"hello": map[string]interface{}{"greeting": "howzit"}}}})
+	if err := Reload(); err != nil {
+		t.Fatalf("failed to reload: %+v", err)
+	}
+	if n := atomic.LoadInt32(&testGreeterCreates); n != 2 {
+		t.Fatalf("creates=%d, expected 2", n)
+	}
+	if v, _ := VersionOf("ms.greeter"); v != 2 {
+		t.Fatalf("VersionOf=%d, expected 2", v)
+	}
+	select {
+	case v := <-changed:
+		if v.(testGreeter).Greet() != "howzit" {
+			t.Fatalf("watcher got %v", v.(testGreeter).Greet())
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("watcher not called")
+	}
+}
+
+func TestReloadErrorKeepsLiveConfig(t *testing.T) {
+	s := loadTestGreeter(t)
+	s.set(map[string]interface{}{})
+	if err := Reload(); err == nil {
+		t.Fatalf("reload without required config did not fail")
+	}
+	if Get("ms.greeter").(testGreeter).Greet() != "hi" {
+		t.Fatalf("live config changed after failed reload")
+	}
+	if Version() != 1 {
+		t.Fatalf("Version=%d after failed reload, expected 1", Version())
+	}
+}
+
+func TestReloadBeforeLoad(t *testing.T) {
+	reset(t)
+	if err := Reload(); err == nil {
+		t.Fatalf("reload before load did not fail")
+	}
+}
+
+func TestWatchSignal(t *testing.T) {
+	s := loadTestGreeter(t)
+	s.set(map[string]interface{}{"ms": map[string]interface{}{"greeter": map[string]interface{}{"hello": map[string]interface{}{"greeting": "howzit"}}}})
+
+	reloaded := make(chan struct{})
+	OnVersion(2, func() { close(reloaded) })
+	stop := WatchSignal(syscall.SIGUSR1)
+	defer stop()
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatalf("failed to send signal: %+v", err)
+	}
+	select {
+	case <-reloaded:
+	case <-time.After(time.Second):
+		t.Fatalf("not reloaded on signal")
+	}
+	if Get("ms.greeter").(testGreeter).Greet() != "howzit" {
+		t.Fatalf("config not reloaded")
+	}
+	stop() //may be called more than once
+}
+
+func TestWatchSignalReloadError(t *testing.T) {
+	s := loadTestGreeter(t)
+	s.set(map[string]interface{}{})
+
+	failed := make(chan error, 1)
+	OnReloadError(func(err error) { failed <- err })
+	defer OnReloadError(nil)
+	defer WatchSignal(syscall.SIGUSR1)()
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatalf("failed to send signal: %+v", err)
+	}
+	select {
+	case <-failed:
+	case <-time.After(time.Second):
+		t.Fatalf("reload error handler not called")
+	}
+}
+
+// testSlowSource delays each GetInto()
+type testSlowSource struct {
+	testSource
+	delay time.Duration
+}
+
+func (s *testSlowSource) GetInto(name string, tmpl interface{}) (interface{}, error) {
+	time.Sleep(s.delay)
+	return s.testSource.GetInto(name, tmpl)
+}
+
+func TestReloadContext(t *testing.T) {
+	reset(t)
+	MustConfigure("ms.name", "")
+	MustConfigure("ms.port", 0)
+	s := &testSlowSource{testSource: testSource{value: map[string]interface{}{"ms": map[string]interface{}{"name": "a", "port": 1}}}}
+	if err := AddSource("slow", s); err != nil {
+		t.Fatalf("failed to add source: %+v", err)
+	}
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	s.set(map[string]interface{}{"ms": map[string]interface{}{"name": "b", "port": 2}})
+
+	//cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := ReloadContext(ctx); err == nil {
+		t.Fatalf("reload with cancelled context did not fail")
+	}
+
+	//timeout after the first value was read
+	s.delay = 50 * time.Millisecond
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := ReloadContext(ctx); err == nil {
+		t.Fatalf("reload did not time out")
+	}
+	if Get("ms.name") != "a" || Version() != 1 {
+		t.Fatalf("live config changed: ms.name=%v version=%d", Get("ms.name"), Version())
+	}
+
+	s.delay = 0
+	if err := ReloadContext(context.Background()); err != nil {
+		t.Fatalf("failed to reload: %+v", err)
+	}
+	if Get("ms.name") != "b" || Get("ms.port") != 2 {
+		t.Fatalf("ms.name=%v ms.port=%v", Get("ms.name"), Get("ms.port"))
+	}
+}
+
+// testSlowCountingSource delays and counts each GetInto()
+type testSlowCountingSource struct {
+	testCountingSource
+	delay time.Duration
+}
+
+func (s *testSlowCountingSource) GetInto(name string, tmpl interface{}) (interface{}, error) {
+	time.Sleep(s.delay)
+	return s.testCountingSource.GetInto(name, tmpl)
+}
+
+func TestReloadCoalesced(t *testing.T) {
+	reset(t)
+	MustConfigure("ms.name", "")
+	s := &testSlowCountingSource{testCountingSource: testCountingSource{testSource: testSource{value: map[string]interface{}{"ms": map[string]interface{}{"name": "a"}}}}}
+	if err := AddSource("counting", s); err != nil {
+		t.Fatalf("failed to add source: %+v", err)
+	}
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	s.delay = 50 * time.Millisecond
+	loadCalls := s.count()
+
+	//first reload is busy while the others are called
+	busy := make(chan error)
+	go func() { busy <- Reload() }()
+	time.Sleep(10 * time.Millisecond)
+	s.set(map[string]interface{}{"ms": map[string]interface{}{"name": "b"}})
+	errs := make(chan error)
+	for i := 0; i < 5; i++ {
+		go func() { errs <- Reload() }()
+	}
+	for i := 0; i < 5; i++ {
+		if err := <-errs; err != nil {
+			t.Fatalf("failed to reload: %+v", err)
+		}
+	}
+	if err := <-busy; err != nil {
+		t.Fatalf("failed to reload: %+v", err)
+	}
+	if n := s.count() - loadCalls; n != 2 {
+		t.Fatalf("%d reads instead of 2", n)
+	}
+	if Get("ms.name") != "b" {
+		t.Fatalf("ms.name=%v", Get("ms.name"))
+	}
+}
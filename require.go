@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/go-msvc/errors"
+)
+
+// Require is MustConfigure() for a simple value that returns a getter
+// so Load() fails if ref is not configured, e.g.:
+//
+//	var port = config.Require("http.port", 8080)
+//	...after config.Load()...
+//	p, err := port()
+//
+// it must be called before Load(), i.e. in init() or as package variable
+// defaultValue is also the template to parse the value into, so it cannot be nil
+func (c *Config) Require(ref string, defaultValue interface{}) func() (interface{}, error) {
+	if defaultValue == nil {
+		panic(fmt.Sprintf("config.Require(%s) cannot use default nil, it is the template for the value", ref))
+	}
+	c.MustConfigure(ref, defaultValue)
+	return func() (interface{}, error) {
+		return c.getLoaded(ref)
+	}
+}
+
+// Optional is MayConfigure() for a simple value that returns a getter
+// the getter returns defaultValue if ref is not configured
+// defaultValue is also the template to parse the value into, so it cannot be nil,
+// use a zero value, e.g. "" or 0, instead
+func (c *Config) Optional(ref string, defaultValue interface{}) func() (interface{}, error) {
+	if defaultValue == nil {
+		panic(fmt.Sprintf("config.Optional(%s) cannot use default nil, it is the template for the value", ref))
+	}
+	c.MayConfigure(ref, defaultValue)
+	return func() (interface{}, error) {
+		value, err := c.getLoaded(ref)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			return defaultValue, nil
+		}
+		return value, nil
+	}
+}
+
+// getLoaded is Get() with an error instead of panic
+func (c *Config) getLoaded(ref string) (interface{}, error) {
+	ref = c.resolveAlias(ref)
+	c.configByRefMutex.RLock()
+	defer c.configByRefMutex.RUnlock()
+	if !c.loaded {
+		return nil, errors.Errorf("config.Load() not yet called")
+	}
+	value, ok := c.configByRef[ref]
+	if !ok {
+		return nil, errors.Errorf("config(%s) not loaded", ref)
+	}
+	return value, nil
+}
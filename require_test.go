@@ -0,0 +1,47 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestRequireAndOptional(t *testing.T) {
+	reset(t)
+	port := Require("ms.port", 80)
+	name := Optional("ms.name", "default")
+	label := Optional("ms.label", "none")
+	if _, err := port(); err == nil {
+		t.Fatalf("got port before Load()")
+	}
+	addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{"port": 8080, "name": "test"}})
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if v, err := port(); err != nil || v != 8080 {
+		t.Fatalf("port=%v,%v", v, err)
+	}
+	if v, err := name(); err != nil || v != "test" {
+		t.Fatalf("name=%v,%v", v, err)
+	}
+	if v, err := label(); err != nil || v != "none" {
+		t.Fatalf("absent label=%v,%v", v, err)
+	}
+}
+
+func TestRequireAbsent(t *testing.T) {
+	reset(t)
+	Require("ms.port", 80)
+	addTestSource(t, map[string]interface{}{})
+	if err := Load(); err == nil {
+		t.Fatalf("loaded without required value")
+	}
+}
+
+func TestOptionalNilDefault(t *testing.T) {
+	reset(t)
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("Optional() with nil default did not panic")
+		}
+	}()
+	Optional("ms.name", nil)
+}
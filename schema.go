@@ -0,0 +1,232 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema returns a JSON Schema (draft-07) describing all the config
+// registered with MustConfigure(), MayConfigure(), MustConstruct() and MayConstruct()
+// struct types are described in "definitions", using these field tags:
+//
+//	json:"name"              property name (omitempty and pointer fields are optional)
+//	doc:"..."                description (doc:"-" hides the field)
+//	validate:"min=1,max=10"  minimum/maximum (minLength/maxLength for strings)
+//	validate:"regexp=..."    pattern
+//	validate:"oneof=a b c"   enum
+func (c *Config) Schema() map[string]interface{} {
+	c.moduleDataMutex.Lock()
+	defer c.moduleDataMutex.Unlock()
+
+	sb := schemaBuilder{defs: map[string]interface{}{}}
+	root := map[string]interface{}{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type":    "object",
+	}
+	for ref, tmpl := range c.mustConfigureByRef {
+		sb.setProperty(root, ref, sb.typeSchema(reflect.TypeOf(tmpl)), c.requiredConfigureByRef[ref])
+	}
+	for constructedType, info := range c.constructorsByType {
+		implNames := make([]string, 0, len(info.tmplByName))
+		for implName := range info.tmplByName {
+			implNames = append(implNames, implName)
+		}
+		sort.Strings(implNames)
+		for ref, required := range info.mustConstructByRef {
+			oneOf := []interface{}{}
+			for _, implName := range implNames {
+				oneOf = append(oneOf, map[string]interface{}{
+					"type":                 "object",
+					"properties":           map[string]interface{}{implName: sb.typeSchema(reflect.TypeOf(info.tmplByName[implName]))},
+					"required":             []string{implName},
+					"additionalProperties": false,
+				})
+			}
+			sb.setProperty(root, ref, map[string]interface{}{
+				"description": "one implementation of " + constructedType.String(),
+				"oneOf":       oneOf,
+			}, required)
+		}
+	}
+	if len(sb.defs) > 0 {
+		root["definitions"] = sb.defs
+	}
+	return root
+} //Schema()
+
+// SchemaJSON returns Schema() as indented JSON
+func (c *Config) SchemaJSON() ([]byte, error) {
+	return json.MarshalIndent(c.Schema(), "", "  ")
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+type schemaBuilder struct {
+	defs map[string]interface{}
+}
+
+// setProperty sets the schema of a dot-notation ref inside the object schema
+// creating nested object schemas for the parent names
+func (sb schemaBuilder) setProperty(obj map[string]interface{}, ref string, schema map[string]interface{}, required bool) {
+	names := strings.Split(ref, ".")
+	for i, name := range names {
+		properties, ok := obj["properties"].(map[string]interface{})
+		if !ok {
+			properties = map[string]interface{}{}
+			obj["properties"] = properties
+		}
+		if required {
+			list, _ := obj["required"].([]string)
+			found := false
+			for _, n := range list {
+				if n == name {
+					found = true
+					break
+				}
+			}
+			if !found {
+				list = append(list, name)
+				sort.Strings(list)
+				obj["required"] = list
+			}
+		}
+		if i == len(names)-1 {
+			properties[name] = schema
+			return
+		}
+		sub, ok := properties[name].(map[string]interface{})
+		if !ok {
+			sub = map[string]interface{}{"type": "object"}
+			properties[name] = sub
+		}
+		obj = sub
+	}
+} //schemaBuilder.setProperty()
+
+func (sb schemaBuilder) typeSchema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t {
+	case ipType:
+		return map[string]interface{}{"type": "string", "anyOf": []interface{}{
+			map[string]interface{}{"format": "ipv4"},
+			map[string]interface{}{"format": "ipv6"},
+		}}
+	case ipNetType:
+		return map[string]interface{}{"type": "string"}
+	case urlType:
+		return map[string]interface{}{"type": "string", "format": "uri"}
+	case envType:
+		return map[string]interface{}{"type": "string"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": sb.typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": sb.typeSchema(t.Elem())}
+	case reflect.Struct:
+		if t == timeType {
+			return map[string]interface{}{"type": "string", "format": "date-time"}
+		}
+		if t.Name() == "" {
+			return sb.structSchema(t) //anonymous struct
+		}
+		//full package path so that types with the same name in different packages do not collide
+		//JSON pointer escapes "~" and "/" in the $ref
+		name := t.PkgPath() + "." + t.Name()
+		if _, ok := sb.defs[name]; !ok {
+			sb.defs[name] = map[string]interface{}{} //placeholder for recursive types
+			sb.defs[name] = sb.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/definitions/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(name)}
+	default:
+		return map[string]interface{}{} //any value
+	}
+} //schemaBuilder.typeSchema()
+
+func (sb schemaBuilder) structSchema(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	required := []string{}
+	sb.addFields(t, properties, &required)
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	return schema
+}
+
+func (sb schemaBuilder) addFields(t reflect.Type, properties map[string]interface{}, required *[]string) {
+	iterFields(t, func(jsonName, docString string, f reflect.StructField) {
+		//copy the type schema so that it can be changed for this field
+		schema := map[string]interface{}{}
+		for n, v := range sb.typeSchema(f.Type) {
+			schema[n] = v
+		}
+		if docString != "" {
+			schema["description"] = docString
+		}
+		addValidateSchema(schema, FieldTag(f, "validate"))
+
+		_, omitEmpty := JSONName(f)
+		if f.Type.Kind() != reflect.Ptr && !omitEmpty {
+			*required = append(*required, jsonName)
+		}
+		properties[jsonName] = schema
+	})
+} //schemaBuilder.addFields()
+
+// addValidateSchema adds schema keywords for the `validate:"..."` tag constraints
+func addValidateSchema(schema map[string]interface{}, tag string) {
+	isString := schema["type"] == "string"
+	for _, c := range parseValidateTag(tag) {
+		name, arg := c.name, c.arg
+		switch name {
+		case "min", "max":
+			limit, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				continue
+			}
+			//like validate, min and max limit the length of strings, slices and maps
+			keyword := map[string]string{"min": "minimum", "max": "maximum"}[name]
+			switch schema["type"] {
+			case "string":
+				keyword = map[string]string{"min": "minLength", "max": "maxLength"}[name]
+			case "array":
+				keyword = map[string]string{"min": "minItems", "max": "maxItems"}[name]
+			case "object":
+				keyword = map[string]string{"min": "minProperties", "max": "maxProperties"}[name]
+			}
+			schema[keyword] = limit
+		case "regexp":
+			schema["pattern"] = arg
+		case "oneof":
+			enum := []interface{}{}
+			for _, option := range strings.Fields(arg) {
+				if n, err := strconv.ParseFloat(option, 64); err == nil && !isString {
+					enum = append(enum, n)
+				} else {
+					enum = append(enum, option)
+				}
+			}
+			schema["enum"] = enum
+		}
+	}
+} //addValidateSchema()
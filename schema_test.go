@@ -0,0 +1,95 @@
+package config
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Cookie has the same name as http.Cookie to test that definitions do not collide
+type Cookie struct {
+	Name string `json:"name"`
+}
+
+type testSchemaConfig struct {
+	Host    string            `json:"host" doc:"server host" validate:"required"`
+	Port    int               `json:"port" validate:"min=1,max=65535"`
+	Level   string            `json:"level,omitempty" validate:"oneof=debug info"`
+	Timeout *int              `json:"timeout"`
+	Started time.Time         `json:"started"`
+	Tags    []string          `json:"tags" validate:"min=1,max=5"`
+	Labels  map[string]string `json:"labels,omitempty" validate:"max=3"`
+	Cookie  Cookie            `json:"cookie"`
+	Other   http.Cookie       `json:"other"`
+	Ignored string            `json:"-"`
+	Next    *testSchemaConfig `json:"next"`
+}
+
+func TestSchema(t *testing.T) {
+	reset(t)
+	MustConfigure("ms.server", testSchemaConfig{})
+	MayConfigure("ms.name", "")
+	RegisterConstructor("hello", testGreeterConfig{})
+	MustConstruct("ms.greeter", testGreeterType)
+	schema := Schema()
+
+	if schema["$schema"] != "http://json-schema.org/draft-07/schema#" {
+		t.Fatalf("$schema=%v", schema["$schema"])
+	}
+	if _, ok := schema["$defs"]; ok {
+		t.Fatalf("draft-07 uses definitions, not $defs")
+	}
+	ms := schema["properties"].(map[string]interface{})["ms"].(map[string]interface{})
+	if !reflect.DeepEqual(ms["required"], []string{"greeter", "server"}) {
+		t.Fatalf("required=%+v", ms["required"])
+	}
+	msProperties := ms["properties"].(map[string]interface{})
+	if !reflect.DeepEqual(msProperties["name"], map[string]interface{}{"type": "string"}) {
+		t.Fatalf("name=%+v", msProperties["name"])
+	}
+	serverRef := "#/definitions/github.com~1go-msvc~1config.testSchemaConfig"
+	if !reflect.DeepEqual(msProperties["server"], map[string]interface{}{"$ref": serverRef}) {
+		t.Fatalf("server=%+v", msProperties["server"])
+	}
+	greeter := msProperties["greeter"].(map[string]interface{})
+	if greeter["description"] != "one implementation of config.testGreeter" || len(greeter["oneOf"].([]interface{})) != 1 {
+		t.Fatalf("greeter=%+v", greeter)
+	}
+
+	definitions := schema["definitions"].(map[string]interface{})
+	for _, name := range []string{
+		"github.com/go-msvc/config.testSchemaConfig",
+		"github.com/go-msvc/config.testGreeterConfig",
+		"github.com/go-msvc/config.Cookie",
+		"net/http.Cookie",
+	} {
+		if _, ok := definitions[name]; !ok {
+			t.Fatalf("missing definition %s in %+v", name, definitions)
+		}
+	}
+	server := definitions["github.com/go-msvc/config.testSchemaConfig"].(map[string]interface{})
+	if !reflect.DeepEqual(server["required"], []string{"cookie", "host", "other", "port", "started", "tags"}) {
+		t.Fatalf("required=%+v", server["required"])
+	}
+	properties := server["properties"].(map[string]interface{})
+	expected := map[string]interface{}{
+		"host":    map[string]interface{}{"type": "string", "description": "server host"},
+		"port":    map[string]interface{}{"type": "integer", "minimum": 1.0, "maximum": 65535.0},
+		"level":   map[string]interface{}{"type": "string", "enum": []interface{}{"debug", "info"}},
+		"timeout": map[string]interface{}{"type": "integer"},
+		"started": map[string]interface{}{"type": "string", "format": "date-time"},
+		"tags":    map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}, "minItems": 1.0, "maxItems": 5.0},
+		"labels":  map[string]interface{}{"type": "object", "additionalProperties": map[string]interface{}{"type": "string"}, "maxProperties": 3.0},
+		"cookie":  map[string]interface{}{"$ref": "#/definitions/github.com~1go-msvc~1config.Cookie"},
+		"other":   map[string]interface{}{"$ref": "#/definitions/net~1http.Cookie"},
+		"next":    map[string]interface{}{"$ref": serverRef},
+	}
+	if !reflect.DeepEqual(properties, expected) {
+		t.Fatalf("properties=%+v\nexpected=%+v", properties, expected)
+	}
+
+	if _, err := SchemaJSON(); err != nil {
+		t.Fatalf("failed to marshal: %+v", err)
+	}
+}
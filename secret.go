@@ -0,0 +1,86 @@
+package config
+
+import (
+	"reflect"
+)
+
+// IsSecretField is the same as SecretField()
+func IsSecretField(f reflect.StructField) bool {
+	return SecretField(f)
+}
+
+// MaskSecrets returns a deep copy of v with secret string fields set to "***"
+// and other secret fields set to their zero value, e.g. to log config with %+v
+// v itself is not modified
+func MaskSecrets(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	return maskValue(reflect.ValueOf(v)).Interface()
+}
+
+func maskValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type().Elem())
+		copied.Elem().Set(maskValue(v.Elem()))
+		return copied
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(maskValue(v.Elem()))
+		return copied
+	case reflect.Struct:
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(v) //also copies unexported fields
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			if SecretField(f) {
+				if f.Type.Kind() == reflect.String {
+					copied.Field(i).SetString("***")
+				} else {
+					copied.Field(i).Set(reflect.Zero(f.Type))
+				}
+				continue
+			}
+			copied.Field(i).Set(maskValue(v.Field(i)))
+		}
+		return copied
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(iter.Key(), maskValue(iter.Value()))
+		}
+		return copied
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(maskValue(v.Index(i)))
+		}
+		return copied
+	case reflect.Array:
+		copied := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(maskValue(v.Index(i)))
+		}
+		return copied
+	default:
+		return v
+	}
+} //maskValue()
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testSecretConfig struct {
+	User     string                  `json:"user"`
+	Password string                  `json:"password" secret:"true"`
+	Pin      int                     `json:"pin" secret:"true"`
+	DB       *testDBConfig           `json:"db"`
+	Keys     map[string]testDBConfig `json:"keys"`
+	List     []testDBConfig          `json:"list"`
+	Any      interface{}             `json:"any"`
+}
+
+func TestMaskSecrets(t *testing.T) {
+	c := testSecretConfig{
+		User:     "joe",
+		Password: "pass",
+		Pin:      1234,
+		DB:       &testDBConfig{Host: "db1", Password: "dbpass"},
+		Keys:     map[string]testDBConfig{"a": {Host: "a", Password: "apass"}},
+		List:     []testDBConfig{{Host: "l", Password: "lpass"}},
+		Any:      testDBConfig{Host: "any", Password: "anypass"},
+	}
+	original := fmt.Sprintf("%+v %+v", c, *c.DB)
+	masked := MaskSecrets(c).(testSecretConfig)
+	expected := testSecretConfig{
+		User:     "joe",
+		Password: "***",
+		DB:       &testDBConfig{Host: "db1", Password: "***"},
+		Keys:     map[string]testDBConfig{"a": {Host: "a", Password: "***"}},
+		List:     []testDBConfig{{Host: "l", Password: "***"}},
+		Any:      testDBConfig{Host: "any", Password: "***"},
+	}
+	if !reflect.DeepEqual(masked, expected) {
+		t.Fatalf("masked=%+v", masked)
+	}
+	if s := fmt.Sprintf("%+v %+v", c, *c.DB); s != original {
+		t.Fatalf("original changed from %s to %s", original, s)
+	}
+	if MaskSecrets(nil) != nil {
+		t.Fatalf("nil not masked as nil")
+	}
+	if p := MaskSecrets(&c).(*testSecretConfig); p == &c || p.Password != "***" || c.Password != "pass" {
+		t.Fatalf("pointer masked=%+v", p)
+	}
+}
+
+func TestSecretsInLoadedConfig(t *testing.T) {
+	reset(t)
+	MustConfigure("ms.db", testDBConfig{})
+	addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{"db": map[string]interface{}{"host": "db1", "password": "pass1"}}})
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if dump, _ := DumpJSON(); strings.Contains(string(dump), "pass1") {
+		t.Fatalf("secret in dump: %s", dump)
+	}
+	if c := Get("ms.db").(testDBConfig); c.Password != "pass1" {
+		t.Fatalf("live value changed: %+v", c)
+	}
+}
+
+func TestIsSecretField(t *testing.T) {
+	typ := reflect.TypeOf(testSecretConfig{})
+	for name, expected := range map[string]bool{"User": false, "Password": true, "Pin": true} {
+		f, _ := typ.FieldByName(name)
+		if IsSecretField(f) != expected {
+			t.Fatalf("IsSecretField(%s)=%v", name, !expected)
+		}
+	}
+}
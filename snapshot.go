@@ -0,0 +1,145 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/go-msvc/errors"
+)
+
+// Snapshot is a copy of all loaded config values with the name of the
+// source that provided each value, for auditing
+// e.g. take a snapshot before and after Reload() and log the Diff()
+// struct fields tagged with `secret:"true"` are stored as "***"
+type Snapshot struct {
+	time    time.Time
+	version uint64
+	values  map[string]snapshotValue
+}
+
+type snapshotValue struct {
+	Value  interface{} `json:"value"`
+	Source string      `json:"source,omitempty"`
+	raw    interface{} //value with secrets, only used in Diff()
+}
+
+// TakeSnapshot takes a snapshot of all loaded config values
+// (it cannot be called Snapshot() because that is the name of the type)
+func (c *Config) TakeSnapshot() (Snapshot, error) {
+	c.configByRefMutex.RLock()
+	defer c.configByRefMutex.RUnlock()
+	if !c.loaded {
+		return Snapshot{}, errors.Errorf("config.Load() not yet called")
+	}
+	s := Snapshot{
+		time:    time.Now(),
+		version: c.Version(),
+		values:  map[string]snapshotValue{},
+	}
+	for ref, value := range c.configByRef {
+		raw := dumpRaw(reflect.ValueOf(value))
+		s.values[ref] = snapshotValue{
+			Value:  redact(raw),
+			Source: c.sourceNameByRef[ref],
+			raw:    raw,
+		}
+	}
+	return s, nil
+} //TakeSnapshot()
+
+func (s Snapshot) Time() time.Time { return s.time }
+
+func (s Snapshot) Version() uint64 { return s.version }
+
+// Refs returns the sorted list of config references in the snapshot
+func (s Snapshot) Refs() []string {
+	refs := make([]string, 0, len(s.values))
+	for ref := range s.values {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+	return refs
+}
+
+// Value returns the (redacted) value of ref and the name of the source it came from
+func (s Snapshot) Value(ref string) (value interface{}, sourceName string, ok bool) {
+	v, ok := s.values[ref]
+	return v.Value, v.Source, ok
+}
+
+func (s Snapshot) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"time":    s.time,
+		"version": s.version,
+		"values":  s.values,
+	})
+}
+
+type ChangeType string
+
+const (
+	ChangeAdded    ChangeType = "added"
+	ChangeModified ChangeType = "modified"
+	ChangeDeleted  ChangeType = "deleted"
+)
+
+// Change describes a value that differs between two snapshots
+// Key is the dot-notation reference to the value that changed,
+// which may be a field inside a configured struct, e.g. "ms.db.port"
+// Source is the source of the new value, or of the old value when deleted
+type Change struct {
+	Key        string      `json:"key"`
+	ChangeType ChangeType  `json:"change_type"`
+	OldValue   interface{} `json:"old_value,omitempty"`
+	NewValue   interface{} `json:"new_value,omitempty"`
+	Source     string      `json:"source,omitempty"`
+}
+
+// Diff returns the changes from snapshot a to snapshot b, sorted by key
+// secret values are compared, so a changed secret is reported, but as "***"
+func Diff(a, b Snapshot) []Change {
+	changes := []Change{}
+	for ref, av := range a.values {
+		if bv, ok := b.values[ref]; ok {
+			changes = diffValues(changes, ref, av.raw, bv.raw, bv.Source)
+		} else {
+			changes = append(changes, Change{Key: ref, ChangeType: ChangeDeleted, OldValue: av.Value, Source: av.Source})
+		}
+	}
+	for ref, bv := range b.values {
+		if _, ok := a.values[ref]; !ok {
+			changes = append(changes, Change{Key: ref, ChangeType: ChangeAdded, NewValue: bv.Value, Source: bv.Source})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+} //Diff()
+
+// diffValues appends the changes between two raw values
+// going into objects to report the fields that changed
+// and redacting secrets in the changes
+func diffValues(changes []Change, key string, oldValue, newValue interface{}, sourceName string) []Change {
+	if reflect.DeepEqual(oldValue, newValue) {
+		return changes
+	}
+	oldObj, oldIsObj := oldValue.(map[string]interface{})
+	newObj, newIsObj := newValue.(map[string]interface{})
+	if !oldIsObj || !newIsObj {
+		return append(changes, Change{Key: key, ChangeType: ChangeModified, OldValue: redact(oldValue), NewValue: redact(newValue), Source: sourceName})
+	}
+	for name, ov := range oldObj {
+		if nv, ok := newObj[name]; ok {
+			changes = diffValues(changes, key+"."+name, ov, nv, sourceName)
+		} else {
+			changes = append(changes, Change{Key: key + "." + name, ChangeType: ChangeDeleted, OldValue: redact(ov), Source: sourceName})
+		}
+	}
+	for name, nv := range newObj {
+		if _, ok := oldObj[name]; !ok {
+			changes = append(changes, Change{Key: key + "." + name, ChangeType: ChangeAdded, NewValue: redact(nv), Source: sourceName})
+		}
+	}
+	return changes
+} //diffValues()
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func takeTestSnapshot(t *testing.T) Snapshot {
+	t.Helper()
+	s, err := TakeSnapshot()
+	if err != nil {
+		t.Fatalf("failed to take snapshot: %+v", err)
+	}
+	return s
+}
+
+func TestSnapshotDiff(t *testing.T) {
+	reset(t)
+	MustConfigure("ms.db", testDBConfig{})
+	MayConfigure("ms.name", "")
+	MayConfigure("ms.label", "")
+	s := addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{
+		"db":   map[string]interface{}{"host": "db1", "password": "pass1"},
+		"name": "a",
+	}})
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	a := takeTestSnapshot(t)
+	if a.Version() != 1 || a.Time().IsZero() {
+		t.Fatalf("version=%d time=%v", a.Version(), a.Time())
+	}
+	if !reflect.DeepEqual(a.Refs(), []string{"ms.db", "ms.label", "ms.name"}) {
+		t.Fatalf("refs=%+v", a.Refs())
+	}
+	if v, sourceName, ok := a.Value("ms.name"); !ok || v != "a" || sourceName != "test" {
+		t.Fatalf("ms.name=%v,%s,%v", v, sourceName, ok)
+	}
+	if v, _, _ := a.Value("ms.db"); v.(map[string]interface{})["password"] != "***" {
+		t.Fatalf("secret not redacted: %+v", v)
+	}
+
+	//no changes
+	if changes := Diff(a, a); len(changes) != 0 {
+		t.Fatalf("changes=%+v", changes)
+	}
+
+	//nested field, secret, added and deleted values
+	s.set(map[string]interface{}{"ms": map[string]interface{}{
+		"db":    map[string]interface{}{"host": "db2", "password": "pass2"},
+		"label": "x",
+	}})
+	if err := Reload(); err != nil {
+		t.Fatalf("failed to reload: %+v", err)
+	}
+	b := takeTestSnapshot(t)
+	expected := []Change{
+		{Key: "ms.db.host", ChangeType: ChangeModified, OldValue: "db1", NewValue: "db2", Source: "test"},
+		{Key: "ms.db.password", ChangeType: ChangeModified, OldValue: "***", NewValue: "***", Source: "test"},
+		{Key: "ms.label", ChangeType: ChangeModified, OldValue: nil, NewValue: "x", Source: "test"},
+		{Key: "ms.name", ChangeType: ChangeModified, OldValue: "a", NewValue: nil, Source: ""},
+	}
+	if changes := Diff(a, b); !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("changes=%+v\nexpected=%+v", changes, expected)
+	}
+
+	if _, err := json.Marshal(b); err != nil {
+		t.Fatalf("failed to marshal: %+v", err)
+	}
+}
+
+func TestDiffAddedAndDeletedRefs(t *testing.T) {
+	a := Snapshot{values: map[string]snapshotValue{
+		"ms.old": {Value: "***", Source: "a", raw: secretValue{value: "x"}},
+	}}
+	b := Snapshot{values: map[string]snapshotValue{
+		"ms.new": {Value: map[string]interface{}{"k": "***"}, Source: "b", raw: map[string]interface{}{"k": secretValue{value: "y"}}},
+	}}
+	expected := []Change{
+		{Key: "ms.new", ChangeType: ChangeAdded, NewValue: map[string]interface{}{"k": "***"}, Source: "b"},
+		{Key: "ms.old", ChangeType: ChangeDeleted, OldValue: "***", Source: "a"},
+	}
+	if changes := Diff(a, b); !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("changes=%+v", changes)
+	}
+}
+
+func TestSnapshotNotLoaded(t *testing.T) {
+	reset(t)
+	if _, err := TakeSnapshot(); err == nil {
+		t.Fatalf("snapshot before Load() did not fail")
+	}
+}
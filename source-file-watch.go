@@ -0,0 +1,160 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-msvc/errors"
+)
+
+// fileWatchDelay is how long the watcher waits after a change before it reads
+// the file, so that a burst of writes is read once
+const fileWatchDelay = 200 * time.Millisecond
+
+// WithWatch makes File() watch the file and read it again after it was written,
+// replaced or removed, then reload the config so that Watch() callbacks are called
+// a removed file has no values until it is written again
+// watching stops when ctx is done or when Close() is called on the source
+func WithWatch(ctx context.Context) FileOption {
+	if ctx == nil {
+		panic("config.WithWatch(nil)")
+	}
+	return func(o *fileOptions) {
+		o.watchCtx = ctx
+	}
+}
+
+// WithReloadConfig makes the file from File() with WithWatch() reload c
+// instead of the default config when it changed
+func WithReloadConfig(c *Config) FileOption {
+	if c == nil {
+		panic("config.WithReloadConfig(nil)")
+	}
+	return func(o *fileOptions) {
+		o.reloadConfig = c
+	}
+}
+
+// newWatchedFile watches the directory of filename, because editors and
+// deployments often replace a file with a rename, which a watch on the file misses
+func newWatchedFile(filename string, data map[string]interface{}, options fileOptions, opts []FileOption) (*watchedFile, error) {
+	c := options.reloadConfig
+	if c == nil {
+		c = Default()
+	}
+	f := &watchedFile{
+		filename: filename,
+		opts:     opts,
+		reload: func() error {
+			if c.Version() == 0 {
+				return nil //not yet loaded
+			}
+			return c.Reload()
+		},
+		data: data,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	var err error
+	if f.watcher, err = fsnotify.NewWatcher(); err != nil {
+		return nil, errors.Wrapf(err, "cannot watch file %s", filename)
+	}
+	if err := f.watcher.Add(filepath.Dir(filename)); err != nil {
+		f.watcher.Close()
+		return nil, errors.Wrapf(err, "cannot watch file %s", filename)
+	}
+	go f.watch(options.watchCtx)
+	return f, nil
+} //newWatchedFile()
+
+// watchedFile implements Source for File() with WithWatch()
+type watchedFile struct {
+	filename  string
+	opts      []FileOption
+	reload    func() error
+	watcher   *fsnotify.Watcher
+	mutex     sync.RWMutex
+	data      map[string]interface{}
+	stop      chan struct{}
+	done      chan struct{} //closed when watching stopped
+	closeOnce sync.Once
+}
+
+func (f *watchedFile) GetInto(name string, tmpl interface{}) (interface{}, error) {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	return getInto(f.data, name, tmpl)
+}
+
+// Keys returns the top-level names in the file
+func (f *watchedFile) Keys() []string {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	return file{data: f.data}.Keys()
+}
+
+// Close stops watching
+func (f *watchedFile) Close() error {
+	f.closeOnce.Do(func() {
+		close(f.stop)
+	})
+	<-f.done
+	return nil
+}
+
+func (f *watchedFile) watch(ctx context.Context) {
+	defer close(f.done)
+	defer f.watcher.Close()
+	var delay <-chan time.Time
+	for {
+		select {
+		case event, ok := <-f.watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Base(event.Name) != filepath.Base(f.filename) ||
+				event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename|fsnotify.Remove) == 0 {
+				continue
+			}
+			delay = time.After(fileWatchDelay)
+		case err, ok := <-f.watcher.Errors:
+			if !ok {
+				return
+			}
+			Logger().Errorf("cannot watch file %s: %+v", f.filename, err)
+		case <-delay:
+			delay = nil
+			if err := f.refresh(); err != nil {
+				Logger().Errorf("%+v", err)
+				continue
+			}
+			if err := f.reload(); err != nil {
+				Logger().Errorf("failed to reload after file %s changed: %+v", f.filename, err)
+			}
+		case <-ctx.Done():
+			return
+		case <-f.stop:
+			return
+		}
+	}
+} //watchedFile.watch()
+
+// refresh reads the file again, or clears the values when it was removed
+// the values are kept when the file cannot be read
+func (f *watchedFile) refresh() error {
+	data := map[string]interface{}{}
+	if _, err := os.Stat(f.filename); !os.IsNotExist(err) {
+		if data, err = readFile(f.filename, f.opts...); err != nil {
+			return err
+		}
+	}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.data = data
+	Logger().Debugf("Read file %s again", f.filename)
+	return nil
+}
@@ -1,22 +1,36 @@
 package config
 
 import (
+	"bytes"
+	"context"
+	"crypto/aes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 
-	"github.com/go-msvc/data"
+	"github.com/go-msvc/errors"
+	"gopkg.in/yaml.v3"
 )
 
-func File(filename string) Source {
-	f, err := os.Open(filename)
+// File reads config from a JSON or YAML file
+// files with extension .yaml or .yml are parsed as YAML,
+// extensions registered with RegisterDecoder() with that decoder, others as JSON
+// with WithWatch() the file is read again when it changed
+func File(filename string, opts ...FileOption) Source {
+	data, err := readFile(filename, opts...)
 	if err != nil {
-		panic(fmt.Sprintf("cannot open config file %s: %+v", filename, err))
+		panic(fmt.Sprintf("cannot read config file %s: %+v", filename, err))
 	}
-	defer f.Close()
-	var data map[string]interface{}
-	if err := json.NewDecoder(f).Decode(&data); err != nil {
-		panic(fmt.Sprintf("cannot read JSON object from file %s: %+v", filename, err))
+	if options := fileOptionsOf(opts); options.watchCtx != nil {
+		s, err := newWatchedFile(filename, data, options, opts)
+		if err != nil {
+			panic(fmt.Sprintf("cannot watch config file %s: %+v", filename, err))
+		}
+		return s
 	}
 	return file{
 		data: data,
@@ -28,5 +42,140 @@ type file struct {
 }
 
 func (f file) GetInto(name string, tmpl interface{}) (interface{}, error) {
-	return data.GetInto(f.data, name, tmpl)
+	return getInto(f.data, name, tmpl)
+}
+
+// Keys returns the top-level names in the file
+func (f file) Keys() []string {
+	keys := make([]string, 0, len(f.data))
+	for key := range f.data {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// FileOption changes how File() reads the file
+type FileOption func(*fileOptions)
+
+type fileOptions struct {
+	decryptionKey []byte
+	watchCtx      context.Context //nil unless WithWatch()
+	reloadConfig  *Config         //reloaded by the watcher, nil for the default config
+}
+
+func fileOptionsOf(opts []FileOption) fileOptions {
+	options := fileOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// WithDecryption decrypts file content written by EncryptFileContent() with the
+// AES key of 16, 24 or 32 bytes before it is parsed
+// files that are not base64 are still read as plain text
+func WithDecryption(key []byte) FileOption {
+	if _, err := aes.NewCipher(key); err != nil {
+		panic(errors.Wrapf(err, "invalid config file decryption key"))
+	}
+	key = append([]byte{}, key...)
+	return func(o *fileOptions) {
+		o.decryptionKey = key
+	}
+}
+
+// readFile reads an object from a file with the decoder registered for its extension
+// files with other extensions are read as JSON
+func readFile(filename string, opts ...FileOption) (map[string]interface{}, error) {
+	options := fileOptionsOf(opts)
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read file %s", filename)
+	}
+	if options.decryptionKey != nil {
+		if content, err = decryptFileContent(content, options.decryptionKey); err != nil {
+			return nil, errors.Wrapf(err, "cannot decrypt file %s", filename)
+		}
+	}
+	return decodeFile(filename, content)
+} //readFile()
+
+// decodeFile decodes the content of a file with the decoder registered for its extension
+// files with other extensions are decoded as JSON
+func decodeFile(filename string, content []byte) (map[string]interface{}, error) {
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), ".")
+	decoder, ok := LookupDecoder(ext)
+	if !ok {
+		ext = "json"
+		decoder, _ = LookupDecoder(ext)
+	}
+	data, err := decoder(bytes.NewReader(content))
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read %s object from file %s", strings.ToUpper(ext), filename)
+	}
+	return data, nil
+} //decodeFile()
+
+// RegisterDecoder registers the decoder for files with the extension, e.g. "toml"
+// so that File() and AddFileSource() can read them
+// decoders for "json", "yaml" and "yml" are registered by default
+func RegisterDecoder(ext string, fn func(r io.Reader) (map[string]interface{}, error)) {
+	ext = strings.ToLower(ext)
+	if ext == "" || strings.HasPrefix(ext, ".") {
+		panic(fmt.Sprintf("config.RegisterDecoder(%s) expects an extension without the dot", ext))
+	}
+	if fn == nil {
+		panic(fmt.Sprintf("config.RegisterDecoder(%s) called with nil func", ext))
+	}
+	decoderMutex.Lock()
+	defer decoderMutex.Unlock()
+	if _, ok := decoderByExt[ext]; ok {
+		panic(fmt.Sprintf("config decoder(%s) is already registered", ext))
+	}
+	decoderByExt[ext] = fn
+}
+
+// LookupDecoder returns the decoder registered for the extension without the dot
+func LookupDecoder(ext string) (func(r io.Reader) (map[string]interface{}, error), bool) {
+	decoderMutex.Lock()
+	defer decoderMutex.Unlock()
+	fn, ok := decoderByExt[strings.ToLower(ext)]
+	return fn, ok
+}
+
+func init() {
+	RegisterDecoder("json", decodeJSON)
+	RegisterDecoder("yaml", decodeYAML)
+	RegisterDecoder("yml", decodeYAML)
+}
+
+func decodeJSON(r io.Reader) (map[string]interface{}, error) {
+	var data map[string]interface{}
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func decodeYAML(r io.Reader) (map[string]interface{}, error) {
+	var data map[string]interface{}
+	if err := yaml.NewDecoder(r).Decode(&data); err != nil && err != io.EOF { //EOF for empty file
+		return nil, err
+	}
+	return data, nil
+}
+
+var (
+	decoderMutex sync.Mutex
+	decoderByExt = map[string]func(r io.Reader) (map[string]interface{}, error){}
+)
+
+// findFile returns the first of "<name>.json", "<name>.yaml" and "<name>.yml" that exists
+func findFile(name string) (string, error) {
+	for _, ext := range []string{".json", ".yaml", ".yml"} {
+		if _, err := os.Stat(name + ext); err == nil {
+			return name + ext, nil
+		}
+	}
+	return "", errors.Errorf("no file %s.json, %s.yaml or %s.yml", name, name, name)
 }
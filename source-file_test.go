@@ -0,0 +1,185 @@
+package config
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(filename, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write %s: %+v", filename, err)
+	}
+	return filename
+}
+
+func TestFileJSON(t *testing.T) {
+	s := File(writeTestFile(t, "config.json", `{"section":{"key":"value","port":8080,"empty":null}}`))
+	if v, err := s.GetInto("section.key", ""); err != nil || v != "value" {
+		t.Fatalf("got %v,%v", v, err)
+	}
+	if v, err := s.GetInto("section.port", 0); err != nil || v != 8080 {
+		t.Fatalf("got %v,%v", v, err)
+	}
+	if v, err := s.GetInto("section.empty", ""); err != nil || v != nil {
+		t.Fatalf("null got %#v,%v", v, err)
+	}
+}
+
+func TestFileYAML(t *testing.T) {
+	for _, name := range []string{"config.yaml", "config.yml"} {
+		s := File(writeTestFile(t, name, "section:\n  key: value\n  port: 8080\n  empty: null\n  server:\n    host: localhost\n"))
+		if v, err := s.GetInto("section.key", ""); err != nil || v != "value" {
+			t.Fatalf("%s got %v,%v", name, v, err)
+		}
+		if v, err := s.GetInto("section.port", 0); err != nil || v != 8080 {
+			t.Fatalf("%s got %v,%v", name, v, err)
+		}
+		if v, err := s.GetInto("section.server", testServerConfig{}); err != nil || v != (testServerConfig{Host: "localhost"}) {
+			t.Fatalf("%s got %v,%v", name, v, err)
+		}
+		if v, err := s.GetInto("section.empty", ""); err != nil || v != nil {
+			t.Fatalf("%s null got %#v,%v", name, v, err)
+		}
+		if v, err := s.GetInto("section.missing", ""); err != nil || v != nil {
+			t.Fatalf("%s missing got %#v,%v", name, v, err)
+		}
+	}
+}
+
+func TestFindFile(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "config")
+	if _, err := findFile(name); err == nil {
+		t.Fatalf("found file in empty dir")
+	}
+	for _, ext := range []string{".yml", ".yaml", ".json"} {
+		if err := os.WriteFile(name+ext, []byte("{}"), 0600); err != nil {
+			t.Fatalf("failed to write: %+v", err)
+		}
+		if fn, err := findFile(name); err != nil || fn != name+ext {
+			t.Fatalf("got %s,%v after writing %s", fn, err, ext)
+		}
+	}
+}
+
+func TestFileInvalid(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("invalid file did not panic")
+		}
+	}()
+	File(writeTestFile(t, "config.yaml", "- not\n- an object\n"))
+}
+
+func TestRegisterDecoder(t *testing.T) {
+	//decodes lines of name=value
+	RegisterDecoder("kv", func(r io.Reader) (map[string]interface{}, error) {
+		content, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		obj := map[string]interface{}{}
+		for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+			name, value, _ := strings.Cut(line, "=")
+			setNested(obj, name, value)
+		}
+		return obj, nil
+	})
+	t.Cleanup(func() {
+		decoderMutex.Lock()
+		delete(decoderByExt, "kv")
+		decoderMutex.Unlock()
+	})
+	if _, ok := LookupDecoder("kv"); !ok {
+		t.Fatalf("decoder not registered")
+	}
+	if _, ok := LookupDecoder("toml"); ok {
+		t.Fatalf("unexpected toml decoder")
+	}
+
+	s := File(writeTestFile(t, "config.kv", "ms.name=kv\nms.code=x\n"))
+	if value, err := s.GetInto("ms.name", ""); err != nil || value != "kv" {
+		t.Fatalf("got %v,%v", value, err)
+	}
+
+	reset(t)
+	if _, err := AddFileSource(writeTestFile(t, "config.kv", "ms.name=kv\n")); err != nil {
+		t.Fatalf("failed to add kv file: %+v", err)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("registered json decoder twice")
+		}
+	}()
+	RegisterDecoder("json", decodeJSON)
+}
+
+func TestFileWithDecryption(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	content, err := EncryptFileContent([]byte(`{"section":{"key":"secret"}}`), key)
+	if err != nil {
+		t.Fatalf("failed to encrypt: %+v", err)
+	}
+	filename := writeTestFile(t, "config.json", string(content))
+
+	s := File(filename, WithDecryption(key))
+	if v, err := s.GetInto("section.key", ""); err != nil || v != "secret" {
+		t.Fatalf("got %v,%v", v, err)
+	}
+	if keys := s.(ListableSource).Keys(); len(keys) != 1 || keys[0] != "section" {
+		t.Fatalf("keys %v", keys)
+	}
+
+	//wrong key
+	if _, err := readFile(filename, WithDecryption([]byte("fedcba9876543210fedcba9876543210"))); err == nil {
+		t.Fatalf("decrypted with wrong key")
+	}
+
+	//plain file is still read
+	s = File(writeTestFile(t, "plain.json", `{"section":{"key":"plain"}}`), WithDecryption(key))
+	if v, err := s.GetInto("section.key", ""); err != nil || v != "plain" {
+		t.Fatalf("got %v,%v", v, err)
+	}
+}
+
+func TestFileWithWatch(t *testing.T) {
+	filename := writeTestFile(t, "config.json", `{"ms":{"name":"a"}}`)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := New()
+	c.MustConfigure("ms.name", "")
+	s := File(filename, WithWatch(ctx), WithReloadConfig(c))
+	defer s.(io.Closer).Close()
+	if err := c.AddSource("file", s); err != nil {
+		t.Fatalf("failed to add source: %+v", err)
+	}
+	if err := c.Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if err := os.WriteFile(filename, []byte(`{"ms":{"name":"b"}}`), 0600); err != nil {
+		t.Fatalf("failed to write: %+v", err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); c.Get("ms.name") != "b"; time.Sleep(50 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("not reloaded after write: %v", c.Get("ms.name"))
+		}
+	}
+	if err := os.Remove(filename); err != nil {
+		t.Fatalf("failed to remove: %+v", err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(50 * time.Millisecond) {
+		if v, err := s.GetInto("ms.name", ""); err == nil && v == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("still has values after remove")
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/go-msvc/errors"
+)
+
+// defaultHTTPPollInterval is how often AddFileSource() downloads a URL again
+const defaultHTTPPollInterval = 60 * time.Second
+
+// newHTTPSource downloads the file at the http:// or https:// URL
+// and downloads it again every interval, then calls reload when it changed
+// the format is taken from the extension of the URL path, like for files
+func newHTTPSource(rawURL string, interval time.Duration, reload func() error) (*httpSource, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid URL")
+	}
+	if interval <= 0 {
+		return nil, errors.Errorf("%s: invalid polling interval %v", u.Redacted(), interval)
+	}
+	s := &httpSource{
+		url:    rawURL,
+		name:   u.Redacted(),
+		path:   u.Path,
+		client: &http.Client{Timeout: 30 * time.Second},
+		reload: reload,
+		stop:   make(chan struct{}),
+	}
+	if _, err := s.fetchIfChanged(context.Background()); err != nil {
+		return nil, err
+	}
+	go s.poll(interval)
+	return s, nil
+} //newHTTPSource()
+
+// httpSource implements Source for a file downloaded from a URL
+type httpSource struct {
+	url       string
+	name      string //url without the password, to log
+	path      string //url path with the extension of the file
+	client    *http.Client
+	reload    func() error
+	mutex     sync.RWMutex
+	content   []byte
+	data      map[string]interface{}
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+func (s *httpSource) GetInto(name string, tmpl interface{}) (interface{}, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return getInto(s.data, name, tmpl)
+}
+
+// HealthCheck checks that the URL can be downloaded
+// it implements HealthChecker
+func (s *httpSource) HealthCheck(ctx context.Context) error {
+	_, err := s.download(ctx)
+	return err
+}
+
+// Close stops polling
+func (s *httpSource) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.stop)
+	})
+	return nil
+}
+
+func (s *httpSource) poll(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.refresh(context.Background())
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// refresh downloads the file and reloads config when it changed
+func (s *httpSource) refresh(ctx context.Context) {
+	changed, err := s.fetchIfChanged(ctx)
+	if err != nil {
+		Logger().Errorf("%+v", err)
+		return
+	}
+	if changed {
+		if err := s.reload(); err != nil {
+			Logger().Errorf("failed to reload after %s changed: %+v", s.name, err)
+		}
+	}
+}
+
+// fetchIfChanged downloads the file and decodes it if the content changed
+func (s *httpSource) fetchIfChanged(ctx context.Context) (bool, error) {
+	content, err := s.download(ctx)
+	if err != nil {
+		return false, err
+	}
+	s.mutex.RLock()
+	unchanged := s.data != nil && bytes.Equal(content, s.content)
+	s.mutex.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+	value, err := decodeFile(s.path, content)
+	if err != nil {
+		return false, errors.Wrapf(err, "invalid content from %s", s.name)
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.content = content
+	s.data = value
+	Logger().Debugf("Downloaded %s", s.name)
+	return true, nil
+} //httpSource.fetchIfChanged()
+
+func (s *httpSource) download(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot make request for %s", s.name)
+	}
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot download %s", s.name)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("cannot download %s: %s", s.name, res.Status)
+	}
+	content, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot download %s", s.name)
+	}
+	return content, nil
+} //httpSource.download()
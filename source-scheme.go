@@ -0,0 +1,121 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"sync"
+
+	"github.com/go-msvc/errors"
+)
+
+var (
+	schemeMutex           sync.Mutex
+	sourceFactoryByScheme = map[string]func(uri string) (Source, error){}
+)
+
+// RegisterSourceScheme registers the factory that AddSourceURI() calls for URIs with the scheme,
+// usually from init() in the package of the source, e.g.
+//
+//	func init() { config.RegisterSourceScheme("s3", FromURI) }
+//
+// the "file" scheme is registered by default
+func RegisterSourceScheme(scheme string, factory func(uri string) (Source, error)) {
+	if !validScheme(scheme) {
+		panic(fmt.Sprintf("config.RegisterSourceScheme(%s) with invalid scheme", scheme))
+	}
+	if factory == nil {
+		panic(fmt.Sprintf("config.RegisterSourceScheme(%s) called with nil factory", scheme))
+	}
+	schemeMutex.Lock()
+	defer schemeMutex.Unlock()
+	if _, ok := sourceFactoryByScheme[scheme]; ok {
+		panic(fmt.Sprintf("config source scheme(%s) is already registered", scheme))
+	}
+	sourceFactoryByScheme[scheme] = factory
+}
+
+// validScheme is true for a lowercase URI scheme, e.g. "s3" or "git+ssh"
+func validScheme(scheme string) bool {
+	if scheme == "" || scheme[0] < 'a' || scheme[0] > 'z' {
+		return false
+	}
+	for _, r := range scheme {
+		if !(r >= 'a' && r <= 'z') && !(r >= '0' && r <= '9') && r != '+' && r != '-' && r != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+// ListSchemes returns the registered source schemes, sorted
+func ListSchemes() []string {
+	schemeMutex.Lock()
+	defer schemeMutex.Unlock()
+	schemes := make([]string, 0, len(sourceFactoryByScheme))
+	for scheme := range sourceFactoryByScheme {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// AddSourceURI creates a source with the factory registered for the URI scheme
+// and adds it with AddSource(), named by the URI without its password,
+// e.g. config.AddSourceURI("file:///etc/app/config.json")
+func (c *Config) AddSourceURI(uri string) (Source, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, errors.Errorf("invalid config source URI") //not wrapped, it may contain a password
+	}
+	schemeMutex.Lock()
+	factory, ok := sourceFactoryByScheme[u.Scheme]
+	schemeMutex.Unlock()
+	if !ok {
+		return nil, errors.Errorf("unknown config source scheme \"%s\" (registered: %v)", u.Scheme, ListSchemes())
+	}
+	name := u.Redacted()
+	s, err := factory(uri)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot create config source(%s)", name)
+	}
+	if err := c.AddSource(name, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// AddSourceURIOrPanic is AddSourceURI() that panics on error, e.g. in main()
+func (c *Config) AddSourceURIOrPanic(uri string) Source {
+	s, err := c.AddSourceURI(uri)
+	if err != nil {
+		panic(fmt.Sprintf("%+v", err))
+	}
+	return s
+}
+
+// fileFromURI reads the file of "file:///<absolute path>" or "file:<relative path>"
+func fileFromURI(uri string) (Source, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	filename := u.Path
+	if u.Opaque != "" {
+		filename = u.Opaque
+	} else if u.Host != "" && u.Host != "localhost" {
+		filename = u.Host + u.Path //"file://config.json" is a relative path
+	}
+	if filename == "" {
+		return nil, errors.Errorf("missing filename")
+	}
+	data, err := readFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return file{data: data}, nil
+}
+
+func init() {
+	RegisterSourceScheme("file", fileFromURI)
+}
@@ -1,8 +1,7 @@
 package config
 
 import (
-	"encoding/json"
-	"os"
+	"fmt"
 	"strings"
 
 	"github.com/go-msvc/data"
@@ -14,25 +13,34 @@ type Source interface {
 }
 
 type namedSource struct {
-	name   string
-	source Source
+	name          string
+	source        Source
+	priority      int
+	mergeStrategy MergeStrategy //nil to use the config merge strategy, see WithMergeStrategy()
+	builtin       bool          //true for overrides, env and defaults
 }
 
-var (
-	sources = []namedSource{}
-)
-
 // todo: provide mechanism to write config set to a backup, for audit
 // but also for use when sources cannot be reached.
 
 // sources are used in order of being added
+// a source with the same name as an existing source is ignored
 // call this in main func, not in init(), as that will not allow
 // control of the order of sources, and the order determine which
 // one is used, so you can have multiple sources for redundancy,
 // e.g. read remote, but make local copy so if restart and remote
 // is not reachable, then read local backup, or just build that
 // into your source if you only have one
-func AddSource(name string, source Source) error {
+// AddSource is AddSourceWithPriority() with priority 0
+func (c *Config) AddSource(name string, source Source, opts ...SourceOption) error {
+	return c.AddSourceWithPriority(0, name, source, opts...)
+}
+
+// AddSourceWithPriority adds a source that is used before all sources with lower priority,
+// e.g. AddSourceWithPriority(100, "remote", ...) is used before sources added with AddSource()
+// sources with the same priority are used in order of being added
+func (c *Config) AddSourceWithPriority(priority int, name string, source Source, opts ...SourceOption) error {
+	c.checkNotFinalized(fmt.Sprintf("AddSource(%s)", name))
 	name = strings.TrimSpace(name)
 	if name == "" {
 		return errors.Errorf("invalid config source name \"%s\"", name)
@@ -40,27 +48,105 @@ func AddSource(name string, source Source) error {
 	if source == nil {
 		return errors.Errorf("cannot add config source nil")
 	}
-	sources = append(sources, namedSource{name: name, source: source})
+	for _, ns := range c.sources {
+		if ns.name == name {
+			//not an error, e.g. when init() adds a source and is called again in tests
+			Logger().Infof("config source \"%s\" already added - ignored", name)
+			return nil
+		}
+	}
+	//insert after all sources with the same or higher priority
+	i := len(c.sources)
+	for i > 0 && c.sources[i-1].priority < priority {
+		i--
+	}
+	ns := namedSource{name: name, source: source, priority: priority}
+	for _, opt := range opts {
+		opt(&ns)
+	}
+	c.sources = append(c.sources[:i:i], append([]namedSource{ns}, c.sources[i:]...)...)
 	return nil
+} //AddSourceWithPriority()
+
+// getFromSources returns the value of ref from the first source that has it,
+// or merged from the sources, see SetMergeStrategy()
+// the first value is used, so multiple sources can be specified for redundancy
+// or to support a mix of sources
+// it returns nil value and nil error if ref is not configured in any source
+//
+// sources must return nil value and nil error if ref is not configured in it,
+// so an error is treated as an error in the source, e.g. cannot connect to source
+// or authentication error or invalid values
+func (c *Config) getFromSources(ref string, tmpl interface{}) (interface{}, namedSource, error) {
+	sources := c.allSources()
+	loaded, ok, err := readValue(c.loaderSources(sources), ref, tmpl)
+	if err != nil || !ok {
+		return nil, namedSource{}, err
+	}
+	for _, ns := range sources {
+		if ns.name == loaded.SourceName {
+			return loaded.Value, ns, nil
+		}
+	}
+	//merged from multiple sources
+	return loaded.Value, namedSource{name: loaded.SourceName, source: mergedSource{ref: ref, value: loaded.Value}}, nil
+}
+
+// allSources returns the sources in the order they are used:
+// overrides if SetOverride() was called, then sourcesAndEnv()
+func (c *Config) allSources() []namedSource {
+	if !c.hasOverrides() {
+		return c.sourcesAndEnv()
+	}
+	return append([]namedSource{{name: overrideSourceName, source: overrideSource{c: c}, builtin: true}}, c.sourcesAndEnv()...)
+}
+
+// sourcesAndEnv returns the added sources followed by environment variables
+// if they were bound with BindEnv() or BindEnvPrefix(),
+// and last the defaults if any were declared with DeclareDefault()
+func (c *Config) sourcesAndEnv() []namedSource {
+	hasEnv, hasDefaults := c.hasEnvBindings(), c.hasDefaults()
+	if !hasEnv && !hasDefaults {
+		return c.sources
+	}
+	list := append([]namedSource{}, c.sources...)
+	if hasEnv {
+		list = append(list, namedSource{name: envSourceName, source: envSource{c: c}, builtin: true})
+	}
+	if hasDefaults {
+		list = append(list, namedSource{name: defaultsSourceName, source: defaultsSource{c: c}, builtin: true})
+	}
+	return list
 }
 
 // defaultfile is used if config is loaded with no sources
-// to load config from file "./config.json"
+// to load config from file "./config.json", "./config.yaml" or "./config.yml"
 type defaultfile struct {
 	value interface{}
 }
 
 func (f defaultfile) GetInto(name string, tmpl interface{}) (interface{}, error) {
 	if f.value == nil {
-		fn := "./config.json"
-		cf, err := os.Open(fn)
+		fn, err := findFile("./config")
 		if err != nil {
-			return nil, errors.Wrapf(err, "cannot open file %s", fn)
+			return nil, err
 		}
-		defer cf.Close()
-		if err := json.NewDecoder(cf).Decode(&f.value); err != nil {
-			return nil, errors.Wrapf(err, "failed to decode JSON from file %s", fn)
+		if f.value, err = readFile(fn); err != nil {
+			return nil, err
 		}
 	}
-	return data.GetInto(f.value, name, tmpl)
+	return getInto(f.value, name, tmpl)
+}
+
+// getInto does data.GetInto() but returns nil,nil when name does not exist in value
+// or is null, because sources must return nil,nil for config that is not configured
+func getInto(value interface{}, name string, tmpl interface{}) (interface{}, error) {
+	if name == "" {
+		//data.GetInto() cannot get scalar values with name "", so wrap it
+		value, name = map[string]interface{}{"value": value}, "value"
+	}
+	if v, err := data.Get(value, name); err != nil || v == nil {
+		return nil, nil //not configured
+	}
+	return data.GetInto(value, name, tmpl)
 }
@@ -0,0 +1,241 @@
+// Package awsappconfig is a config source that reads a JSON configuration
+// profile from AWS AppConfig, e.g. the profile {"db":{"host":"db1"}}
+// has config "db.host"
+//
+// it uses the AppConfig Data API: a configuration session is started
+// and each refresh polls for the latest configuration with the token
+// returned by the previous poll, so only changes are downloaded
+package awsappconfig
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/appconfigdata"
+	"github.com/go-msvc/config"
+	"github.com/go-msvc/data"
+	"github.com/go-msvc/errors"
+)
+
+// agentName and agentVersion are sent in the user agent header
+// as required by AppConfig, e.g. "AppConfigAgent/1.0"
+const (
+	agentName    = "AppConfigAgent"
+	agentVersion = "1.0"
+)
+
+// Client is the part of the AppConfig Data client used by this source
+// so tests can use a mock instead of *appconfigdata.Client
+type Client interface {
+	StartConfigurationSession(ctx context.Context, params *appconfigdata.StartConfigurationSessionInput, optFns ...func(*appconfigdata.Options)) (*appconfigdata.StartConfigurationSessionOutput, error)
+	GetLatestConfiguration(ctx context.Context, params *appconfigdata.GetLatestConfigurationInput, optFns ...func(*appconfigdata.Options)) (*appconfigdata.GetLatestConfigurationOutput, error)
+}
+
+// New reads the configuration profile of the application in the environment
+// using the default AWS credentials
+// values are kept in memory until Refresh()
+func New(appID, envID, profileID, region string, opts ...Option) (*Source, error) {
+	client, err := newClient(region)
+	if err != nil {
+		return nil, errors.Wrapf(err, "appconfig(%s/%s/%s)", appID, envID, profileID)
+	}
+	return NewWithClient(client, appID, envID, profileID, opts...)
+}
+
+// NewWithClient is New() using the specified client
+func NewWithClient(client Client, appID, envID, profileID string, opts ...Option) (*Source, error) {
+	if client == nil {
+		panic("awsappconfig.NewWithClient(nil)")
+	}
+	s := &Source{
+		client:    client,
+		appID:     appID,
+		envID:     envID,
+		profileID: profileID,
+		reload:    config.Reload,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if _, err := s.refresh(context.Background()); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// NewPolling is New() that calls Refresh() every interval, or less often when
+// AppConfig asks for a longer interval, and config.Reload() when the
+// configuration changed, so that config.Watch() callbacks are called for the
+// changed values
+// call Close() to stop polling
+func NewPolling(appID, envID, profileID, region string, interval time.Duration, opts ...Option) (*Source, error) {
+	if interval <= 0 {
+		return nil, errors.Errorf("appconfig(%s/%s/%s): invalid polling interval %v", appID, envID, profileID, interval)
+	}
+	s, err := New(appID, envID, profileID, region, opts...)
+	if err != nil {
+		return nil, err
+	}
+	s.startPolling(interval)
+	return s, nil
+}
+
+// Option changes how the source is created
+type Option func(*Source)
+
+// WithConfig makes polling reload c instead of the default config
+func WithConfig(c *config.Config) Option {
+	return WithReload(c.Reload)
+}
+
+// WithReload makes polling call reload instead of config.Reload()
+func WithReload(reload func() error) Option {
+	return func(s *Source) {
+		s.reload = reload
+	}
+}
+
+func newClient(region string, optFns ...func(*appconfigdata.Options)) (*appconfigdata.Client, error) {
+	cfg, err := awsconfig.LoadDefaultConfig(context.Background(), awsconfig.WithRegion(region))
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot load AWS config")
+	}
+	optFns = append([]func(*appconfigdata.Options){
+		appconfigdata.WithAPIOptions(awsmiddleware.AddUserAgentKeyValue(agentName, agentVersion)),
+	}, optFns...)
+	return appconfigdata.NewFromConfig(cfg, optFns...), nil
+}
+
+// Source implements config.Source
+type Source struct {
+	client       Client
+	appID        string
+	envID        string
+	profileID    string
+	reload       func() error
+	mutex        sync.RWMutex
+	data         map[string]interface{}
+	token        string        //next poll token, "" to start a new session
+	pollInterval time.Duration //minimum poll interval from AppConfig
+	stop         chan struct{}
+	closeOnce    sync.Once
+}
+
+func (s *Source) GetInto(name string, tmpl interface{}) (interface{}, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if _, err := data.Get(s.data, name); err != nil {
+		return nil, nil //not configured
+	}
+	return data.GetInto(s.data, name, tmpl)
+}
+
+// Refresh polls for the latest configuration
+// call config.Reload() after it to use the new values
+func (s *Source) Refresh() error {
+	_, err := s.refresh(context.Background())
+	return err
+}
+
+func (s *Source) String() string {
+	return "appconfig(" + s.appID + "/" + s.envID + "/" + s.profileID + ")"
+}
+
+// refresh polls for the latest configuration and returns true if it changed
+// an empty response means the configuration did not change since the last poll
+func (s *Source) refresh(ctx context.Context) (bool, error) {
+	s.mutex.Lock()
+	token := s.token
+	s.mutex.Unlock()
+	if token == "" {
+		session, err := s.client.StartConfigurationSession(ctx, &appconfigdata.StartConfigurationSessionInput{
+			ApplicationIdentifier:          aws.String(s.appID),
+			EnvironmentIdentifier:          aws.String(s.envID),
+			ConfigurationProfileIdentifier: aws.String(s.profileID),
+		})
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to start %s session", s)
+		}
+		token = aws.ToString(session.InitialConfigurationToken)
+	}
+
+	out, err := s.client.GetLatestConfiguration(ctx, &appconfigdata.GetLatestConfigurationInput{
+		ConfigurationToken: aws.String(token),
+	})
+	if err != nil {
+		//tokens expire, so start a new session on the next refresh
+		s.mutex.Lock()
+		s.token = ""
+		s.mutex.Unlock()
+		return false, errors.Wrapf(err, "failed to get %s", s)
+	}
+
+	var value map[string]interface{}
+	if len(out.Configuration) > 0 {
+		if err := json.Unmarshal(out.Configuration, &value); err != nil {
+			return false, errors.Wrapf(err, "%s is not a JSON object", s)
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.token = aws.ToString(out.NextPollConfigurationToken)
+	s.pollInterval = time.Duration(out.NextPollIntervalInSeconds) * time.Second
+	if len(out.Configuration) == 0 {
+		if s.data == nil {
+			s.data = map[string]interface{}{}
+		}
+		return false, nil //not changed
+	}
+	changed := !reflect.DeepEqual(s.data, value)
+	s.data = value
+	config.Logger().Debugf("Fetched %s version %s", s, aws.ToString(out.VersionLabel))
+	return changed, nil
+} //Source.refresh()
+
+func (s *Source) startPolling(interval time.Duration) {
+	s.stop = make(chan struct{})
+	go func() {
+		for {
+			delay := interval
+			s.mutex.RLock()
+			if s.pollInterval > delay {
+				delay = s.pollInterval
+			}
+			s.mutex.RUnlock()
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+				changed, err := s.refresh(context.Background())
+				if err != nil {
+					config.Logger().Errorf("%+v", err)
+					continue
+				}
+				if changed {
+					if err := s.reload(); err != nil {
+						config.Logger().Errorf("failed to reload after %s changed: %+v", s, err)
+					}
+				}
+			case <-s.stop:
+				timer.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Close stops polling
+func (s *Source) Close() error {
+	s.closeOnce.Do(func() {
+		if s.stop != nil {
+			close(s.stop)
+		}
+	})
+	return nil
+}
@@ -0,0 +1,188 @@
+package awsappconfig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/appconfigdata"
+)
+
+// testServer mocks the AppConfig Data HTTP API
+// each poll token remembers the version it returned so that
+// only a changed configuration is returned again
+type testServer struct {
+	sync.Mutex
+	t             *testing.T
+	configuration string
+	version       int
+	sessions      int
+	polls         int
+	tokenVersion  map[string]int
+}
+
+func (ts *testServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ts.Lock()
+	defer ts.Unlock()
+	if ua := r.Header.Get("User-Agent"); !strings.Contains(ua, agentName+"/"+agentVersion) {
+		ts.t.Errorf("User-Agent %q without %s", ua, agentName)
+		http.Error(w, "missing agent", http.StatusBadRequest)
+		return
+	}
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/configurationsessions":
+		var req map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req["ApplicationIdentifier"] != "shop" || req["EnvironmentIdentifier"] != "prod" || req["ConfigurationProfileIdentifier"] != "main" {
+			http.Error(w, "bad session request", http.StatusBadRequest)
+			return
+		}
+		ts.sessions++
+		token := fmt.Sprintf("s%d", ts.sessions)
+		ts.tokenVersion[token] = -1
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"InitialConfigurationToken":%q}`, token)
+	case r.Method == http.MethodGet && r.URL.Path == "/configuration":
+		token := r.URL.Query().Get("configuration_token")
+		version, ok := ts.tokenVersion[token]
+		if !ok {
+			w.Header().Set("X-Amzn-ErrorType", "BadRequestException")
+			http.Error(w, `{"Message":"invalid token"}`, http.StatusBadRequest)
+			return
+		}
+		delete(ts.tokenVersion, token)
+		ts.polls++
+		next := fmt.Sprintf("p%d", ts.polls)
+		ts.tokenVersion[next] = ts.version
+		w.Header().Set("Next-Poll-Configuration-Token", next)
+		w.Header().Set("Next-Poll-Interval-In-Seconds", "0")
+		w.Header().Set("Content-Type", "application/json")
+		if version != ts.version {
+			w.Header().Set("Version-Label", fmt.Sprintf("%d", ts.version))
+			w.Write([]byte(ts.configuration))
+		}
+	default:
+		http.NotFound(w, r)
+	}
+} //testServer.ServeHTTP()
+
+func (ts *testServer) set(configuration string) {
+	ts.Lock()
+	defer ts.Unlock()
+	ts.configuration = configuration
+	ts.version++
+}
+
+func newTestServer(t *testing.T, configuration string) (*testServer, Client) {
+	ts := &testServer{t: t, configuration: configuration, tokenVersion: map[string]int{}}
+	server := httptest.NewServer(ts)
+	t.Cleanup(server.Close)
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_CONFIG_FILE", "/nonexistent")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "/nonexistent")
+	client, err := newClient("eu-west-1", func(o *appconfigdata.Options) {
+		o.BaseEndpoint = aws.String(server.URL)
+	})
+	if err != nil {
+		t.Fatalf("failed to create client: %+v", err)
+	}
+	return ts, client
+}
+
+type testDBConfig struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+func TestAppConfig(t *testing.T) {
+	ts, client := newTestServer(t, `{"db":{"host":"db1","port":5432},"debug":true}`)
+	s, err := NewWithClient(client, "shop", "prod", "main")
+	if err != nil {
+		t.Fatalf("failed to create source: %+v", err)
+	}
+	v, err := s.GetInto("db", testDBConfig{})
+	if err != nil {
+		t.Fatalf("failed to get: %+v", err)
+	}
+	if c := v.(testDBConfig); c.Host != "db1" || c.Port != 5432 {
+		t.Fatalf("got %+v", c)
+	}
+	if v, err := s.GetInto("debug", false); err != nil || v != true {
+		t.Fatalf("got %v,%v", v, err)
+	}
+	if v, err := s.GetInto("unknown", ""); v != nil || err != nil {
+		t.Fatalf("got %v,%v for unknown", v, err)
+	}
+
+	//no change: empty response keeps the values
+	if changed, err := s.refresh(context.Background()); err != nil || changed {
+		t.Fatalf("refresh without change: %v,%+v", changed, err)
+	}
+	if v, _ := s.GetInto("db.host", ""); v != "db1" {
+		t.Fatalf("got %v after empty response", v)
+	}
+
+	ts.set(`{"db":{"host":"db2"}}`)
+	if changed, err := s.refresh(context.Background()); err != nil || !changed {
+		t.Fatalf("refresh with change: %v,%+v", changed, err)
+	}
+	v, _ = s.GetInto("db", testDBConfig{})
+	if c := v.(testDBConfig); c.Host != "db2" || c.Port != 0 {
+		t.Fatalf("got %+v after refresh", c)
+	}
+	if ts.sessions != 1 {
+		t.Fatalf("%d sessions", ts.sessions)
+	}
+
+	//expired token starts a new session on the next refresh
+	ts.Lock()
+	ts.tokenVersion = map[string]int{}
+	ts.Unlock()
+	if err := s.Refresh(); err == nil {
+		t.Fatalf("refreshed with expired token")
+	}
+	if err := s.Refresh(); err != nil {
+		t.Fatalf("failed to refresh with new session: %+v", err)
+	}
+	if ts.sessions != 2 {
+		t.Fatalf("%d sessions after expired token", ts.sessions)
+	}
+}
+
+func TestPolling(t *testing.T) {
+	ts, client := newTestServer(t, `{"db":{"host":"db1"}}`)
+	reloads := int32(0)
+	s, err := NewWithClient(client, "shop", "prod", "main", WithReload(func() error {
+		atomic.AddInt32(&reloads, 1)
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("failed to create source: %+v", err)
+	}
+	s.startPolling(5 * time.Millisecond)
+	defer s.Close()
+
+	time.Sleep(30 * time.Millisecond)
+	if n := atomic.LoadInt32(&reloads); n != 0 {
+		t.Fatalf("reloaded %d times without changes", n)
+	}
+	ts.set(`{"db":{"host":"db2"}}`)
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&reloads) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("not reloaded after change")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if v, _ := s.GetInto("db.host", ""); v != "db2" {
+		t.Fatalf("got %v after reload", v)
+	}
+}
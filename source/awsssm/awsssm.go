@@ -0,0 +1,259 @@
+// Package awsssm is a config source that reads all parameters under a path
+// from AWS Systems Manager Parameter Store, e.g. with root path "/app/prod"
+// the parameter "/app/prod/db/host" is config "db.host"
+//
+// values that are valid JSON are parsed, e.g. "5432" is a number,
+// others like "db1" are strings
+package awsssm
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/go-msvc/config"
+	"github.com/go-msvc/data"
+	"github.com/go-msvc/errors"
+)
+
+// Client is the part of the SSM client used by this source
+// so tests can use a mock instead of *ssm.Client
+type Client interface {
+	GetParametersByPath(ctx context.Context, params *ssm.GetParametersByPathInput, optFns ...func(*ssm.Options)) (*ssm.GetParametersByPathOutput, error)
+}
+
+// NewHierarchy reads all parameters under rootPath, with SecureString parameters decrypted,
+// using the default AWS credentials
+// values are kept in memory until Refresh()
+func NewHierarchy(region, rootPath string, opts ...Option) (*Source, error) {
+	client, err := newClient(region)
+	if err != nil {
+		return nil, errors.Wrapf(err, "ssm(%s)", rootPath)
+	}
+	return NewHierarchyWithClient(client, rootPath, opts...)
+}
+
+// NewHierarchyWithClient is NewHierarchy() using the specified client
+func NewHierarchyWithClient(client Client, rootPath string, opts ...Option) (*Source, error) {
+	if client == nil {
+		panic("awsssm.NewHierarchyWithClient(nil)")
+	}
+	s := &Source{
+		client:   client,
+		rootPath: "/" + strings.Trim(rootPath, "/"),
+		reload:   config.Reload,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if _, err := s.refresh(context.Background()); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// NewPolling is NewHierarchy() that calls Refresh() every interval
+// and config.Reload() when any parameter changed, so that config.Watch()
+// callbacks are called for the changed values
+// call Close() to stop polling
+func NewPolling(region, rootPath string, interval time.Duration, opts ...Option) (*Source, error) {
+	if interval <= 0 {
+		return nil, errors.Errorf("ssm(%s): invalid polling interval %v", rootPath, interval)
+	}
+	s, err := NewHierarchy(region, rootPath, opts...)
+	if err != nil {
+		return nil, err
+	}
+	s.startPolling(interval)
+	return s, nil
+}
+
+// Option changes how the source is created
+type Option func(*Source)
+
+// WithConfig makes polling reload c instead of the default config
+func WithConfig(c *config.Config) Option {
+	return WithReload(c.Reload)
+}
+
+// WithReload makes polling call reload instead of config.Reload()
+func WithReload(reload func() error) Option {
+	return func(s *Source) {
+		s.reload = reload
+	}
+}
+
+// FromURI is NewHierarchy() for "ssm:///<root path>?region=<region>"
+// it is registered for config.AddSourceURI()
+func FromURI(uri string) (config.Source, error) {
+	rootPath, region, err := parseURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	return NewHierarchy(region, rootPath)
+}
+
+func parseURI(uri string) (rootPath, region string, err error) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "ssm" {
+		return "", "", errors.Errorf("invalid SSM URI, expecting ssm:///<root path>")
+	}
+	rootPath = "/" + strings.Trim(u.Host+u.Path, "/") //"ssm://app/prod" is also accepted
+	return rootPath, u.Query().Get("region"), nil
+}
+
+func init() {
+	config.RegisterSourceScheme("ssm", FromURI)
+}
+
+func newClient(region string) (*ssm.Client, error) {
+	cfg, err := awsconfig.LoadDefaultConfig(context.Background(), awsconfig.WithRegion(region))
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot load AWS config")
+	}
+	return ssm.NewFromConfig(cfg), nil
+}
+
+// Source implements config.Source
+type Source struct {
+	client    Client
+	rootPath  string
+	reload    func() error
+	mutex     sync.RWMutex
+	data      map[string]interface{}
+	names     []string
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+func (s *Source) GetInto(name string, tmpl interface{}) (interface{}, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if _, err := data.Get(s.data, name); err != nil {
+		return nil, nil //not configured
+	}
+	return data.GetInto(s.data, name, tmpl)
+}
+
+// Keys returns the config names of all parameters, e.g. "db.host", sorted
+// it implements config.ListableSource
+func (s *Source) Keys() []string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return append([]string{}, s.names...)
+}
+
+// Refresh reads all parameters again
+// call config.Reload() after it to use the new values
+func (s *Source) Refresh() error {
+	_, err := s.refresh(context.Background())
+	return err
+}
+
+// refresh reads all pages of parameters and returns true if any changed
+func (s *Source) refresh(ctx context.Context) (bool, error) {
+	value := map[string]interface{}{}
+	names := []string{}
+	paginator := ssm.NewGetParametersByPathPaginator(s.client, &ssm.GetParametersByPathInput{
+		Path:           aws.String(s.rootPath),
+		Recursive:      aws.Bool(true),
+		WithDecryption: aws.Bool(true),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to get ssm(%s)", s.rootPath)
+		}
+		for _, p := range page.Parameters {
+			name := s.configName(aws.ToString(p.Name))
+			if name == "" {
+				continue
+			}
+			setNested(value, strings.Split(name, "."), parseValue(aws.ToString(p.Value)))
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	changed := !reflect.DeepEqual(s.data, value)
+	s.data = value
+	s.names = names
+	config.Logger().Debugf("Fetched %d parameters from ssm(%s)", len(names), s.rootPath)
+	return changed, nil
+} //Source.refresh()
+
+// configName returns the config name of a parameter, e.g. "/app/prod/db/host" -> "db.host"
+func (s *Source) configName(parameterName string) string {
+	rel := strings.TrimPrefix(parameterName, s.rootPath)
+	if s.rootPath == "/" {
+		rel = strings.TrimPrefix(parameterName, "/")
+	}
+	return strings.ReplaceAll(strings.Trim(rel, "/"), "/", ".")
+}
+
+// setNested sets value in m at the names, creating or replacing parent maps
+func setNested(m map[string]interface{}, names []string, value interface{}) {
+	for _, name := range names[:len(names)-1] {
+		child, ok := m[name].(map[string]interface{})
+		if !ok {
+			child = map[string]interface{}{}
+			m[name] = child
+		}
+		m = child
+	}
+	m[names[len(names)-1]] = value
+}
+
+// parseValue returns the JSON value, or the string if it is not valid JSON
+func parseValue(s string) interface{} {
+	var value interface{}
+	if err := json.Unmarshal([]byte(s), &value); err == nil {
+		return value
+	}
+	return s
+}
+
+func (s *Source) startPolling(interval time.Duration) {
+	s.stop = make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				changed, err := s.refresh(context.Background())
+				if err != nil {
+					config.Logger().Errorf("%+v", err)
+					continue
+				}
+				if changed {
+					if err := s.reload(); err != nil {
+						config.Logger().Errorf("failed to reload after ssm(%s) changed: %+v", s.rootPath, err)
+					}
+				}
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Close stops polling
+func (s *Source) Close() error {
+	s.closeOnce.Do(func() {
+		if s.stop != nil {
+			close(s.stop)
+		}
+	})
+	return nil
+}
@@ -0,0 +1,149 @@
+package awsssm
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+// testClient returns the parameters 2 per page
+type testClient struct {
+	sync.Mutex
+	parameters []types.Parameter
+	calls      int
+}
+
+func (c *testClient) GetParametersByPath(ctx context.Context, params *ssm.GetParametersByPathInput, optFns ...func(*ssm.Options)) (*ssm.GetParametersByPathOutput, error) {
+	c.Lock()
+	defer c.Unlock()
+	c.calls++
+	if !aws.ToBool(params.Recursive) || !aws.ToBool(params.WithDecryption) {
+		panic("expected recursive with decryption")
+	}
+	start := 0
+	if params.NextToken != nil {
+		start, _ = strconv.Atoi(*params.NextToken)
+	}
+	end := start + 2
+	out := &ssm.GetParametersByPathOutput{}
+	if end < len(c.parameters) {
+		out.NextToken = aws.String(strconv.Itoa(end))
+	} else {
+		end = len(c.parameters)
+	}
+	out.Parameters = append(out.Parameters, c.parameters[start:end]...)
+	return out, nil
+}
+
+func (c *testClient) set(values map[string]string) {
+	c.Lock()
+	defer c.Unlock()
+	c.parameters = nil
+	for name, value := range values {
+		c.parameters = append(c.parameters, types.Parameter{Name: aws.String(name), Value: aws.String(value)})
+	}
+}
+
+type testDBConfig struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+func TestHierarchy(t *testing.T) {
+	client := &testClient{}
+	client.set(map[string]string{
+		"/app/prod/db/host":    "db1",
+		"/app/prod/db/port":    "5432",
+		"/app/prod/cache/size": "100",
+		"/app/prod/name":       "shop",
+		"/app/prod/debug":      "true",
+	})
+	s, err := NewHierarchyWithClient(client, "/app/prod/")
+	if err != nil {
+		t.Fatalf("failed to create source: %+v", err)
+	}
+	if client.calls != 3 {
+		t.Fatalf("%d calls for 3 pages", client.calls)
+	}
+	v, err := s.GetInto("db", testDBConfig{})
+	if err != nil {
+		t.Fatalf("failed to get: %+v", err)
+	}
+	if c := v.(testDBConfig); c.Host != "db1" || c.Port != 5432 {
+		t.Fatalf("got %+v", c)
+	}
+	if v, err := s.GetInto("debug", false); err != nil || v != true {
+		t.Fatalf("got %v,%v", v, err)
+	}
+	if v, err := s.GetInto("unknown", ""); v != nil || err != nil {
+		t.Fatalf("got %v,%v for unknown", v, err)
+	}
+	expected := []string{"cache.size", "db.host", "db.port", "debug", "name"}
+	if names := s.Keys(); !reflect.DeepEqual(names, expected) {
+		t.Fatalf("names %v", names)
+	}
+	if client.calls != 3 {
+		t.Fatalf("GetInto() called SSM")
+	}
+
+	client.set(map[string]string{"/app/prod/db/host": "db2"})
+	if err := s.Refresh(); err != nil {
+		t.Fatalf("failed to refresh: %+v", err)
+	}
+	v, _ = s.GetInto("db", testDBConfig{})
+	if c := v.(testDBConfig); c.Host != "db2" || c.Port != 0 {
+		t.Fatalf("got %+v after refresh", c)
+	}
+}
+
+func TestPolling(t *testing.T) {
+	client := &testClient{}
+	client.set(map[string]string{"/app/db/host": "db1"})
+	reloads := int32(0)
+	s, err := NewHierarchyWithClient(client, "/app", WithReload(func() error {
+		atomic.AddInt32(&reloads, 1)
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("failed to create source: %+v", err)
+	}
+	s.startPolling(5 * time.Millisecond)
+	defer s.Close()
+
+	time.Sleep(30 * time.Millisecond)
+	if n := atomic.LoadInt32(&reloads); n != 0 {
+		t.Fatalf("reloaded %d times without changes", n)
+	}
+	client.set(map[string]string{"/app/db/host": "db2"})
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&reloads) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("not reloaded after change")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestParseURI(t *testing.T) {
+	for uri, expected := range map[string][2]string{
+		"ssm:///app/prod?region=eu-west-1": {"/app/prod", "eu-west-1"},
+		"ssm://app/prod/":                  {"/app/prod", ""},
+		"ssm:///":                          {"/", ""},
+	} {
+		rootPath, region, err := parseURI(uri)
+		if err != nil || rootPath != expected[0] || region != expected[1] {
+			t.Fatalf("%s -> %s,%s,%v", uri, rootPath, region, err)
+		}
+	}
+	if _, _, err := parseURI("s3://bucket/key"); err == nil {
+		t.Fatalf("parsed other scheme")
+	}
+}
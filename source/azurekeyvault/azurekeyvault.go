@@ -0,0 +1,125 @@
+// Package azurekeyvault is a config source that reads secrets from Azure Key Vault
+// secret names cannot have dots, so config "db.password" is secret "db-password"
+//
+// secrets with JSON values are decoded, e.g. secret "db" = {"host":"db1","port":5432}
+// can be read into a struct, while other values are strings
+// every GetInto() reads the vault, use source/cache to read less often
+package azurekeyvault
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
+	"github.com/go-msvc/data"
+	"github.com/go-msvc/errors"
+)
+
+// Client is the part of the Key Vault secrets client used by this source
+// so tests can use a mock instead of *azsecrets.Client
+type Client interface {
+	GetSecret(ctx context.Context, name string, version string, options *azsecrets.GetSecretOptions) (azsecrets.GetSecretResponse, error)
+}
+
+// New reads the latest version of secrets from the vault, e.g. "https://myvault.vault.azure.net/"
+// using the credential, e.g. from azidentity.NewDefaultAzureCredential()
+func New(vaultURL string, credential azcore.TokenCredential) (*Source, error) {
+	return NewWithVersion(vaultURL, credential, "")
+}
+
+// NewWithVersion is New() that reads the specified version of each secret,
+// "" for the latest version
+func NewWithVersion(vaultURL string, credential azcore.TokenCredential, version string) (*Source, error) {
+	return newSource(vaultURL, credential, version, nil)
+}
+
+// NewWithClientSecret is New() that authenticates as the application with the client secret,
+// e.g. when not running in Azure with a managed identity
+func NewWithClientSecret(vaultURL, tenantID, clientID, clientSecret string) (*Source, error) {
+	credential, err := azidentity.NewClientSecretCredential(tenantID, clientID, clientSecret, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot create Azure credential for client(%s)", clientID)
+	}
+	return New(vaultURL, credential)
+}
+
+// NewWithClient reads the specified version of secrets with the client, "" for the latest version
+func NewWithClient(client Client, version string) *Source {
+	if client == nil {
+		panic("azurekeyvault.NewWithClient(nil)")
+	}
+	return &Source{client: client, version: version, timeout: defaultTimeout, now: time.Now}
+}
+
+const defaultTimeout = 30 * time.Second
+
+func newSource(vaultURL string, credential azcore.TokenCredential, version string, options *azsecrets.ClientOptions) (*Source, error) {
+	if credential == nil {
+		return nil, errors.Errorf("azurekeyvault(%s) without credential", vaultURL)
+	}
+	client, err := azsecrets.NewClient(vaultURL, credential, options)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot create Azure Key Vault client for %s", vaultURL)
+	}
+	return NewWithClient(client, version), nil
+}
+
+// Source implements config.Source
+type Source struct {
+	client  Client
+	version string
+	timeout time.Duration
+	now     func() time.Time
+}
+
+// GetInto reads the secret named like the config name with dots replaced by hyphens
+// secrets that do not exist, are disabled or expired are not configured
+func (s *Source) GetInto(name string, tmpl interface{}) (interface{}, error) {
+	secretName := SecretName(name)
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	resp, err := s.client.GetSecret(ctx, secretName, s.version, nil)
+	if err != nil {
+		if respErr, ok := err.(*azcore.ResponseError); ok {
+			if respErr.StatusCode == http.StatusNotFound {
+				return nil, nil //not configured
+			}
+			if respErr.StatusCode == http.StatusForbidden && strings.Contains(respErr.Error(), "SecretDisabled") {
+				return nil, nil //disabled
+			}
+		}
+		return nil, errors.Wrapf(err, "failed to get secret(%s)", secretName)
+	}
+	if attrs := resp.Attributes; attrs != nil {
+		if (attrs.Enabled != nil && !*attrs.Enabled) || (attrs.Expires != nil && !s.now().Before(*attrs.Expires)) {
+			return nil, nil //disabled or expired
+		}
+	}
+	if resp.Value == nil {
+		return nil, nil
+	}
+	value := *resp.Value
+	if _, ok := tmpl.(string); ok {
+		return value, nil //as is, even if it is valid JSON
+	}
+	var jsonValue interface{}
+	if err := json.Unmarshal([]byte(value), &jsonValue); err != nil {
+		jsonValue = value //plain string
+	}
+	//data.GetInto() cannot get scalar values with name "", so wrap it
+	v, err := data.GetInto(map[string]interface{}{"value": jsonValue}, "value", tmpl)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid secret(%s)", secretName)
+	}
+	return v, nil
+} //Source.GetInto()
+
+// SecretName returns the Key Vault secret name for a config name, e.g. "db.password" -> "db-password"
+func SecretName(name string) string {
+	return strings.ReplaceAll(name, ".", "-")
+}
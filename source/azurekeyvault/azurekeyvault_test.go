@@ -0,0 +1,157 @@
+package azurekeyvault
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
+)
+
+type testSecret struct {
+	value   string
+	version string
+	enabled bool
+	expires time.Time
+}
+
+// testVault mocks the Key Vault REST API
+// requests without a token get the authentication challenge
+type testVault struct {
+	sync.Mutex
+	secrets []testSecret
+	byName  map[string][]testSecret
+}
+
+const testToken = "test-token"
+
+func (v *testVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	v.Lock()
+	defer v.Unlock()
+	if r.Header.Get("Authorization") != "Bearer "+testToken {
+		w.Header().Set("WWW-Authenticate", `Bearer authorization="https://login.microsoftonline.com/tenant", resource="https://vault.azure.net"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if r.Method != http.MethodGet || len(parts) < 2 || parts[0] != "secrets" {
+		http.NotFound(w, r)
+		return
+	}
+	name, version := parts[1], ""
+	if len(parts) > 2 {
+		version = parts[2]
+	}
+	var found *testSecret
+	for i, s := range v.byName[name] {
+		if version == "" || s.version == version {
+			found = &v.byName[name][i] //last is the latest
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if found == nil {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":{"code":"SecretNotFound","message":"not found"}}`))
+		return
+	}
+	if !found.enabled {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error":{"code":"Forbidden","message":"Operation get is not allowed on a disabled secret.","innererror":{"code":"SecretDisabled"}}}`))
+		return
+	}
+	attributes := map[string]interface{}{"enabled": true}
+	if !found.expires.IsZero() {
+		attributes["exp"] = found.expires.Unix()
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"value":      found.value,
+		"id":         "https://" + r.Host + "/secrets/" + name + "/" + found.version,
+		"attributes": attributes,
+	})
+} //testVault.ServeHTTP()
+
+func (v *testVault) add(name string, s testSecret) {
+	v.Lock()
+	defer v.Unlock()
+	v.byName[name] = append(v.byName[name], s)
+}
+
+type testCredential struct{}
+
+func (testCredential) GetToken(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: testToken, ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+func newTestSource(t *testing.T, version string) (*testVault, *Source) {
+	vault := &testVault{byName: map[string][]testSecret{}}
+	server := httptest.NewTLSServer(vault)
+	t.Cleanup(server.Close)
+	s, err := newSource(server.URL, testCredential{}, version, &azsecrets.ClientOptions{
+		ClientOptions:                        azcore.ClientOptions{Transport: server.Client()},
+		DisableChallengeResourceVerification: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create source: %+v", err)
+	}
+	return vault, s
+}
+
+type testDBConfig struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+func TestGetInto(t *testing.T) {
+	vault, s := newTestSource(t, "")
+	vault.add("ms-db", testSecret{value: `{"host":"db1","port":5432}`, version: "v1", enabled: true})
+	vault.add("ms-db-password", testSecret{value: "secret", version: "v1", enabled: true})
+	vault.add("ms-port", testSecret{value: "8080", version: "v1", enabled: true})
+	vault.add("ms-json", testSecret{value: `{"a":1}`, version: "v1", enabled: true})
+	vault.add("ms-disabled", testSecret{value: "x", version: "v1", enabled: false})
+	vault.add("ms-expired", testSecret{value: "x", version: "v1", enabled: true, expires: time.Now().Add(-time.Hour)})
+
+	v, err := s.GetInto("ms.db", testDBConfig{})
+	if err != nil {
+		t.Fatalf("failed to get: %+v", err)
+	}
+	if c := v.(testDBConfig); c.Host != "db1" || c.Port != 5432 {
+		t.Fatalf("got %+v", c)
+	}
+	if v, err := s.GetInto("ms.db.password", ""); err != nil || v != "secret" {
+		t.Fatalf("password %v,%+v", v, err)
+	}
+	if v, err := s.GetInto("ms.port", 0); err != nil || v != 8080 {
+		t.Fatalf("port %v,%+v", v, err)
+	}
+	if v, err := s.GetInto("ms.json", ""); err != nil || v != `{"a":1}` {
+		t.Fatalf("json as string %v,%+v", v, err)
+	}
+	for _, name := range []string{"ms.unknown", "ms.disabled", "ms.expired"} {
+		if v, err := s.GetInto(name, ""); v != nil || err != nil {
+			t.Fatalf("%s: %v,%+v", name, v, err)
+		}
+	}
+	if _, err := s.GetInto("ms.db.password", 0); err == nil {
+		t.Fatalf("got string secret into int")
+	}
+}
+
+func TestVersion(t *testing.T) {
+	vault, s := newTestSource(t, "v1")
+	vault.add("name", testSecret{value: "old", version: "v1", enabled: true})
+	vault.add("name", testSecret{value: "new", version: "v2", enabled: true})
+	if v, err := s.GetInto("name", ""); err != nil || v != "old" {
+		t.Fatalf("got %v,%+v", v, err)
+	}
+	latest := NewWithClient(s.client, "")
+	if v, err := latest.GetInto("name", ""); err != nil || v != "new" {
+		t.Fatalf("got latest %v,%+v", v, err)
+	}
+}
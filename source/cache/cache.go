@@ -0,0 +1,170 @@
+// Package cache is a config source that caches values read from another source,
+// so that slow sources are not read on every Load() or Reload()
+package cache
+
+import (
+	"container/list"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-msvc/config"
+)
+
+// New creates a source that caches values read from inner for ttl
+func New(inner config.Source, ttl time.Duration) *Cache {
+	return NewLRU(inner, ttl, 0)
+}
+
+// NewLRU is New() with at most maxEntries cached values,
+// evicting the least recently used when full
+// maxEntries <= 0 means no limit
+func NewLRU(inner config.Source, ttl time.Duration, maxEntries int) *Cache {
+	if inner == nil {
+		panic("cache.New(nil)")
+	}
+	if ttl <= 0 {
+		panic("cache.New() with ttl <= 0")
+	}
+	return &Cache{
+		inner:      inner,
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    map[entryKey]*list.Element{},
+		lru:        list.New(),
+		now:        time.Now,
+	}
+} //NewLRU()
+
+// Cache implements config.Source and config.CacheableSource
+type Cache struct {
+	inner      config.Source
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mutex   sync.RWMutex
+	entries map[entryKey]*list.Element
+	lru     *list.List //front is most recently used
+	stats   CacheStats
+}
+
+// CacheStats counts cache lookups since the cache was created
+type CacheStats struct {
+	Hits      int
+	Misses    int
+	Evictions int
+}
+
+// values are cached per name and template type,
+// because the inner source returns a value of the template type
+type entryKey struct {
+	name string
+	t    reflect.Type
+}
+
+type cachedEntry struct {
+	key       entryKey
+	value     interface{}
+	fetchedAt time.Time
+}
+
+// GetInto returns the cached value if it is not older than the ttl,
+// else reads it from the inner source
+// errors are not cached, but nil values (not configured) are
+func (c *Cache) GetInto(name string, tmpl interface{}) (interface{}, error) {
+	key := entryKey{name: name, t: reflect.TypeOf(tmpl)}
+	if value, ok := c.cached(key); ok {
+		return value, nil
+	}
+	value, err := c.inner.GetInto(name, tmpl)
+	if err != nil {
+		return nil, err
+	}
+	c.store(key, value)
+	return value, nil
+}
+
+func (c *Cache) cached(key entryKey) (interface{}, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if e, ok := c.entries[key]; ok {
+		entry := e.Value.(*cachedEntry)
+		if c.now().Sub(entry.fetchedAt) < c.ttl {
+			c.lru.MoveToFront(e)
+			c.stats.Hits++
+			return entry.value, true
+		}
+		c.remove(e)
+	}
+	c.stats.Misses++
+	return nil, false
+}
+
+func (c *Cache) store(key entryKey, value interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if e, ok := c.entries[key]; ok {
+		c.remove(e)
+	}
+	c.entries[key] = c.lru.PushFront(&cachedEntry{key: key, value: value, fetchedAt: c.now()})
+	for c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Back())
+		c.stats.Evictions++
+	}
+}
+
+// remove must be called with the mutex locked
+func (c *Cache) remove(e *list.Element) {
+	c.lru.Remove(e)
+	delete(c.entries, e.Value.(*cachedEntry).key)
+}
+
+// Invalidate removes cached values of name, its parents and its children,
+// e.g. "db.host" removes "db", "db.host" and "db.host.x",
+// so that they are read again from the inner source
+// call it when you know the value changed in the inner source, before config.Reload()
+func (c *Cache) Invalidate(name string) {
+	c.FlushCacheFor(name)
+}
+
+// FlushCacheFor is Invalidate() that returns the nr of cached values removed
+// it implements config.CacheableSource with FlushCache()
+func (c *Cache) FlushCacheFor(name string) int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	n := 0
+	for key, e := range c.entries {
+		if related(key.name, name) {
+			c.remove(e)
+			n++
+		}
+	}
+	return n
+}
+
+// related is true if a and b are the same name or one is a parent of the other
+func related(a, b string) bool {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	return a == "" || a == b || strings.HasPrefix(b, a+".")
+}
+
+// FlushCache removes all cached values and returns the nr removed
+func (c *Cache) FlushCache() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	n := c.lru.Len()
+	c.entries = map[entryKey]*list.Element{}
+	c.lru.Init()
+	return n
+}
+
+// Stats returns the cache hit, miss and eviction counts
+func (c *Cache) Stats() CacheStats {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.stats
+}
@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-msvc/config"
+)
+
+// countingSource returns values from a map and counts reads
+type countingSource struct {
+	values map[string]interface{}
+	reads  int
+}
+
+func (s *countingSource) GetInto(name string, tmpl interface{}) (interface{}, error) {
+	s.reads++
+	return s.values[name], nil
+}
+
+func newTestCache(t *testing.T, maxEntries int) (*Cache, *countingSource, *time.Time) {
+	inner := &countingSource{values: map[string]interface{}{"a": "1", "b": "2", "c": "3"}}
+	c := NewLRU(inner, time.Minute, maxEntries)
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	return c, inner, &now
+}
+
+func get(t *testing.T, c *Cache, name string) interface{} {
+	t.Helper()
+	value, err := c.GetInto(name, "")
+	if err != nil {
+		t.Fatalf("failed to get %s: %+v", name, err)
+	}
+	return value
+}
+
+func TestTTL(t *testing.T) {
+	c, inner, now := newTestCache(t, 0)
+	get(t, c, "a")
+	inner.values["a"] = "changed"
+	if v := get(t, c, "a"); v != "1" || inner.reads != 1 {
+		t.Fatalf("got %v after %d reads", v, inner.reads)
+	}
+	*now = now.Add(time.Minute)
+	if v := get(t, c, "a"); v != "changed" || inner.reads != 2 {
+		t.Fatalf("got %v after %d reads", v, inner.reads)
+	}
+	if s := c.Stats(); s.Hits != 1 || s.Misses != 2 {
+		t.Fatalf("stats %+v", s)
+	}
+}
+
+func TestInvalidate(t *testing.T) {
+	c, inner, _ := newTestCache(t, 0)
+	inner.values["db"] = map[string]interface{}{"host": "x"}
+	get(t, c, "db")
+	get(t, c, "a")
+	c.Invalidate("db.host")
+	get(t, c, "db")
+	get(t, c, "a")
+	if inner.reads != 3 {
+		t.Fatalf("%d reads", inner.reads)
+	}
+	if n := c.FlushCache(); n != 2 {
+		t.Fatalf("flushed %d", n)
+	}
+	get(t, c, "a")
+	if inner.reads != 4 {
+		t.Fatalf("%d reads after flush", inner.reads)
+	}
+}
+
+func TestFlushCacheFor(t *testing.T) {
+	c, inner, _ := newTestCache(t, 0)
+	inner.values["db"] = map[string]interface{}{"host": "x"}
+	get(t, c, "db")
+	get(t, c, "a")
+	var cs config.CacheableSource = c
+	if n := cs.FlushCacheFor("db.host"); n != 1 {
+		t.Fatalf("flushed %d", n)
+	}
+	if n := cs.FlushCacheFor("db.host"); n != 0 {
+		t.Fatalf("flushed %d again", n)
+	}
+}
+
+func TestLRU(t *testing.T) {
+	c, inner, _ := newTestCache(t, 2)
+	get(t, c, "a")
+	get(t, c, "b")
+	get(t, c, "a") //b is now least recently used
+	get(t, c, "c") //evicts b
+	if s := c.Stats(); s.Evictions != 1 {
+		t.Fatalf("stats %+v", s)
+	}
+	get(t, c, "a")
+	if inner.reads != 3 {
+		t.Fatalf("a was evicted: %d reads", inner.reads)
+	}
+	get(t, c, "b")
+	if inner.reads != 4 {
+		t.Fatalf("b was not evicted: %d reads", inner.reads)
+	}
+}
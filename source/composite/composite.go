@@ -0,0 +1,125 @@
+// Package composite is a config source that combines other sources,
+// e.g. composite.NewLastWin(fileSource, envSource) so that env values always
+// override the values in the file
+//
+// unlike config.WithMergeStrategy(), which combines sources added to the config,
+// this combines sources into one source, e.g. to add it with config.Sub() or prefix.New()
+package composite
+
+import (
+	"context"
+	"reflect"
+	"sort"
+
+	"github.com/go-msvc/config"
+	"github.com/go-msvc/data"
+	"github.com/go-msvc/errors"
+)
+
+// NewLastWin creates a source where values from later sources override
+// values from earlier sources
+func NewLastWin(sources ...config.Source) *Source {
+	return newSource(config.LastWinStrategy, sources)
+}
+
+// NewDeepMerge creates a source where objects from all sources are merged,
+// with values from later sources overriding the same values from earlier sources
+// other values, like strings and lists, are used from the last source that has it
+func NewDeepMerge(sources ...config.Source) *Source {
+	return newSource(config.DeepMergeStrategy, sources)
+}
+
+func newSource(ms config.MergeStrategy, sources []config.Source) *Source {
+	if len(sources) == 0 {
+		panic("composite source without sources")
+	}
+	for i, s := range sources {
+		if s == nil {
+			panic(errors.Errorf("composite source[%d] is nil", i))
+		}
+	}
+	return &Source{
+		sources:       append([]config.Source{}, sources...),
+		mergeStrategy: ms,
+	}
+}
+
+// Source implements config.Source and config.ListableSource
+type Source struct {
+	sources       []config.Source
+	mergeStrategy config.MergeStrategy
+}
+
+func (s *Source) GetInto(name string, tmpl interface{}) (interface{}, error) {
+	//objects are merged as maps, so that fields not configured in a source
+	//do not replace the values from other sources
+	convert := isObject(tmpl)
+	var value interface{}
+	for i, source := range s.sources {
+		readTmpl := tmpl
+		if convert {
+			readTmpl = map[string]interface{}{} //new map for each source, as GetInto() may fill it
+		}
+		v, err := source.GetInto(name, readTmpl)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get composite source[%d].config(%s)", i, name)
+		}
+		if v == nil {
+			continue
+		}
+		if value == nil {
+			value = v
+		} else {
+			value = s.mergeStrategy(value, v)
+		}
+	}
+	if value == nil || !convert {
+		return value, nil
+	}
+	converted, err := data.GetInto(value, "", tmpl)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid merged composite config(%s)", name)
+	}
+	return converted, nil
+} //Source.GetInto()
+
+// Keys returns the sorted keys of all sources that are config.ListableSource
+func (s *Source) Keys() []string {
+	keys := []string{}
+	found := map[string]bool{}
+	for _, source := range s.sources {
+		listable, ok := source.(config.ListableSource)
+		if !ok {
+			continue
+		}
+		for _, key := range listable.Keys() {
+			if !found[key] {
+				found[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// HealthCheck checks all sources that are config.HealthChecker
+func (s *Source) HealthCheck(ctx context.Context) error {
+	for i, source := range s.sources {
+		if checker, ok := source.(config.HealthChecker); ok {
+			if err := checker.HealthCheck(ctx); err != nil {
+				return errors.Wrapf(err, "composite source[%d] is not healthy", i)
+			}
+		}
+	}
+	return nil
+}
+
+// isObject is true for struct and map templates
+func isObject(tmpl interface{}) bool {
+	t := reflect.TypeOf(tmpl)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t != nil && (t.Kind() == reflect.Struct || t.Kind() == reflect.Map)
+}
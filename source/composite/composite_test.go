@@ -0,0 +1,82 @@
+package composite_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-msvc/config"
+	"github.com/go-msvc/config/source/composite"
+	"github.com/go-msvc/config/source/mock"
+)
+
+type dbConfig struct {
+	Host    string                 `json:"host"`
+	Port    int                    `json:"port"`
+	Options map[string]interface{} `json:"options"`
+}
+
+func load(t *testing.T, s config.Source) *config.Config {
+	t.Helper()
+	c := config.New()
+	c.MustConfigure("db", dbConfig{})
+	c.MustConfigure("name", "")
+	c.Optional("other", "none")
+	if err := c.AddSource("composite", s); err != nil {
+		t.Fatalf("failed to add source: %+v", err)
+	}
+	if err := c.Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	return c
+}
+
+func testSources() (file, env *mock.MockSource) {
+	file = mock.New().
+		Permanent("db", map[string]interface{}{"host": "file-db", "port": 5432, "options": map[string]interface{}{"ssl": "disable", "timeout": 5}}).
+		Permanent("name", "file")
+	env = mock.New().
+		Permanent("db", map[string]interface{}{"host": "env-db", "options": map[string]interface{}{"ssl": "require"}})
+	return file, env
+}
+
+func TestLastWin(t *testing.T) {
+	file, env := testSources()
+	c := load(t, composite.NewLastWin(file, env))
+	expected := dbConfig{Host: "env-db", Options: map[string]interface{}{"ssl": "require"}}
+	if db := c.Get("db").(dbConfig); !reflect.DeepEqual(db, expected) {
+		t.Fatalf("db %+v", db)
+	}
+	if name := c.Get("name"); name != "file" {
+		t.Fatalf("name %v", name)
+	}
+}
+
+func TestDeepMerge(t *testing.T) {
+	file, env := testSources()
+	c := load(t, composite.NewDeepMerge(file, env))
+	expected := dbConfig{Host: "env-db", Port: 5432, Options: map[string]interface{}{"ssl": "require", "timeout": float64(5)}}
+	if db := c.Get("db").(dbConfig); !reflect.DeepEqual(db, expected) {
+		t.Fatalf("db %+v", db)
+	}
+	if name := c.Get("name"); name != "file" {
+		t.Fatalf("name %v", name)
+	}
+}
+
+type listableSource struct {
+	*mock.MockSource
+	keys []string
+}
+
+func (s listableSource) Keys() []string { return s.keys }
+
+func TestKeys(t *testing.T) {
+	s := composite.NewLastWin(
+		listableSource{MockSource: mock.New(), keys: []string{"name", "db.host"}},
+		mock.New(),
+		listableSource{MockSource: mock.New(), keys: []string{"db.host", "db.port"}},
+	)
+	if keys, expected := s.Keys(), []string{"db.host", "db.port", "name"}; !reflect.DeepEqual(keys, expected) {
+		t.Fatalf("keys %v", keys)
+	}
+}
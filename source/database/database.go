@@ -0,0 +1,163 @@
+// Package database is a config source that reads values from a SQL table
+// with one row per config value, e.g.:
+//
+//	CREATE TABLE config (key TEXT PRIMARY KEY, value TEXT);
+//	INSERT INTO config VALUES ('ms.server', '{"http":{"addr":"localhost:8080"}}');
+//
+// the value column must contain JSON
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-msvc/config"
+	"github.com/go-msvc/data"
+	"github.com/go-msvc/errors"
+)
+
+// New creates a config source from a table in the database
+// table and column names are used in the SQL as is, so must not come from user input
+// queries use $1 placeholders, as supported by PostgreSQL and SQLite
+func New(db *sql.DB, table, keyCol, valueCol string) config.Source {
+	return NewWithTimeout(db, table, keyCol, valueCol, 0)
+}
+
+// NewWithTimeout is New() with a deadline on each query
+// queryTimeout 0 means no deadline
+func NewWithTimeout(db *sql.DB, table, keyCol, valueCol string, queryTimeout time.Duration) config.Source {
+	if db == nil {
+		panic("database.New(nil)")
+	}
+	return source{
+		db:           db,
+		name:         table,
+		queryTimeout: queryTimeout,
+		exactQuery:   fmt.Sprintf("SELECT %s FROM %s WHERE %s = $1", valueCol, table, keyCol),
+		prefixQuery:  fmt.Sprintf("SELECT %s,%s FROM %s WHERE %s LIKE $1 ESCAPE '\\'", keyCol, valueCol, table, keyCol),
+	}
+}
+
+type source struct {
+	db           *sql.DB
+	name         string
+	queryTimeout time.Duration
+	exactQuery   string
+	prefixQuery  string
+}
+
+// HealthCheck pings the database
+// it implements config.HealthChecker
+func (s source) HealthCheck(ctx context.Context) error {
+	if err := s.db.PingContext(ctx); err != nil {
+		return errors.Wrapf(err, "cannot reach database of %s", s.name)
+	}
+	return nil
+}
+
+// GetInto reads the row with key = name
+// if there is no such row, it reads all rows with keys starting with "<name>."
+// and combines them into an object, e.g. "ms.server.http.addr" -> {"http":{"addr":...}}
+// if there are no such rows either, it reads the value inside the row of the
+// nearest ancestor, e.g. "ms.server.http" from "ms.server" -> {"http":{...}}
+// it returns nil,nil if no rows were found
+func (s source) GetInto(name string, tmpl interface{}) (interface{}, error) {
+	ctx := context.Background()
+	if s.queryTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.queryTimeout)
+		defer cancel()
+	}
+
+	//exact match
+	var jsonValue string
+	err := s.db.QueryRowContext(ctx, s.exactQuery, name).Scan(&jsonValue)
+	if err == nil {
+		value, err := data.JsonInto([]byte(jsonValue), tmpl)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s(%s)", s.name, name)
+		}
+		return value, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, errors.Wrapf(err, "failed to query %s(%s)", s.name, name)
+	}
+
+	//hierarchical match on all keys under name
+	rows, err := s.db.QueryContext(ctx, s.prefixQuery, likeEscape(name)+".%")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to query %s(%s.*)", s.name, name)
+	}
+	defer rows.Close()
+	obj := map[string]interface{}{}
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key, &jsonValue); err != nil {
+			return nil, errors.Wrapf(err, "failed to read %s(%s.*)", s.name, name)
+		}
+		var value interface{}
+		if err := json.Unmarshal([]byte(jsonValue), &value); err != nil {
+			return nil, errors.Wrapf(err, "invalid %s(%s)", s.name, key)
+		}
+		setNested(obj, strings.Split(strings.TrimPrefix(key, name+"."), "."), value)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s(%s.*)", s.name, name)
+	}
+	if len(obj) > 0 {
+		value, err := data.GetInto(obj, "", tmpl)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s(%s.*)", s.name, name)
+		}
+		return value, nil
+	}
+
+	//match inside the value of the nearest ancestor, e.g. "ms.server.http"
+	//is in the row with key "ms.server" and value {"http":{...}}
+	names := strings.Split(name, ".")
+	for i := len(names) - 1; i > 0; i-- {
+		ancestor := strings.Join(names[:i], ".")
+		err := s.db.QueryRowContext(ctx, s.exactQuery, ancestor).Scan(&jsonValue)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to query %s(%s)", s.name, ancestor)
+		}
+		var ancestorValue interface{}
+		if err := json.Unmarshal([]byte(jsonValue), &ancestorValue); err != nil {
+			return nil, errors.Wrapf(err, "invalid %s(%s)", s.name, ancestor)
+		}
+		rest := strings.Join(names[i:], ".")
+		if _, err := data.Get(ancestorValue, rest); err != nil {
+			return nil, nil //not configured in the nearest ancestor
+		}
+		value, err := data.GetInto(ancestorValue, rest, tmpl)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s(%s).%s", s.name, ancestor, rest)
+		}
+		return value, nil
+	}
+	return nil, nil //not configured
+} //source.GetInto()
+
+func setNested(obj map[string]interface{}, names []string, value interface{}) {
+	for _, name := range names[:len(names)-1] {
+		sub, ok := obj[name].(map[string]interface{})
+		if !ok {
+			sub = map[string]interface{}{}
+			obj[name] = sub
+		}
+		obj = sub
+	}
+	obj[names[len(names)-1]] = value
+}
+
+// likeEscape escapes the LIKE wildcards that may appear in config names
+func likeEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,160 @@
+package database_test
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-msvc/config"
+	"github.com/go-msvc/config/source/database"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type serverConfig struct {
+	Addr string `json:"addr"`
+	Port int    `json:"port"`
+}
+
+func openTestDB(t *testing.T, rows map[string]string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open db: %+v", err)
+	}
+	db.SetMaxOpenConns(1) //each connection to :memory: is a new database
+	t.Cleanup(func() { db.Close() })
+	if _, err := db.Exec("CREATE TABLE config (k TEXT PRIMARY KEY, v TEXT)"); err != nil {
+		t.Fatalf("failed to create table: %+v", err)
+	}
+	for k, v := range rows {
+		if _, err := db.Exec("INSERT INTO config VALUES ($1, $2)", k, v); err != nil {
+			t.Fatalf("failed to insert: %+v", err)
+		}
+	}
+	return db
+}
+
+func TestGetInto(t *testing.T) {
+	db := openTestDB(t, map[string]string{
+		"ms.server":      `{"http":{"addr":"localhost","port":8080}}`,
+		"ms.db.addr":     `"db1"`,
+		"ms.db.port":     `5432`,
+		"ms.name":        `"test"`,
+		"ms_x.name":      `"not a child of ms.x"`,
+		"ms.invalid":     `{`,
+		"other.settings": `{"level":"debug"}`,
+	})
+	s := database.New(db, "config", "k", "v")
+	tests := map[string]struct {
+		tmpl     interface{}
+		expected interface{}
+	}{
+		"ms.name":                  {"", "test"},
+		"ms.db":                    {serverConfig{}, serverConfig{Addr: "db1", Port: 5432}},
+		"ms.server.http":           {serverConfig{}, serverConfig{Addr: "localhost", Port: 8080}},
+		"ms.server.http.port":      {0, 8080},
+		"ms.server.https":          {serverConfig{}, nil},
+		"ms.x":                     {map[string]interface{}{}, nil},
+		"other.settings.level":     {"", "debug"},
+		"unknown":                  {"", nil},
+		"unknown.with.many.levels": {"", nil},
+	}
+	for name, test := range tests {
+		value, err := s.GetInto(name, test.tmpl)
+		if err != nil {
+			t.Fatalf("%s failed: %+v", name, err)
+		}
+		if !reflect.DeepEqual(value, test.expected) {
+			t.Fatalf("%s got %#v, expected %#v", name, value, test.expected)
+		}
+	}
+	if _, err := s.GetInto("ms.invalid", map[string]interface{}{}); err == nil {
+		t.Fatalf("invalid JSON did not fail")
+	}
+}
+
+func TestPolling(t *testing.T) {
+	db := openTestDB(t, map[string]string{"ms.name": `"a"`})
+	s, err := database.NewPolling(db, "config", "k", "v", 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("failed: %+v", err)
+	}
+	defer s.Close()
+	config.MustConfigure("ms.name", "")
+	if err := config.AddSource("db", s); err != nil {
+		t.Fatalf("failed to add source: %+v", err)
+	}
+	if err := config.Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	changed := make(chan interface{}, 1)
+	defer config.Watch("ms.name", func(v interface{}) { changed <- v })()
+
+	if _, err := db.Exec("UPDATE config SET v=$1 WHERE k=$2", `"b"`, "ms.name"); err != nil {
+		t.Fatalf("failed to update: %+v", err)
+	}
+	select {
+	case v := <-changed:
+		if v != "b" {
+			t.Fatalf("watcher got %v", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("not reloaded after table changed")
+	}
+	if config.Get("ms.name") != "b" {
+		t.Fatalf("ms.name=%v", config.Get("ms.name"))
+	}
+
+	if _, err := database.NewPolling(db, "config", "k", "v", 0); err == nil {
+		t.Fatalf("invalid interval did not fail")
+	}
+}
+
+func TestPollingWithConfig(t *testing.T) {
+	db := openTestDB(t, map[string]string{"ms.name": `"a"`})
+	c := config.New()
+	s, err := database.NewPolling(db, "config", "k", "v", 10*time.Millisecond, database.WithConfig(c))
+	if err != nil {
+		t.Fatalf("failed: %+v", err)
+	}
+	defer s.Close()
+	c.MustConfigure("ms.name", "")
+	if err := c.AddSource("db", s); err != nil {
+		t.Fatalf("failed to add source: %+v", err)
+	}
+	if err := c.Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	changed := make(chan interface{}, 1)
+	defer c.Watch("ms.name", func(v interface{}) { changed <- v })()
+
+	if _, err := db.Exec("UPDATE config SET v=$1 WHERE k=$2", `"b"`, "ms.name"); err != nil {
+		t.Fatalf("failed to update: %+v", err)
+	}
+	select {
+	case v := <-changed:
+		if v != "b" {
+			t.Fatalf("watcher got %v", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("config not reloaded after table changed")
+	}
+}
+
+func TestHealthCheck(t *testing.T) {
+	db := openTestDB(t, nil)
+	s := database.New(db, "config", "k", "v")
+	hc, ok := s.(config.HealthChecker)
+	if !ok {
+		t.Fatalf("%T is not a config.HealthChecker", s)
+	}
+	if err := hc.HealthCheck(context.Background()); err != nil {
+		t.Fatalf("unhealthy: %+v", err)
+	}
+	db.Close()
+	if err := hc.HealthCheck(context.Background()); err == nil {
+		t.Fatalf("healthy after close")
+	}
+}
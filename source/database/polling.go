@@ -0,0 +1,137 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-msvc/config"
+	"github.com/go-msvc/errors"
+)
+
+// NewPolling is New() that reads the whole table every interval
+// and calls config.Reload() when anything in the table changed,
+// so that config.Watch() callbacks are called for the changed values
+// call Close() to stop polling
+func NewPolling(db *sql.DB, table, keyCol, valueCol string, interval time.Duration, opts ...PollingOption) (*PollingSource, error) {
+	if interval <= 0 {
+		return nil, errors.Errorf("%s: invalid polling interval %v", table, interval)
+	}
+	s := &PollingSource{
+		Source:    New(db, table, keyCol, valueCol),
+		db:        db,
+		name:      table,
+		interval:  interval,
+		listQuery: fmt.Sprintf("SELECT %s,%s FROM %s ORDER BY %s", keyCol, valueCol, table, keyCol),
+		stop:      make(chan struct{}),
+		config:    config.Default(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	hash, err := s.tableHash(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	s.hash = hash
+	go s.poll()
+	return s, nil
+} //NewPolling()
+
+// PollingOption changes how NewPolling() creates the source
+type PollingOption func(*PollingSource)
+
+// WithConfig reloads c when the table changed, instead of the default config
+func WithConfig(c *config.Config) PollingOption {
+	return func(s *PollingSource) {
+		s.config = c
+	}
+}
+
+// PollingSource implements config.Source
+type PollingSource struct {
+	config.Source
+	db        *sql.DB
+	name      string
+	interval  time.Duration
+	listQuery string
+	hash      string
+	stop      chan struct{}
+	closeOnce sync.Once
+	config    *config.Config //reloaded when the table changed
+}
+
+// HealthCheck pings the database
+// it implements config.HealthChecker
+func (s *PollingSource) HealthCheck(ctx context.Context) error {
+	return s.Source.(config.HealthChecker).HealthCheck(ctx)
+}
+
+// Close stops polling
+func (s *PollingSource) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.stop)
+	})
+	return nil
+}
+
+func (s *PollingSource) poll() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.reloadIfChanged(context.Background()); err != nil {
+				config.Logger().Errorf("%+v", err)
+			}
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// reloadIfChanged reloads config if the table changed since the last poll
+// the table is only remembered as changed once config was reloaded,
+// so that a failed reload is tried again on the next poll
+func (s *PollingSource) reloadIfChanged(ctx context.Context) error {
+	hash, err := s.tableHash(ctx)
+	if err != nil {
+		return err
+	}
+	if hash == s.hash {
+		return nil
+	}
+	if s.config.Version() == 0 {
+		return nil //not yet loaded
+	}
+	config.Logger().Debugf("%s changed, reloading config", s.name)
+	if err := s.config.Reload(); err != nil {
+		return err
+	}
+	s.hash = hash
+	return nil
+}
+
+// tableHash returns a hash of all rows in the table
+func (s *PollingSource) tableHash(ctx context.Context) (string, error) {
+	rows, err := s.db.QueryContext(ctx, s.listQuery)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to query %s", s.name)
+	}
+	defer rows.Close()
+	h := sha256.New()
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return "", errors.Wrapf(err, "failed to read %s", s.name)
+		}
+		fmt.Fprintf(h, "%d:%s%d:%s", len(key), key, len(value), value)
+	}
+	if err := rows.Err(); err != nil {
+		return "", errors.Wrapf(err, "failed to read %s", s.name)
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
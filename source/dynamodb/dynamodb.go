@@ -0,0 +1,328 @@
+// Package dynamodb is a config source that reads each config name from
+// an item in an AWS DynamoDB table, e.g. with key attribute "name" and
+// value attribute "value", config "db" is the JSON in the "value" string
+// of the item with "name" = "db"
+//
+// values that are not valid JSON are used as strings
+package dynamodb
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	ddb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams"
+	streamtypes "github.com/aws/aws-sdk-go-v2/service/dynamodbstreams/types"
+	"github.com/go-msvc/config"
+	"github.com/go-msvc/data"
+	"github.com/go-msvc/errors"
+)
+
+// Client is the part of the DynamoDB client used by this source
+// so tests can use a mock instead of *dynamodb.Client
+type Client interface {
+	GetItem(ctx context.Context, params *ddb.GetItemInput, optFns ...func(*ddb.Options)) (*ddb.GetItemOutput, error)
+	PutItem(ctx context.Context, params *ddb.PutItemInput, optFns ...func(*ddb.Options)) (*ddb.PutItemOutput, error)
+	DeleteItem(ctx context.Context, params *ddb.DeleteItemInput, optFns ...func(*ddb.Options)) (*ddb.DeleteItemOutput, error)
+}
+
+// StreamsClient is the part of the DynamoDB Streams client used by NewWithStream()
+// so tests can use a mock instead of *dynamodbstreams.Client
+type StreamsClient interface {
+	DescribeStream(ctx context.Context, params *dynamodbstreams.DescribeStreamInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.DescribeStreamOutput, error)
+	GetShardIterator(ctx context.Context, params *dynamodbstreams.GetShardIteratorInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.GetShardIteratorOutput, error)
+	GetRecords(ctx context.Context, params *dynamodbstreams.GetRecordsInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.GetRecordsOutput, error)
+}
+
+// New reads items from tableName using the default AWS credentials
+// keyAttr is the string partition key with the config name
+// and valueAttr the string attribute with the JSON value
+func New(region, tableName, keyAttr, valueAttr string, opts ...Option) (*Source, error) {
+	cfg, err := loadAWSConfig(region)
+	if err != nil {
+		return nil, errors.Wrapf(err, "dynamodb(%s)", tableName)
+	}
+	return NewWithClient(ddb.NewFromConfig(cfg), tableName, keyAttr, valueAttr, opts...), nil
+}
+
+// NewWithClient is New() using the specified client
+func NewWithClient(client Client, tableName, keyAttr, valueAttr string, opts ...Option) *Source {
+	if client == nil {
+		panic("dynamodb.NewWithClient(nil)")
+	}
+	if tableName == "" || keyAttr == "" || valueAttr == "" {
+		panic("dynamodb.NewWithClient() needs table name, key and value attributes")
+	}
+	s := &Source{
+		client:    client,
+		tableName: tableName,
+		keyAttr:   keyAttr,
+		valueAttr: valueAttr,
+		reload:    config.Reload,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Option changes how the source is created
+type Option func(*Source)
+
+// WithConfig makes WatchStream() reload c instead of the default config
+func WithConfig(c *config.Config) Option {
+	return WithReload(c.Reload)
+}
+
+// WithReload makes WatchStream() call reload instead of config.Reload()
+func WithReload(reload func() error) Option {
+	return func(s *Source) {
+		s.reload = reload
+	}
+}
+
+// NewWithStream is New() that reads the DynamoDB stream of the table every interval
+// and calls config.Reload() when any item changed, so that config.Watch()
+// callbacks are called for the changed values
+// only changes made after it started are seen
+// call Close() to stop polling
+func NewWithStream(region, tableName, keyAttr, valueAttr, streamARN string, interval time.Duration, opts ...Option) (*Source, error) {
+	cfg, err := loadAWSConfig(region)
+	if err != nil {
+		return nil, errors.Wrapf(err, "dynamodb(%s)", tableName)
+	}
+	s := NewWithClient(ddb.NewFromConfig(cfg), tableName, keyAttr, valueAttr, opts...)
+	if err := s.WatchStream(dynamodbstreams.NewFromConfig(cfg), streamARN, interval); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func loadAWSConfig(region string) (aws.Config, error) {
+	cfg, err := awsconfig.LoadDefaultConfig(context.Background(), awsconfig.WithRegion(region))
+	if err != nil {
+		return aws.Config{}, errors.Wrapf(err, "cannot load AWS config")
+	}
+	return cfg, nil
+}
+
+// Source implements config.Source and config.WritableSource
+type Source struct {
+	client    Client
+	tableName string
+	keyAttr   string
+	valueAttr string
+	reload    func() error
+
+	streamMutex     sync.Mutex
+	streams         StreamsClient
+	streamARN       string
+	iteratorByShard map[string]*string //nil when the shard is closed
+	stop            chan struct{}
+	closeOnce       sync.Once
+}
+
+func (s *Source) GetInto(name string, tmpl interface{}) (interface{}, error) {
+	out, err := s.client.GetItem(context.Background(), &ddb.GetItemInput{
+		TableName:      aws.String(s.tableName),
+		Key:            s.key(name),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get dynamodb(%s).item(%s)", s.tableName, name)
+	}
+	if out.Item == nil {
+		return nil, nil //not configured
+	}
+	attr, ok := out.Item[s.valueAttr]
+	if !ok {
+		return nil, nil
+	}
+	str, ok := attr.(*ddbtypes.AttributeValueMemberS)
+	if !ok {
+		return nil, errors.Errorf("dynamodb(%s).item(%s).%s is %T, expecting a string", s.tableName, name, s.valueAttr, attr)
+	}
+	v, err := data.GetInto(map[string]interface{}{"value": parseValue(str.Value)}, "value", tmpl)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid dynamodb(%s).item(%s)", s.tableName, name)
+	}
+	return v, nil
+} //Source.GetInto()
+
+// Set writes value as JSON in the item of name
+// it implements config.WritableSource
+func (s *Source) Set(name string, value interface{}) error {
+	jsonValue, err := json.Marshal(value)
+	if err != nil {
+		return errors.Wrapf(err, "cannot encode dynamodb(%s).item(%s)", s.tableName, name)
+	}
+	item := s.key(name)
+	item[s.valueAttr] = &ddbtypes.AttributeValueMemberS{Value: string(jsonValue)}
+	if _, err := s.client.PutItem(context.Background(), &ddb.PutItemInput{
+		TableName: aws.String(s.tableName),
+		Item:      item,
+	}); err != nil {
+		return errors.Wrapf(err, "failed to put dynamodb(%s).item(%s)", s.tableName, name)
+	}
+	return nil
+}
+
+// Delete deletes the item of name, it is not an error if it does not exist
+// it implements config.WritableSource
+func (s *Source) Delete(name string) error {
+	if _, err := s.client.DeleteItem(context.Background(), &ddb.DeleteItemInput{
+		TableName: aws.String(s.tableName),
+		Key:       s.key(name),
+	}); err != nil {
+		return errors.Wrapf(err, "failed to delete dynamodb(%s).item(%s)", s.tableName, name)
+	}
+	return nil
+}
+
+func (s *Source) key(name string) map[string]ddbtypes.AttributeValue {
+	return map[string]ddbtypes.AttributeValue{
+		s.keyAttr: &ddbtypes.AttributeValueMemberS{Value: name},
+	}
+}
+
+// parseValue returns the JSON value, or the string if it is not valid JSON
+func parseValue(s string) interface{} {
+	var value interface{}
+	if err := json.Unmarshal([]byte(s), &value); err == nil {
+		return value
+	}
+	return s
+}
+
+// WatchStream reads the DynamoDB stream every interval and calls config.Reload()
+// when any item changed, it may only be called once
+// call Close() to stop
+func (s *Source) WatchStream(streams StreamsClient, streamARN string, interval time.Duration) error {
+	if streams == nil {
+		panic("dynamodb.WatchStream(nil)")
+	}
+	if interval <= 0 {
+		return errors.Errorf("dynamodb(%s): invalid stream interval %v", s.tableName, interval)
+	}
+	s.streamMutex.Lock()
+	if s.streams != nil {
+		s.streamMutex.Unlock()
+		panic("dynamodb.WatchStream() called more than once")
+	}
+	s.streams = streams
+	s.streamARN = streamARN
+	s.iteratorByShard = map[string]*string{}
+	s.streamMutex.Unlock()
+
+	//start at the end of existing shards, so only new changes are seen
+	if _, err := s.pollStream(context.Background(), streamtypes.ShardIteratorTypeLatest); err != nil {
+		return err
+	}
+	s.stop = make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				names, err := s.pollStream(context.Background(), streamtypes.ShardIteratorTypeTrimHorizon)
+				if err != nil {
+					config.Logger().Errorf("%+v", err)
+					continue
+				}
+				if len(names) > 0 {
+					config.Logger().Debugf("Changed dynamodb(%s).items%v", s.tableName, names)
+					if err := s.reload(); err != nil {
+						config.Logger().Errorf("failed to reload after dynamodb(%s) changed: %+v", s.tableName, err)
+					}
+				}
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+	return nil
+} //Source.WatchStream()
+
+// pollStream reads the new records of all shards and returns the sorted names of changed items
+// shards that were not yet seen start at newShardType
+func (s *Source) pollStream(ctx context.Context, newShardType streamtypes.ShardIteratorType) ([]string, error) {
+	s.streamMutex.Lock()
+	defer s.streamMutex.Unlock()
+
+	//find new shards, e.g. when a shard was split or closed
+	var lastShardID *string
+	for {
+		out, err := s.streams.DescribeStream(ctx, &dynamodbstreams.DescribeStreamInput{
+			StreamArn:             aws.String(s.streamARN),
+			ExclusiveStartShardId: lastShardID,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to describe dynamodb(%s).stream", s.tableName)
+		}
+		if out.StreamDescription == nil {
+			break
+		}
+		for _, shard := range out.StreamDescription.Shards {
+			shardID := aws.ToString(shard.ShardId)
+			if _, ok := s.iteratorByShard[shardID]; ok {
+				continue
+			}
+			it, err := s.streams.GetShardIterator(ctx, &dynamodbstreams.GetShardIteratorInput{
+				StreamArn:         aws.String(s.streamARN),
+				ShardId:           shard.ShardId,
+				ShardIteratorType: newShardType,
+			})
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to get dynamodb(%s).stream.shard(%s)", s.tableName, shardID)
+			}
+			s.iteratorByShard[shardID] = it.ShardIterator
+		}
+		lastShardID = out.StreamDescription.LastEvaluatedShardId
+		if lastShardID == nil {
+			break
+		}
+	}
+
+	changed := map[string]bool{}
+	for shardID, iterator := range s.iteratorByShard {
+		if iterator == nil {
+			continue //closed
+		}
+		out, err := s.streams.GetRecords(ctx, &dynamodbstreams.GetRecordsInput{ShardIterator: iterator})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get dynamodb(%s).stream.shard(%s) records", s.tableName, shardID)
+		}
+		s.iteratorByShard[shardID] = out.NextShardIterator
+		for _, record := range out.Records {
+			if record.Dynamodb == nil {
+				continue
+			}
+			if key, ok := record.Dynamodb.Keys[s.keyAttr].(*streamtypes.AttributeValueMemberS); ok {
+				changed[key.Value] = true
+			}
+		}
+	}
+	names := make([]string, 0, len(changed))
+	for name := range changed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+} //Source.pollStream()
+
+// Close stops reading the stream
+func (s *Source) Close() error {
+	s.closeOnce.Do(func() {
+		if s.stop != nil {
+			close(s.stop)
+		}
+	})
+	return nil
+}
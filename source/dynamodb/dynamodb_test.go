@@ -0,0 +1,172 @@
+package dynamodb
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ddb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams"
+	streamtypes "github.com/aws/aws-sdk-go-v2/service/dynamodbstreams/types"
+	"github.com/go-msvc/config"
+)
+
+// testClient keeps the items of one table with key "name" in memory
+type testClient struct {
+	sync.Mutex
+	itemByName map[string]map[string]ddbtypes.AttributeValue
+}
+
+func newTestClient(valueByName map[string]string) *testClient {
+	c := &testClient{itemByName: map[string]map[string]ddbtypes.AttributeValue{}}
+	for name, value := range valueByName {
+		c.itemByName[name] = map[string]ddbtypes.AttributeValue{
+			"name":  &ddbtypes.AttributeValueMemberS{Value: name},
+			"value": &ddbtypes.AttributeValueMemberS{Value: value},
+		}
+	}
+	return c
+}
+
+func keyName(key map[string]ddbtypes.AttributeValue) string {
+	return key["name"].(*ddbtypes.AttributeValueMemberS).Value
+}
+
+func (c *testClient) GetItem(ctx context.Context, params *ddb.GetItemInput, optFns ...func(*ddb.Options)) (*ddb.GetItemOutput, error) {
+	c.Lock()
+	defer c.Unlock()
+	return &ddb.GetItemOutput{Item: c.itemByName[keyName(params.Key)]}, nil
+}
+
+func (c *testClient) PutItem(ctx context.Context, params *ddb.PutItemInput, optFns ...func(*ddb.Options)) (*ddb.PutItemOutput, error) {
+	c.Lock()
+	defer c.Unlock()
+	c.itemByName[keyName(params.Item)] = params.Item
+	return &ddb.PutItemOutput{}, nil
+}
+
+func (c *testClient) DeleteItem(ctx context.Context, params *ddb.DeleteItemInput, optFns ...func(*ddb.Options)) (*ddb.DeleteItemOutput, error) {
+	c.Lock()
+	defer c.Unlock()
+	delete(c.itemByName, keyName(params.Key))
+	return &ddb.DeleteItemOutput{}, nil
+}
+
+type testDBConfig struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+func TestGetInto(t *testing.T) {
+	s := NewWithClient(newTestClient(map[string]string{
+		"db":   `{"host":"db1","port":5432}`,
+		"name": "shop",
+	}), "config", "name", "value")
+	if v, err := s.GetInto("db", testDBConfig{}); err != nil || v != (testDBConfig{Host: "db1", Port: 5432}) {
+		t.Fatalf("db %+v, %+v", v, err)
+	}
+	if v, err := s.GetInto("name", ""); err != nil || v != "shop" {
+		t.Fatalf("name %+v, %+v", v, err)
+	}
+	if v, err := s.GetInto("other", ""); err != nil || v != nil {
+		t.Fatalf("other %+v, %+v", v, err)
+	}
+	if _, err := s.GetInto("name", 0); err == nil {
+		t.Fatalf("got string as int")
+	}
+}
+
+func TestSetAndDelete(t *testing.T) {
+	s := NewWithClient(newTestClient(nil), "config", "name", "value")
+	if err := s.Set("db", testDBConfig{Host: "db2", Port: 1}); err != nil {
+		t.Fatalf("failed to set: %+v", err)
+	}
+	if v, err := s.GetInto("db", testDBConfig{}); err != nil || v != (testDBConfig{Host: "db2", Port: 1}) {
+		t.Fatalf("db %+v, %+v", v, err)
+	}
+	if err := s.Delete("db"); err != nil {
+		t.Fatalf("failed to delete: %+v", err)
+	}
+	if err := s.Delete("db"); err != nil {
+		t.Fatalf("failed to delete missing item: %+v", err)
+	}
+	if v, err := s.GetInto("db", testDBConfig{}); err != nil || v != nil {
+		t.Fatalf("deleted db %+v, %+v", v, err)
+	}
+	var _ config.WritableSource = s
+}
+
+// testStreams has one shard with records that are added by change()
+// iterators are the index of the next record
+type testStreams struct {
+	sync.Mutex
+	records []streamtypes.Record
+}
+
+func (c *testStreams) DescribeStream(ctx context.Context, params *dynamodbstreams.DescribeStreamInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.DescribeStreamOutput, error) {
+	return &dynamodbstreams.DescribeStreamOutput{StreamDescription: &streamtypes.StreamDescription{
+		Shards: []streamtypes.Shard{{ShardId: aws.String("shard-1")}},
+	}}, nil
+}
+
+func (c *testStreams) GetShardIterator(ctx context.Context, params *dynamodbstreams.GetShardIteratorInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.GetShardIteratorOutput, error) {
+	c.Lock()
+	defer c.Unlock()
+	start := 0
+	if params.ShardIteratorType == streamtypes.ShardIteratorTypeLatest {
+		start = len(c.records)
+	}
+	return &dynamodbstreams.GetShardIteratorOutput{ShardIterator: aws.String(strconv.Itoa(start))}, nil
+}
+
+func (c *testStreams) GetRecords(ctx context.Context, params *dynamodbstreams.GetRecordsInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.GetRecordsOutput, error) {
+	c.Lock()
+	defer c.Unlock()
+	start, _ := strconv.Atoi(aws.ToString(params.ShardIterator))
+	return &dynamodbstreams.GetRecordsOutput{
+		Records:           append([]streamtypes.Record{}, c.records[start:]...),
+		NextShardIterator: aws.String(strconv.Itoa(len(c.records))),
+	}, nil
+}
+
+func (c *testStreams) change(name string) {
+	c.Lock()
+	defer c.Unlock()
+	c.records = append(c.records, streamtypes.Record{Dynamodb: &streamtypes.StreamRecord{
+		Keys: map[string]streamtypes.AttributeValue{"name": &streamtypes.AttributeValueMemberS{Value: name}},
+	}})
+}
+
+func TestWatchStream(t *testing.T) {
+	streams := &testStreams{}
+	streams.change("old") //before watching, not seen
+	reloaded := make(chan struct{}, 10)
+	s := NewWithClient(newTestClient(nil), "config", "name", "value", WithReload(func() error {
+		reloaded <- struct{}{}
+		return nil
+	}))
+	if err := s.WatchStream(streams, "arn:stream", 10*time.Millisecond); err != nil {
+		t.Fatalf("failed to watch: %+v", err)
+	}
+	defer s.Close()
+
+	select {
+	case <-reloaded:
+		t.Fatalf("reloaded without change")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	streams.change("db")
+	select {
+	case <-reloaded:
+	case <-time.After(time.Second):
+		t.Fatalf("not reloaded after change")
+	}
+	if names, err := s.pollStream(context.Background(), streamtypes.ShardIteratorTypeTrimHorizon); err != nil || len(names) != 0 {
+		t.Fatalf("changes read again: %v, %+v", names, err)
+	}
+}
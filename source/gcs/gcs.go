@@ -0,0 +1,245 @@
+// Package gcs is a config source that reads a JSON object from Google Cloud Storage
+package gcs
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"cloud.google.com/go/storage"
+	"github.com/go-msvc/config"
+	"github.com/go-msvc/data"
+	"github.com/go-msvc/errors"
+	"google.golang.org/api/option"
+)
+
+// New downloads the JSON object gs://<bucket>/<object> using the default
+// Google credentials, unless other options are specified,
+// and returns a source for the values in it
+// values are kept in memory and not downloaded again
+func New(bucket, object string, opts ...option.ClientOption) (*Source, error) {
+	return newSource(config.Reload, bucket, object, opts...)
+}
+
+// newSource is New() that calls reload when the object changed
+func newSource(reload func() error, bucket, object string, opts ...option.ClientOption) (*Source, error) {
+	ctx := context.Background()
+	client, err := storage.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "gs://%s/%s", bucket, object)
+	}
+	s := &Source{
+		client: client,
+		bucket: bucket,
+		object: object,
+		reload: reload,
+	}
+	if _, err := s.fetchIfChanged(ctx); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return s, nil
+} //newSource()
+
+// NewPolling is New() that checks the object generation every interval
+// and downloads it again when it changed, then calls config.Reload()
+// so that config.Watch() callbacks are called for the changed values
+// call Close() to stop polling
+func NewPolling(bucket, object string, interval time.Duration, opts ...option.ClientOption) (*Source, error) {
+	return newPolling(config.Reload, bucket, object, interval, opts...)
+}
+
+// NewPollingWithConfig is NewPolling() that reloads c instead of the default config
+func NewPollingWithConfig(c *config.Config, bucket, object string, interval time.Duration, opts ...option.ClientOption) (*Source, error) {
+	return newPolling(c.Reload, bucket, object, interval, opts...)
+}
+
+func newPolling(reload func() error, bucket, object string, interval time.Duration, opts ...option.ClientOption) (*Source, error) {
+	if interval <= 0 {
+		return nil, errors.Errorf("gs://%s/%s: invalid polling interval %v", bucket, object, interval)
+	}
+	s, err := newSource(reload, bucket, object, opts...)
+	if err != nil {
+		return nil, err
+	}
+	s.stop = make(chan struct{})
+	go s.poll(interval)
+	return s, nil
+}
+
+// NewWithPubSub is New() that receives the Cloud Storage notifications
+// of the bucket from the Pub/Sub subscription, and downloads the object
+// again when it changed, then calls config.Reload()
+// the subscription must be on a topic with notifications of the bucket,
+// see "gsutil notification create"
+// the options are used for both Cloud Storage and Pub/Sub
+// call Close() to stop receiving
+func NewWithPubSub(bucket, object, projectID, subscriptionID string, opts ...option.ClientOption) (*Source, error) {
+	return newWithPubSub(config.Reload, bucket, object, projectID, subscriptionID, opts...)
+}
+
+// NewWithPubSubAndConfig is NewWithPubSub() that reloads c instead of the default config
+func NewWithPubSubAndConfig(c *config.Config, bucket, object, projectID, subscriptionID string, opts ...option.ClientOption) (*Source, error) {
+	return newWithPubSub(c.Reload, bucket, object, projectID, subscriptionID, opts...)
+}
+
+func newWithPubSub(reload func() error, bucket, object, projectID, subscriptionID string, opts ...option.ClientOption) (*Source, error) {
+	s, err := newSource(reload, bucket, object, opts...)
+	if err != nil {
+		return nil, err
+	}
+	pubsubClient, err := pubsub.NewClient(context.Background(), projectID, opts...)
+	if err != nil {
+		s.Close()
+		return nil, errors.Wrapf(err, "gs://%s/%s: cannot create Pub/Sub client", bucket, object)
+	}
+	s.pubsubClient = pubsubClient
+	s.receive(pubsubClient.Subscription(subscriptionID))
+	return s, nil
+} //newWithPubSub()
+
+// Source implements config.Source
+type Source struct {
+	client       *storage.Client
+	pubsubClient *pubsub.Client //nil unless NewWithPubSub()
+	bucket       string
+	object       string
+	reload       func() error
+	mutex        sync.RWMutex
+	generation   int64
+	data         map[string]interface{}
+	stop         chan struct{}      //nil unless NewPolling()
+	cancel       context.CancelFunc //nil unless NewWithPubSub()
+	done         chan struct{}      //closed when receiving stopped
+	closeOnce    sync.Once
+}
+
+func (s *Source) GetInto(name string, tmpl interface{}) (interface{}, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if _, err := data.Get(s.data, name); err != nil {
+		return nil, nil //not configured
+	}
+	return data.GetInto(s.data, name, tmpl)
+}
+
+// HealthCheck checks that the object can be read
+// it implements config.HealthChecker
+func (s *Source) HealthCheck(ctx context.Context) error {
+	if _, err := s.client.Bucket(s.bucket).Object(s.object).Attrs(ctx); err != nil {
+		return errors.Wrapf(err, "gs://%s/%s", s.bucket, s.object)
+	}
+	return nil
+}
+
+// Close stops polling or receiving notifications and closes the clients
+func (s *Source) Close() error {
+	s.closeOnce.Do(func() {
+		if s.stop != nil {
+			close(s.stop)
+		}
+		if s.cancel != nil {
+			s.cancel()
+			<-s.done
+		}
+		if s.pubsubClient != nil {
+			s.pubsubClient.Close()
+		}
+		s.client.Close()
+	})
+	return nil
+}
+
+func (s *Source) poll(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.refresh(context.Background())
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// receive calls refresh() for notifications of the object
+// in a goroutine until Close()
+func (s *Source) receive(sub *pubsub.Subscription) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	go func() {
+		defer close(s.done)
+		err := sub.Receive(ctx, func(ctx context.Context, msg *pubsub.Message) {
+			defer msg.Ack()
+			if msg.Attributes["bucketId"] != s.bucket || msg.Attributes["objectId"] != s.object {
+				return //other object in the bucket
+			}
+			config.Logger().Debugf("gs://%s/%s: %s notification", s.bucket, s.object, msg.Attributes["eventType"])
+			s.refresh(ctx)
+		})
+		if err != nil && ctx.Err() == nil {
+			config.Logger().Errorf("gs://%s/%s: stopped receiving notifications: %+v", s.bucket, s.object, err)
+		}
+	}()
+}
+
+// refresh downloads the object if the generation changed,
+// and reloads config when it did
+func (s *Source) refresh(ctx context.Context) {
+	changed, err := s.fetchIfChanged(ctx)
+	if err != nil {
+		config.Logger().Errorf("%+v", err)
+		return
+	}
+	if changed {
+		if err := s.reload(); err != nil {
+			config.Logger().Errorf("failed to reload after gs://%s/%s changed: %+v", s.bucket, s.object, err)
+		}
+	}
+}
+
+// fetchIfChanged downloads the object only if the generation changed
+func (s *Source) fetchIfChanged(ctx context.Context) (bool, error) {
+	attrs, err := s.client.Bucket(s.bucket).Object(s.object).Attrs(ctx)
+	if err != nil {
+		return false, errors.Wrapf(err, "gs://%s/%s", s.bucket, s.object)
+	}
+	s.mutex.RLock()
+	unchanged := attrs.Generation == s.generation
+	s.mutex.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+	return true, s.fetchGeneration(ctx, attrs.Generation)
+}
+
+// fetchGeneration downloads the generation of the object,
+// so that the content matches the generation even when it changes meanwhile
+func (s *Source) fetchGeneration(ctx context.Context, generation int64) error {
+	r, err := s.client.Bucket(s.bucket).Object(s.object).Generation(generation).NewReader(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "gs://%s/%s", s.bucket, s.object)
+	}
+	defer r.Close()
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return errors.Wrapf(err, "gs://%s/%s", s.bucket, s.object)
+	}
+	var value map[string]interface{}
+	if err := json.Unmarshal(content, &value); err != nil {
+		//do not log the content, it may contain secrets
+		return errors.Wrapf(err, "gs://%s/%s: not a JSON object", s.bucket, s.object)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.data = value
+	s.generation = generation
+	config.Logger().Debugf("Fetched gs://%s/%s (generation %d)", s.bucket, s.object, generation)
+	return nil
+} //Source.fetchGeneration()
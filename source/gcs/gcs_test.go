@@ -0,0 +1,214 @@
+package gcs
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"cloud.google.com/go/pubsub/pstest"
+)
+
+// testStorage mocks the Cloud Storage JSON API for object metadata
+// and the XML API for downloads
+type testStorage struct {
+	sync.Mutex
+	contentByGeneration map[int64]string
+	generation          int64
+	downloads           int
+}
+
+func (ts *testStorage) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ts.Lock()
+	defer ts.Unlock()
+	if strings.HasPrefix(r.URL.Path, "/storage/v1/b/") {
+		//metadata: /storage/v1/b/<bucket>/o/<object>
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/storage/v1/b/"), "/")
+		if len(parts) != 3 || parts[0] != "bucket" || parts[1] != "o" || parts[2] != "config.json" {
+			http.Error(w, `{"error":{"code":404,"message":"not found"}}`, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"bucket":     "bucket",
+			"name":       "config.json",
+			"generation": strconv.FormatInt(ts.generation, 10),
+		})
+		return
+	}
+	//download: /<bucket>/<object>?generation=<n>
+	if r.URL.Path != "/bucket/config.json" {
+		http.NotFound(w, r)
+		return
+	}
+	generation, _ := strconv.ParseInt(r.URL.Query().Get("generation"), 10, 64)
+	content, ok := ts.contentByGeneration[generation]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	ts.downloads++
+	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	w.Write([]byte(content))
+} //testStorage.ServeHTTP()
+
+func (ts *testStorage) set(content string) {
+	ts.Lock()
+	defer ts.Unlock()
+	ts.generation++
+	ts.contentByGeneration[ts.generation] = content
+}
+
+func (ts *testStorage) downloadCount() int {
+	ts.Lock()
+	defer ts.Unlock()
+	return ts.downloads
+}
+
+func newTestStorage(t *testing.T, content string) *testStorage {
+	ts := &testStorage{contentByGeneration: map[int64]string{}}
+	ts.set(content)
+	server := httptest.NewServer(ts)
+	t.Cleanup(server.Close)
+	t.Setenv("STORAGE_EMULATOR_HOST", strings.TrimPrefix(server.URL, "http://"))
+	return ts
+}
+
+type testDBConfig struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+func TestNew(t *testing.T) {
+	newTestStorage(t, `{"db":{"host":"db1","port":5432},"name":"shop"}`)
+	s, err := New("bucket", "config.json")
+	if err != nil {
+		t.Fatalf("failed to create source: %+v", err)
+	}
+	defer s.Close()
+	v, err := s.GetInto("db", testDBConfig{})
+	if err != nil {
+		t.Fatalf("failed to get: %+v", err)
+	}
+	if c := v.(testDBConfig); c.Host != "db1" || c.Port != 5432 {
+		t.Fatalf("got %+v", c)
+	}
+	if v, err := s.GetInto("unknown", ""); v != nil || err != nil {
+		t.Fatalf("got %v,%v for unknown", v, err)
+	}
+	if err := s.HealthCheck(context.Background()); err != nil {
+		t.Fatalf("health check failed: %+v", err)
+	}
+
+	if _, err := New("bucket", "other.json"); err == nil {
+		t.Fatalf("created source for missing object")
+	}
+}
+
+func TestNotJSON(t *testing.T) {
+	newTestStorage(t, `not json`)
+	if _, err := New("bucket", "config.json"); err == nil || !strings.Contains(err.Error(), "not a JSON object") {
+		t.Fatalf("got %v", err)
+	}
+}
+
+func waitForReload(t *testing.T, reloads *int32) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt32(reloads) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("not reloaded after change")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestPolling(t *testing.T) {
+	ts := newTestStorage(t, `{"db":{"host":"db1"}}`)
+	s, err := New("bucket", "config.json")
+	if err != nil {
+		t.Fatalf("failed to create source: %+v", err)
+	}
+	reloads := int32(0)
+	s.reload = func() error {
+		atomic.AddInt32(&reloads, 1)
+		return nil
+	}
+	s.stop = make(chan struct{})
+	go s.poll(5 * time.Millisecond)
+	defer s.Close()
+
+	time.Sleep(30 * time.Millisecond)
+	if n := atomic.LoadInt32(&reloads); n != 0 || ts.downloadCount() != 1 {
+		t.Fatalf("reloaded %d times and downloaded %d times without changes", n, ts.downloadCount())
+	}
+	ts.set(`{"db":{"host":"db2"}}`)
+	waitForReload(t, &reloads)
+	if v, _ := s.GetInto("db.host", ""); v != "db2" {
+		t.Fatalf("got %v after change", v)
+	}
+}
+
+func TestPubSub(t *testing.T) {
+	ts := newTestStorage(t, `{"db":{"host":"db1"}}`)
+	srv := pstest.NewServer()
+	t.Cleanup(func() { srv.Close() })
+	t.Setenv("PUBSUB_EMULATOR_HOST", srv.Addr)
+
+	ctx := context.Background()
+	client, err := pubsub.NewClient(ctx, "project")
+	if err != nil {
+		t.Fatalf("failed to create pubsub client: %+v", err)
+	}
+	defer client.Close()
+	topic, err := client.CreateTopic(ctx, "notifications")
+	if err != nil {
+		t.Fatalf("failed to create topic: %+v", err)
+	}
+	defer topic.Stop()
+	if _, err := client.CreateSubscription(ctx, "config", pubsub.SubscriptionConfig{Topic: topic}); err != nil {
+		t.Fatalf("failed to create subscription: %+v", err)
+	}
+
+	reloads := int32(0)
+	s, err := newWithPubSub(func() error {
+		atomic.AddInt32(&reloads, 1)
+		return nil
+	}, "bucket", "config.json", "project", "config")
+	if err != nil {
+		t.Fatalf("failed to create source: %+v", err)
+	}
+	defer s.Close()
+
+	notify := func(object string) {
+		t.Helper()
+		if _, err := topic.Publish(ctx, &pubsub.Message{
+			Data: []byte("{}"),
+			Attributes: map[string]string{
+				"bucketId":  "bucket",
+				"objectId":  object,
+				"eventType": "OBJECT_FINALIZE",
+			},
+		}).Get(ctx); err != nil {
+			t.Fatalf("failed to publish: %+v", err)
+		}
+	}
+	ts.set(`{"db":{"host":"db2"}}`)
+	notify("other.json")
+	time.Sleep(50 * time.Millisecond)
+	if n := atomic.LoadInt32(&reloads); n != 0 {
+		t.Fatalf("reloaded %d times for other object", n)
+	}
+	notify("config.json")
+	waitForReload(t, &reloads)
+	if v, _ := s.GetInto("db.host", ""); v != "db2" {
+		t.Fatalf("got %v after notification", v)
+	}
+}
@@ -0,0 +1,47 @@
+// Package json5 is a config source that reads a JSON5 file,
+// i.e. JSON with comments, trailing commas and unquoted keys
+package json5
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/go-msvc/config"
+	"github.com/go-msvc/data"
+	"github.com/go-msvc/errors"
+	"github.com/titanous/json5"
+)
+
+// NewFile reads a JSON5 object from the file
+// unlike config.File(), it returns an error when the file is missing or invalid
+func NewFile(path string) (config.Source, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read file %s", path)
+	}
+	var obj map[string]interface{}
+	if err := json5.Unmarshal(content, &obj); err != nil {
+		return nil, errors.Wrapf(err, "cannot read JSON5 object from file %s", path)
+	}
+	return source{data: obj}, nil
+}
+
+// MustFile is NewFile() that panics on error, like config.File()
+func MustFile(path string) config.Source {
+	s, err := NewFile(path)
+	if err != nil {
+		panic(fmt.Sprintf("cannot read config file %s: %+v", path, err))
+	}
+	return s
+}
+
+type source struct {
+	data map[string]interface{}
+}
+
+func (s source) GetInto(name string, tmpl interface{}) (interface{}, error) {
+	if _, err := data.Get(s.data, name); err != nil {
+		return nil, nil //not configured
+	}
+	return data.GetInto(s.data, name, tmpl)
+}
@@ -0,0 +1,69 @@
+package json5
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type testServerConfig struct {
+	Host string   `json:"host"`
+	Port int      `json:"port"`
+	Tags []string `json:"tags"`
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write %s: %+v", path, err)
+	}
+	return path
+}
+
+func TestNewFile(t *testing.T) {
+	strict, err := NewFile(writeFile(t, "strict.json", `{"ms":{"server":{"host":"localhost","port":8080,"tags":["a","b"]}}}`))
+	if err != nil {
+		t.Fatalf("failed to read strict JSON: %+v", err)
+	}
+	relaxed, err := NewFile(writeFile(t, "relaxed.json5", `{
+		// comment
+		ms: {
+			server: {
+				host: 'localhost', /* inline comment */
+				port: 8080,
+				tags: ["a", "b",],
+			},
+		},
+	}`))
+	if err != nil {
+		t.Fatalf("failed to read JSON5: %+v", err)
+	}
+	expected, err := strict.GetInto("ms.server", testServerConfig{})
+	if err != nil {
+		t.Fatalf("failed to get: %+v", err)
+	}
+	value, err := relaxed.GetInto("ms.server", testServerConfig{})
+	if err != nil || !reflect.DeepEqual(value, expected) {
+		t.Fatalf("got %+v,%v instead of %+v", value, err, expected)
+	}
+	if value, err := relaxed.GetInto("ms.unknown", ""); value != nil || err != nil {
+		t.Fatalf("got %v,%v for unknown value", value, err)
+	}
+}
+
+func TestNewFileErrors(t *testing.T) {
+	if _, err := NewFile(filepath.Join(t.TempDir(), "missing.json5")); err == nil {
+		t.Fatalf("read missing file")
+	}
+	if _, err := NewFile(writeFile(t, "bad.json5", `{ms:`)); err == nil {
+		t.Fatalf("read invalid file")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("MustFile() did not panic")
+		}
+	}()
+	MustFile(filepath.Join(t.TempDir(), "missing.json5"))
+}
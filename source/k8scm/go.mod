@@ -0,0 +1,18 @@
+module github.com/go-msvc/config/source/k8scm
+
+go 1.19
+
+require (
+	github.com/fsnotify/fsnotify v1.7.0
+	github.com/go-msvc/config v0.0.0
+	github.com/go-msvc/data v1.0.1
+	github.com/go-msvc/errors v1.2.0
+	github.com/go-msvc/logger v1.0.0 // indirect
+)
+
+require (
+	golang.org/x/sys v0.4.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/go-msvc/config => ../..
@@ -0,0 +1,212 @@
+// Package k8scm is a config source that reads a Kubernetes ConfigMap
+// mounted as a volume, where each key is a file in the directory,
+// e.g. the file "db" has the value of config "db"
+//
+// file contents that are valid JSON are parsed, others are strings
+//
+// Kubernetes updates the files by replacing the "..data" symlink in the directory,
+// the source watches the directory and calls config.Reload() when any file changed
+package k8scm
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-msvc/config"
+	"github.com/go-msvc/data"
+	"github.com/go-msvc/errors"
+)
+
+// NewVolumeMount reads the files in dir and watches it for changes
+// call Close() to stop watching
+func NewVolumeMount(dir string, opts ...Option) (*Source, error) {
+	s := &Source{
+		dir:    dir,
+		reload: config.Reload,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	contentByName, err := s.readAll()
+	if err != nil {
+		return nil, err
+	}
+	s.contentByName = contentByName
+	if s.watcher, err = fsnotify.NewWatcher(); err != nil {
+		return nil, errors.Wrapf(err, "cannot watch configmap(%s)", dir)
+	}
+	if err := s.watcher.Add(dir); err != nil {
+		s.watcher.Close()
+		return nil, errors.Wrapf(err, "cannot watch configmap(%s)", dir)
+	}
+	s.done = make(chan struct{})
+	go s.watch()
+	return s, nil
+} //NewVolumeMount()
+
+// Option changes how NewVolumeMount() creates the source
+type Option func(*Source)
+
+// WithConfig reloads c when a file changed, instead of the default config
+func WithConfig(c *config.Config) Option {
+	return WithReload(c.Reload)
+}
+
+// WithReload calls reload when a file changed, instead of config.Reload()
+func WithReload(reload func() error) Option {
+	return func(s *Source) {
+		s.reload = reload
+	}
+}
+
+// Source implements config.Source and config.ListableSource
+type Source struct {
+	dir           string
+	reload        func() error
+	watcher       *fsnotify.Watcher
+	done          chan struct{}
+	mutex         sync.Mutex
+	contentByName map[string]string
+}
+
+// GetInto reads the file of name, name is used as the file name
+// and is not split on dots
+func (s *Source) GetInto(name string, tmpl interface{}) (interface{}, error) {
+	if !validFileName(name) {
+		return nil, nil //not configured
+	}
+	content, err := os.ReadFile(filepath.Join(s.dir, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil //not configured
+		}
+		return nil, errors.Wrapf(err, "cannot read configmap(%s).file(%s)", s.dir, name)
+	}
+	v, err := data.GetInto(map[string]interface{}{"value": parseValue(string(content))}, "value", tmpl)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid configmap(%s).file(%s)", s.dir, name)
+	}
+	return v, nil
+}
+
+// Keys returns the sorted file names
+func (s *Source) Keys() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	names := make([]string, 0, len(s.contentByName))
+	for name := range s.contentByName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Close stops watching the directory
+func (s *Source) Close() error {
+	err := s.watcher.Close()
+	<-s.done
+	return err
+}
+
+// watch re-reads all files on any change in the directory,
+// e.g. the "..data" symlink that Kubernetes renames, and reloads if any changed
+func (s *Source) watch() {
+	defer close(s.done)
+	for {
+		select {
+		case event, ok := <-s.watcher.Events:
+			if !ok {
+				return
+			}
+			config.Logger().Debugf("configmap(%s): %v", s.dir, event)
+			names, err := s.refresh()
+			if err != nil {
+				config.Logger().Errorf("%+v", err)
+				continue
+			}
+			if len(names) > 0 {
+				config.Logger().Debugf("Changed configmap(%s).files%v", s.dir, names)
+				if err := s.reload(); err != nil {
+					config.Logger().Errorf("failed to reload after configmap(%s) changed: %+v", s.dir, err)
+				}
+			}
+		case err, ok := <-s.watcher.Errors:
+			if !ok {
+				return
+			}
+			config.Logger().Errorf("configmap(%s) watch failed: %+v", s.dir, err)
+		}
+	}
+} //Source.watch()
+
+// refresh reads all files and returns the sorted names of files that changed
+func (s *Source) refresh() ([]string, error) {
+	contentByName, err := s.readAll()
+	if err != nil {
+		return nil, err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	changed := []string{}
+	for name, content := range contentByName {
+		if old, ok := s.contentByName[name]; !ok || old != content {
+			changed = append(changed, name)
+		}
+	}
+	for name := range s.contentByName {
+		if _, ok := contentByName[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+	s.contentByName = contentByName
+	sort.Strings(changed)
+	return changed, nil
+}
+
+// readAll reads the content of all files in the directory,
+// skipping hidden entries like "..data" and sub directories
+func (s *Source) readAll() (map[string]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read configmap(%s)", s.dir)
+	}
+	contentByName := map[string]string{}
+	for _, entry := range entries {
+		if !validFileName(entry.Name()) {
+			continue
+		}
+		filename := filepath.Join(s.dir, entry.Name())
+		info, err := os.Stat(filename) //follows the symlinks of keys
+		if err != nil || info.IsDir() {
+			continue
+		}
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot read configmap(%s).file(%s)", s.dir, entry.Name())
+		}
+		contentByName[entry.Name()] = string(content)
+	}
+	return contentByName, nil
+} //Source.readAll()
+
+// validFileName is false for names that are not a key file in the directory
+func validFileName(name string) bool {
+	return name != "" && !strings.HasPrefix(name, ".") && !strings.ContainsAny(name, `/\`)
+}
+
+// parseValue returns the JSON value, or the string if it is not valid JSON
+func parseValue(s string) interface{} {
+	var value interface{}
+	if err := json.Unmarshal([]byte(s), &value); err == nil {
+		return value
+	}
+	return s
+}
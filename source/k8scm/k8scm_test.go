@@ -0,0 +1,118 @@
+package k8scm
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-msvc/config"
+)
+
+type testDBConfig struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+// writeVersion writes the files in a new hidden dir and points "..data" to it
+// with an atomic rename, like Kubernetes does
+func writeVersion(t *testing.T, dir, version string, contentByName map[string]string) {
+	t.Helper()
+	versionDir := filepath.Join(dir, version)
+	if err := os.Mkdir(versionDir, 0700); err != nil {
+		t.Fatalf("failed to make dir: %+v", err)
+	}
+	for name, content := range contentByName {
+		if err := os.WriteFile(filepath.Join(versionDir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write: %+v", err)
+		}
+		link := filepath.Join(dir, name)
+		if _, err := os.Lstat(link); os.IsNotExist(err) {
+			if err := os.Symlink(filepath.Join("..data", name), link); err != nil {
+				t.Fatalf("failed to link: %+v", err)
+			}
+		}
+	}
+	tmpLink := filepath.Join(dir, "..data_tmp")
+	if err := os.Symlink(version, tmpLink); err != nil {
+		t.Fatalf("failed to link: %+v", err)
+	}
+	if err := os.Rename(tmpLink, filepath.Join(dir, "..data")); err != nil {
+		t.Fatalf("failed to rename: %+v", err)
+	}
+}
+
+func TestVolumeMount(t *testing.T) {
+	dir := t.TempDir()
+	writeVersion(t, dir, "..v1", map[string]string{
+		"db":        `{"host":"db1","port":5432}`,
+		"log.level": "debug\n",
+	})
+	reloaded := make(chan struct{}, 10)
+	s, err := NewVolumeMount(dir, WithReload(func() error {
+		reloaded <- struct{}{}
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("failed to create: %+v", err)
+	}
+	defer s.Close()
+
+	if v, err := s.GetInto("db", testDBConfig{}); err != nil || v != (testDBConfig{Host: "db1", Port: 5432}) {
+		t.Fatalf("db %+v, %+v", v, err)
+	}
+	if v, err := s.GetInto("log.level", ""); err != nil || v != "debug\n" {
+		t.Fatalf("log.level %#v, %+v", v, err)
+	}
+	for _, name := range []string{"other", "..data", "../db"} {
+		if v, err := s.GetInto(name, ""); err != nil || v != nil {
+			t.Fatalf("%s %+v, %+v", name, v, err)
+		}
+	}
+	if keys := s.Keys(); !reflect.DeepEqual(keys, []string{"db", "log.level"}) {
+		t.Fatalf("keys %v", keys)
+	}
+
+	writeVersion(t, dir, "..v2", map[string]string{
+		"db":        `{"host":"db2","port":5432}`,
+		"log.level": "debug\n",
+	})
+	select {
+	case <-reloaded:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("not reloaded after update")
+	}
+	if v, err := s.GetInto("db", testDBConfig{}); err != nil || v != (testDBConfig{Host: "db2", Port: 5432}) {
+		t.Fatalf("updated db %+v, %+v", v, err)
+	}
+	if names, err := s.refresh(); err != nil || len(names) != 0 {
+		t.Fatalf("changed again: %v, %+v", names, err)
+	}
+}
+
+func TestVolumeMountWithConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeVersion(t, dir, "..v1", map[string]string{"db": `{"host":"db1","port":5432}`})
+	c := config.New()
+	s, err := NewVolumeMount(dir, WithConfig(c))
+	if err != nil {
+		t.Fatalf("failed to create: %+v", err)
+	}
+	defer s.Close()
+	c.MustConfigure("db", testDBConfig{})
+	if err := c.AddSource("configmap", s); err != nil {
+		t.Fatalf("failed to add source: %+v", err)
+	}
+	if err := c.Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	writeVersion(t, dir, "..v2", map[string]string{"db": `{"host":"db2","port":5432}`})
+	deadline := time.Now().Add(2 * time.Second)
+	for c.Get("db").(testDBConfig).Host != "db2" {
+		if time.Now().After(deadline) {
+			t.Fatalf("config not reloaded after update")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,127 @@
+// Package mock is a config source for unit tests that returns programmed values
+// and records the calls, to verify which names are read from the source
+//
+//	s := mock.New().Expect("db", map[string]interface{}{"host": "localhost"})
+//	c := config.New()
+//	c.MustConfigure("db", dbConfig{})
+//	c.AddSource("mock", s)
+//	c.Load()
+//	s.AssertExpectations(t)
+package mock
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/go-msvc/data"
+	"github.com/go-msvc/errors"
+)
+
+// New creates a mock source without expectations
+func New() *MockSource {
+	return &MockSource{
+		permanentByName: map[string]interface{}{},
+	}
+}
+
+// MockSource implements config.Source
+type MockSource struct {
+	mutex           sync.Mutex
+	expectations    []*expectation
+	permanentByName map[string]interface{}
+	calls           []Call
+}
+
+type expectation struct {
+	name  string
+	value interface{}
+	err   error
+	done  bool
+}
+
+// Call is one call to GetInto()
+type Call struct {
+	Name string
+	Type reflect.Type //of the template
+}
+
+// Expect returns value for the next call to name, once
+// later calls return nil (not configured) unless more are expected
+// value is converted to the template type, e.g. a map into a struct
+func (s *MockSource) Expect(name string, value interface{}) *MockSource {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.expectations = append(s.expectations, &expectation{name: name, value: value})
+	return s
+}
+
+// ExpectError returns err for the next call to name, once
+func (s *MockSource) ExpectError(name string, err error) *MockSource {
+	if err == nil {
+		panic("mock.ExpectError(nil)")
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.expectations = append(s.expectations, &expectation{name: name, err: err})
+	return s
+}
+
+// Permanent returns value for all calls to name that were not expected
+// it is not checked by AssertExpectations()
+func (s *MockSource) Permanent(name string, value interface{}) *MockSource {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.permanentByName[name] = value
+	return s
+}
+
+func (s *MockSource) GetInto(name string, tmpl interface{}) (interface{}, error) {
+	s.mutex.Lock()
+	s.calls = append(s.calls, Call{Name: name, Type: reflect.TypeOf(tmpl)})
+	var value interface{}
+	found := false
+	for _, e := range s.expectations {
+		if !e.done && e.name == name {
+			e.done = true
+			if e.err != nil {
+				s.mutex.Unlock()
+				return nil, e.err
+			}
+			value, found = e.value, true
+			break
+		}
+	}
+	if !found {
+		value, found = s.permanentByName[name]
+	}
+	s.mutex.Unlock()
+
+	if !found || value == nil {
+		return nil, nil //not configured
+	}
+	v, err := data.GetInto(value, "", tmpl)
+	if err != nil {
+		return nil, errors.Wrapf(err, "mock value of %s does not fit %T", name, tmpl)
+	}
+	return v, nil
+} //MockSource.GetInto()
+
+// AssertExpectations fails the test for each expected call that was not made
+func (s *MockSource) AssertExpectations(t testing.TB) {
+	t.Helper()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, e := range s.expectations {
+		if !e.done {
+			t.Errorf("mock source: expected call to %s was not made", e.name)
+		}
+	}
+}
+
+// Calls returns all calls made to GetInto(), in order
+func (s *MockSource) Calls() []Call {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]Call{}, s.calls...)
+}
@@ -0,0 +1,79 @@
+package mock_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-msvc/config"
+	"github.com/go-msvc/config/source/mock"
+	"github.com/go-msvc/errors"
+)
+
+type dbConfig struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+func TestLoadReadsExpectedNames(t *testing.T) {
+	s := mock.New().
+		Expect("db", map[string]interface{}{"host": "localhost", "port": 5432}).
+		Permanent("name", "shop")
+	c := config.New()
+	c.MustConfigure("db", dbConfig{})
+	c.MustConfigure("name", "")
+	if err := c.AddSource("mock", s); err != nil {
+		t.Fatalf("failed to add source: %+v", err)
+	}
+	if err := c.Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	s.AssertExpectations(t)
+	if db := c.Get("db").(dbConfig); db.Host != "localhost" || db.Port != 5432 {
+		t.Fatalf("got %+v", db)
+	}
+
+	names := []string{}
+	for _, call := range s.Calls() {
+		names = append(names, call.Name)
+		if call.Name == "db" && call.Type != reflect.TypeOf(dbConfig{}) {
+			t.Fatalf("db read into %v", call.Type)
+		}
+	}
+	if len(names) != 2 || !strings.Contains(strings.Join(names, ","), "db") || !strings.Contains(strings.Join(names, ","), "name") {
+		t.Fatalf("calls %v", names)
+	}
+}
+
+func TestExpectOnce(t *testing.T) {
+	s := mock.New().Expect("db", dbConfig{Host: "a"}).Permanent("db", dbConfig{Host: "b"})
+	for _, expected := range []string{"a", "b", "b"} {
+		v, err := s.GetInto("db", dbConfig{})
+		if err != nil || v.(dbConfig).Host != expected {
+			t.Fatalf("got %v,%v instead of %s", v, err, expected)
+		}
+	}
+	if v, err := s.GetInto("other", ""); v != nil || err != nil {
+		t.Fatalf("got %v,%v without expectation", v, err)
+	}
+
+	s = mock.New().ExpectError("db", errors.Errorf("cannot connect")).Expect("db", dbConfig{Host: "a"})
+	if _, err := s.GetInto("db", dbConfig{}); err == nil {
+		t.Fatalf("expected error")
+	}
+	if v, _ := s.GetInto("db", dbConfig{}); v.(dbConfig).Host != "a" {
+		t.Fatalf("got %v after error", v)
+	}
+	if v, _ := s.GetInto("db", dbConfig{}); v != nil {
+		t.Fatalf("got %v after expectations were used", v)
+	}
+}
+
+func TestAssertExpectations(t *testing.T) {
+	s := mock.New().Expect("db", dbConfig{})
+	fake := &testing.T{}
+	s.AssertExpectations(fake)
+	if !fake.Failed() {
+		t.Fatalf("did not fail without the expected call")
+	}
+}
@@ -0,0 +1,243 @@
+// Package natskv is a config source that reads values from a NATS JetStream
+// Key-Value bucket with one key per config value, e.g.:
+//
+//	nats kv put config ms.server '{"http":{"addr":"localhost:8080"}}'
+//
+// the values must contain JSON
+package natskv
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-msvc/config"
+	"github.com/go-msvc/data"
+	"github.com/go-msvc/errors"
+	"github.com/nats-io/nats.go"
+)
+
+// KeyValue is the part of nats.KeyValue used by this source
+// so tests can use a mock instead of a JetStream bucket
+type KeyValue interface {
+	Get(key string) (nats.KeyValueEntry, error)
+	Delete(key string, opts ...nats.DeleteOpt) error
+	Watch(keys string, opts ...nats.WatchOpt) (nats.KeyWatcher, error)
+}
+
+// maxReconnectDelay limits the exponential backoff between reconnect attempts
+const maxReconnectDelay = 30 * time.Second
+
+// New connects to the NATS server and returns a source for the values in the bucket
+// the connection keeps reconnecting with exponential backoff up to 30s
+// call Close() to close the connection
+func New(natsURL, bucketName string, opts ...Option) (*Source, error) {
+	nc, err := nats.Connect(natsURL,
+		nats.MaxReconnects(-1),
+		nats.CustomReconnectDelay(reconnectDelay),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				config.Logger().Errorf("NATS(%s) disconnected: %+v", natsURL, err)
+			}
+		}),
+		nats.ReconnectHandler(func(_ *nats.Conn) {
+			config.Logger().Infof("NATS(%s) reconnected", natsURL)
+		}),
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot connect to NATS(%s)", natsURL)
+	}
+	js, err := nc.JetStream()
+	if err != nil {
+		nc.Close()
+		return nil, errors.Wrapf(err, "cannot use JetStream on NATS(%s)", natsURL)
+	}
+	kv, err := js.KeyValue(bucketName)
+	if err != nil {
+		nc.Close()
+		return nil, errors.Wrapf(err, "cannot open NATS(%s).bucket(%s)", natsURL, bucketName)
+	}
+	s := NewWithKeyValue(kv, bucketName, opts...)
+	s.conn = nc
+	return s, nil
+} //New()
+
+// NewWithKeyValue is New() using the specified bucket
+// the caller remains responsible for closing the NATS connection
+func NewWithKeyValue(kv KeyValue, bucketName string, opts ...Option) *Source {
+	if kv == nil {
+		panic("natskv.NewWithKeyValue(nil)")
+	}
+	s := &Source{
+		kv:       kv,
+		name:     bucketName,
+		reload:   config.Reload,
+		watchers: map[nats.KeyWatcher]bool{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Option changes how New() and NewWithKeyValue() create the source
+type Option func(*Source)
+
+// WithConfig makes Watch() reload c instead of the default config
+func WithConfig(c *config.Config) Option {
+	return WithReload(c.Reload)
+}
+
+// WithReload makes Watch() call reload instead of config.Reload()
+func WithReload(reload func() error) Option {
+	return func(s *Source) {
+		s.reload = reload
+	}
+}
+
+// reconnectDelay is 1s, 2s, 4s, ... up to maxReconnectDelay
+func reconnectDelay(attempts int) time.Duration {
+	if attempts > 5 {
+		return maxReconnectDelay
+	}
+	delay := time.Second << attempts
+	if delay > maxReconnectDelay {
+		return maxReconnectDelay
+	}
+	return delay
+}
+
+// Source implements config.Source
+type Source struct {
+	kv     KeyValue
+	name   string
+	conn   *nats.Conn //nil when not created by New()
+	reload func() error
+
+	mutex    sync.Mutex
+	watchers map[nats.KeyWatcher]bool
+	closed   bool
+}
+
+// GetInto reads the key = name
+// if there is no such key, it reads the value inside the key of the nearest
+// ancestor, e.g. "ms.server.http" from "ms.server" -> {"http":{...}}
+// it returns nil,nil if no key was found
+func (s *Source) GetInto(name string, tmpl interface{}) (interface{}, error) {
+	entry, err := s.kv.Get(name)
+	if err == nil {
+		value, err := data.JsonInto(entry.Value(), tmpl)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s(%s)", s.name, name)
+		}
+		return value, nil
+	}
+	if err != nats.ErrKeyNotFound {
+		return nil, errors.Wrapf(err, "failed to get %s(%s)", s.name, name)
+	}
+
+	//match inside the value of the nearest ancestor
+	names := strings.Split(name, ".")
+	for i := len(names) - 1; i > 0; i-- {
+		ancestor := strings.Join(names[:i], ".")
+		entry, err := s.kv.Get(ancestor)
+		if err == nats.ErrKeyNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get %s(%s)", s.name, ancestor)
+		}
+		var ancestorValue interface{}
+		if err := json.Unmarshal(entry.Value(), &ancestorValue); err != nil {
+			return nil, errors.Wrapf(err, "invalid %s(%s)", s.name, ancestor)
+		}
+		rest := strings.Join(names[i:], ".")
+		if _, err := data.Get(ancestorValue, rest); err != nil {
+			return nil, nil //not configured in the nearest ancestor
+		}
+		value, err := data.GetInto(ancestorValue, rest, tmpl)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s(%s).%s", s.name, ancestor, rest)
+		}
+		return value, nil
+	}
+	return nil, nil //not configured
+} //Source.GetInto()
+
+// Watch calls config.Reload() each time a key matching keys is updated or deleted,
+// so that config.Watch() callbacks are called for the changed values
+// keys may use NATS wildcards, e.g. "ms.>" for all keys under "ms."
+// watching stops when the watcher fails or on Close()
+func (s *Source) Watch(keys string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return errors.Errorf("%s is closed", s.name)
+	}
+	w, err := s.kv.Watch(keys)
+	if err != nil {
+		return errors.Wrapf(err, "cannot watch %s(%s)", s.name, keys)
+	}
+	s.watchers[w] = true
+	go s.watch(keys, w)
+	return nil
+}
+
+func (s *Source) watch(keys string, w nats.KeyWatcher) {
+	defer func() {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		delete(s.watchers, w)
+	}()
+	//the watcher first sends the current values, then nil
+	//only the updates after that need a reload
+	initialized := false
+	for entry := range w.Updates() {
+		if entry == nil {
+			initialized = true
+			continue
+		}
+		if !initialized {
+			continue
+		}
+		config.Logger().Debugf("Changed %s(%s): %v", s.name, entry.Key(), entry.Operation())
+		if err := s.reload(); err != nil {
+			config.Logger().Errorf("failed to reload after %s(%s) changed: %+v", s.name, entry.Key(), err)
+		}
+	}
+	config.Logger().Debugf("Stopped watching %s(%s)", s.name, keys)
+} //Source.watch()
+
+// Delete deletes the key from the bucket
+func (s *Source) Delete(name string) error {
+	if err := s.kv.Delete(name); err != nil {
+		return errors.Wrapf(err, "failed to delete %s(%s)", s.name, name)
+	}
+	return nil
+}
+
+// Close stops all watchers, and closes the NATS connection if it was created by New()
+func (s *Source) Close() error {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return nil
+	}
+	s.closed = true
+	watchers := make([]nats.KeyWatcher, 0, len(s.watchers))
+	for w := range s.watchers {
+		watchers = append(watchers, w)
+	}
+	s.mutex.Unlock()
+
+	for _, w := range watchers {
+		if err := w.Stop(); err != nil {
+			config.Logger().Errorf("failed to stop watching %s: %+v", s.name, err)
+		}
+	}
+	if s.conn != nil {
+		s.conn.Close()
+	}
+	return nil
+} //Source.Close()
@@ -0,0 +1,160 @@
+package natskv
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+type mockEntry struct {
+	key   string
+	value []byte
+	op    nats.KeyValueOp
+}
+
+func (e mockEntry) Bucket() string             { return "test" }
+func (e mockEntry) Key() string                { return e.key }
+func (e mockEntry) Value() []byte              { return e.value }
+func (e mockEntry) Revision() uint64           { return 1 }
+func (e mockEntry) Created() time.Time         { return time.Time{} }
+func (e mockEntry) Delta() uint64              { return 0 }
+func (e mockEntry) Operation() nats.KeyValueOp { return e.op }
+
+type mockWatcher struct {
+	updates  chan nats.KeyValueEntry
+	stopOnce sync.Once
+}
+
+func (w *mockWatcher) Context() context.Context           { return context.Background() }
+func (w *mockWatcher) Updates() <-chan nats.KeyValueEntry { return w.updates }
+func (w *mockWatcher) Stop() error {
+	w.stopOnce.Do(func() { close(w.updates) })
+	return nil
+}
+
+type mockKV struct {
+	sync.Mutex
+	values   map[string]string
+	watchers []*mockWatcher
+}
+
+func (kv *mockKV) Get(key string) (nats.KeyValueEntry, error) {
+	kv.Lock()
+	defer kv.Unlock()
+	value, ok := kv.values[key]
+	if !ok {
+		return nil, nats.ErrKeyNotFound
+	}
+	return mockEntry{key: key, value: []byte(value)}, nil
+}
+
+func (kv *mockKV) Delete(key string, opts ...nats.DeleteOpt) error {
+	kv.Lock()
+	defer kv.Unlock()
+	delete(kv.values, key)
+	return nil
+}
+
+func (kv *mockKV) Watch(keys string, opts ...nats.WatchOpt) (nats.KeyWatcher, error) {
+	kv.Lock()
+	defer kv.Unlock()
+	w := &mockWatcher{updates: make(chan nats.KeyValueEntry, 10)}
+	kv.watchers = append(kv.watchers, w)
+	return w, nil
+}
+
+func TestGetInto(t *testing.T) {
+	kv := &mockKV{values: map[string]string{
+		"ms.server": `{"http":{"addr":"localhost:8080"}}`,
+		"ms.port":   `8080`,
+		"ms.bad":    `not json`,
+	}}
+	s := NewWithKeyValue(kv, "test")
+	tests := map[string]struct {
+		name  string
+		tmpl  interface{}
+		value interface{}
+		err   bool
+	}{
+		"exact":            {name: "ms.port", tmpl: 0, value: 8080},
+		"inside ancestor":  {name: "ms.server.http.addr", tmpl: "", value: "localhost:8080"},
+		"missing":          {name: "ms.name", tmpl: "", value: nil},
+		"missing inside":   {name: "ms.server.grpc", tmpl: "", value: nil},
+		"invalid":          {name: "ms.bad", tmpl: "", err: true},
+		"invalid ancestor": {name: "ms.bad.value", tmpl: "", err: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			value, err := s.GetInto(test.name, test.tmpl)
+			if (err != nil) != test.err || value != test.value {
+				t.Fatalf("got %v,%v", value, err)
+			}
+		})
+	}
+	if err := s.Delete("ms.port"); err != nil {
+		t.Fatalf("failed to delete: %+v", err)
+	}
+	if value, err := s.GetInto("ms.port", 0); err != nil || value != nil {
+		t.Fatalf("deleted value got %v,%v", value, err)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	kv := &mockKV{values: map[string]string{}}
+	reloaded := make(chan struct{}, 10)
+	var reloads int32
+	s := NewWithKeyValue(kv, "test", WithReload(func() error {
+		atomic.AddInt32(&reloads, 1)
+		reloaded <- struct{}{}
+		return nil
+	}))
+	if err := s.Watch("ms.>"); err != nil {
+		t.Fatalf("failed to watch: %+v", err)
+	}
+	w := kv.watchers[0]
+	w.updates <- mockEntry{key: "ms.port", value: []byte("1"), op: nats.KeyValuePut} //initial value
+	w.updates <- nil
+	w.updates <- mockEntry{key: "ms.port", value: []byte("2"), op: nats.KeyValuePut}
+	w.updates <- mockEntry{key: "ms.port", op: nats.KeyValueDelete}
+	for i := 0; i < 2; i++ {
+		select {
+		case <-reloaded:
+		case <-time.After(time.Second):
+			t.Fatalf("not reloaded after update %d", i+1)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("failed to close: %+v", err)
+	}
+	//the watcher goroutine stops when the updates channel is closed
+	for i := 0; ; i++ {
+		s.mutex.Lock()
+		n := len(s.watchers)
+		s.mutex.Unlock()
+		if n == 0 {
+			break
+		}
+		if i > 100 {
+			t.Fatalf("watcher not stopped")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := atomic.LoadInt32(&reloads); n != 2 {
+		t.Fatalf("%d reloads", n)
+	}
+	if err := s.Watch("ms.>"); err == nil {
+		t.Fatalf("watched after close")
+	}
+}
+
+func TestReconnectDelay(t *testing.T) {
+	for attempts, expected := range map[int]time.Duration{0: time.Second, 1: 2 * time.Second, 4: 16 * time.Second, 5: 30 * time.Second, 100: 30 * time.Second} {
+		if delay := reconnectDelay(attempts); delay != expected {
+			t.Fatalf("attempt %d: delay %v != %v", attempts, delay, expected)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+// Package pflag is a config source that reads values from command line flags
+// parsed with github.com/spf13/pflag, e.g. config "db.host" is read from flag --db-host
+// only flags that were set on the command line are used, so that other sources
+// provide the values of flags that were not set
+package pflag
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-msvc/config"
+	"github.com/go-msvc/data"
+	"github.com/go-msvc/errors"
+	"github.com/spf13/pflag"
+)
+
+// New creates a config source from the flags in fs
+// call it after fs.Parse()
+func New(fs *pflag.FlagSet) config.Source {
+	if fs == nil {
+		panic("pflag.New(nil)")
+	}
+	return source{fs: fs}
+}
+
+type source struct {
+	fs *pflag.FlagSet
+}
+
+// GetInto reads the flag with the config name where dots are replaced with hyphens,
+// e.g. "db.host" from --db-host
+// if that flag is not set, it combines all set flags that start with the name,
+// e.g. "db" is {"host":..., "port":...} from --db-host and --db-port
+// it returns nil,nil if no such flags were set
+func (s source) GetInto(name string, tmpl interface{}) (interface{}, error) {
+	flagName := FlagName(name)
+	if f := s.fs.Lookup(flagName); f != nil && f.Changed {
+		value, err := flagValue(f, tmpl)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid flag --%s", flagName)
+		}
+		return value, nil
+	}
+
+	obj := map[string]interface{}{}
+	var err error
+	s.fs.Visit(func(f *pflag.Flag) {
+		if err != nil || !strings.HasPrefix(f.Name, flagName+"-") {
+			return
+		}
+		var value interface{}
+		if value, err = flagValue(f, nil); err != nil {
+			err = errors.Wrapf(err, "invalid flag --%s", f.Name)
+			return
+		}
+		setNested(obj, strings.Split(strings.TrimPrefix(f.Name, flagName+"-"), "-"), value)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(obj) == 0 {
+		return nil, nil //not configured
+	}
+	return data.GetInto(obj, "", tmpl)
+} //source.GetInto()
+
+// FlagName returns the flag name for a config name, e.g. "db.host" -> "db-host"
+func FlagName(name string) string {
+	return strings.ReplaceAll(name, ".", "-")
+}
+
+// flagValue returns the value of the flag parsed into tmpl
+// or as a generic value if tmpl is nil
+func flagValue(f *pflag.Flag, tmpl interface{}) (interface{}, error) {
+	var value interface{}
+	if sliceValue, ok := f.Value.(pflag.SliceValue); ok {
+		list := []interface{}{}
+		for _, item := range sliceValue.GetSlice() {
+			list = append(list, jsonOrString(item))
+		}
+		value = list
+	} else if f.Value.Type() == "string" {
+		value = f.Value.String()
+	} else if f.Value.Type() == "duration" {
+		d, err := time.ParseDuration(f.Value.String())
+		if err != nil {
+			return nil, err
+		}
+		value = int64(d) //time.Duration is nanoseconds in JSON
+	} else {
+		value = jsonOrString(f.Value.String())
+	}
+	if tmpl == nil {
+		return value, nil
+	}
+	if _, ok := tmpl.(string); ok {
+		return f.Value.String(), nil
+	}
+	return data.GetInto(map[string]interface{}{"value": value}, "value", tmpl)
+} //flagValue()
+
+// jsonOrString parses numbers and booleans from the flag text
+func jsonOrString(s string) interface{} {
+	var value interface{}
+	if err := json.Unmarshal([]byte(s), &value); err == nil {
+		switch value.(type) {
+		case float64, bool:
+			return value
+		}
+	}
+	return s
+}
+
+func setNested(obj map[string]interface{}, names []string, value interface{}) {
+	for _, name := range names[:len(names)-1] {
+		sub, ok := obj[name].(map[string]interface{})
+		if !ok {
+			sub = map[string]interface{}{}
+			obj[name] = sub
+		}
+		obj = sub
+	}
+	obj[names[len(names)-1]] = value
+}
+
+// AutoRegister defines a flag for each field of the tmpl struct, named
+// with the prefix and the json name of the field, e.g. with prefix "db",
+// field `json:"host" doc:"database host"` is flag --db-host with usage "database host"
+// the default of each flag is the value of the field in tmpl
+// nested structs are flattened, e.g. --db-tls-cert
+func AutoRegister(fs *pflag.FlagSet, prefix string, tmpl interface{}) error {
+	v := reflect.ValueOf(tmpl)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v = reflect.Zero(v.Type().Elem())
+			break
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return errors.Errorf("pflag.AutoRegister(%s) needs a struct, not %T", prefix, tmpl)
+	}
+	return registerFields(fs, FlagName(prefix), v)
+} //AutoRegister()
+
+func registerFields(fs *pflag.FlagSet, prefix string, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _ := config.JSONName(f)
+		if name == "" {
+			continue
+		}
+		fieldValue := v.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct && strings.Split(config.FieldTag(f, "json"), ",")[0] == "" {
+			if err := registerFields(fs, prefix, fieldValue); err != nil {
+				return err
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		flagName := name
+		if prefix != "" {
+			flagName = prefix + "-" + name
+		}
+		for fieldValue.Kind() == reflect.Ptr {
+			if fieldValue.IsNil() {
+				fieldValue = reflect.Zero(fieldValue.Type().Elem())
+				break
+			}
+			fieldValue = fieldValue.Elem()
+		}
+		if err := registerFlag(fs, flagName, fieldValue, config.DocField(f)); err != nil {
+			return err
+		}
+	}
+	return nil
+} //registerFields()
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func registerFlag(fs *pflag.FlagSet, flagName string, v reflect.Value, usage string) error {
+	if fs.Lookup(flagName) != nil {
+		return errors.Errorf("flag --%s already defined", flagName)
+	}
+	if v.Type() == durationType {
+		fs.Duration(flagName, time.Duration(v.Int()), usage)
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		fs.String(flagName, v.String(), usage)
+	case reflect.Bool:
+		fs.Bool(flagName, v.Bool(), usage)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		fs.Int64(flagName, v.Int(), usage)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		fs.Uint64(flagName, v.Uint(), usage)
+	case reflect.Float32, reflect.Float64:
+		fs.Float64(flagName, v.Float(), usage)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return errors.Errorf("flag --%s: cannot define flag for %v", flagName, v.Type())
+		}
+		fs.StringSlice(flagName, v.Convert(reflect.TypeOf([]string{})).Interface().([]string), usage)
+	case reflect.Struct:
+		return registerFields(fs, flagName, v)
+	default:
+		return errors.Errorf("flag --%s: cannot define flag for %v", flagName, v.Type())
+	}
+	return nil
+} //registerFlag()
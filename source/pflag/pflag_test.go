@@ -0,0 +1,121 @@
+package pflag_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-msvc/config"
+	configpflag "github.com/go-msvc/config/source/pflag"
+	"github.com/spf13/pflag"
+)
+
+type tlsConfig struct {
+	Cert string `json:"cert"`
+}
+
+type dbConfig struct {
+	Host    string        `json:"host" doc:"database host"`
+	Port    int           `json:"port"`
+	Debug   bool          `json:"debug"`
+	Timeout time.Duration `json:"timeout"`
+	Tags    []string      `json:"tags"`
+	TLS     *tlsConfig    `json:"tls"`
+}
+
+func newFlagSet(t *testing.T, args ...string) *pflag.FlagSet {
+	t.Helper()
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	if err := configpflag.AutoRegister(fs, "ms.db", dbConfig{Host: "localhost", Port: 5432}); err != nil {
+		t.Fatalf("failed to register: %+v", err)
+	}
+	if err := fs.Parse(args); err != nil {
+		t.Fatalf("failed to parse: %+v", err)
+	}
+	return fs
+}
+
+func TestAutoRegister(t *testing.T) {
+	fs := newFlagSet(t)
+	for name, expected := range map[string]string{
+		"ms-db-host":     "localhost",
+		"ms-db-port":     "5432",
+		"ms-db-debug":    "false",
+		"ms-db-timeout":  "0s",
+		"ms-db-tags":     "[]",
+		"ms-db-tls-cert": "",
+	} {
+		f := fs.Lookup(name)
+		if f == nil {
+			t.Fatalf("flag --%s not defined", name)
+		}
+		if f.DefValue != expected {
+			t.Fatalf("flag --%s default %q, expected %q", name, f.DefValue, expected)
+		}
+	}
+	if fs.Lookup("ms-db-host").Usage != "database host" {
+		t.Fatalf("usage=%q", fs.Lookup("ms-db-host").Usage)
+	}
+	if err := configpflag.AutoRegister(fs, "ms.db", dbConfig{}); err == nil {
+		t.Fatalf("registered the same flags twice")
+	}
+	if err := configpflag.AutoRegister(fs, "ms.other", 123); err == nil {
+		t.Fatalf("registered flags for int")
+	}
+}
+
+func TestGetInto(t *testing.T) {
+	fs := newFlagSet(t, "--ms-db-host=db1", "--ms-db-port=1234", "--ms-db-debug", "--ms-db-timeout=2s", "--ms-db-tags=a,b", "--ms-db-tls-cert=x.pem")
+	s := configpflag.New(fs)
+	expected := dbConfig{Host: "db1", Port: 1234, Debug: true, Timeout: 2 * time.Second, Tags: []string{"a", "b"}, TLS: &tlsConfig{Cert: "x.pem"}}
+	if v, err := s.GetInto("ms.db", dbConfig{}); err != nil || !reflect.DeepEqual(v, expected) {
+		t.Fatalf("got %+v,%v", v, err)
+	}
+	if v, err := s.GetInto("ms.db.host", ""); err != nil || v != "db1" {
+		t.Fatalf("got %v,%v", v, err)
+	}
+	if v, err := s.GetInto("ms.db.port", 0); err != nil || v != 1234 {
+		t.Fatalf("got %v,%v", v, err)
+	}
+}
+
+func TestGetIntoUnset(t *testing.T) {
+	s := configpflag.New(newFlagSet(t))
+	for _, name := range []string{"ms.db", "ms.db.host", "ms.other"} {
+		if v, err := s.GetInto(name, map[string]interface{}{}); err != nil || v != nil {
+			t.Fatalf("%s got %v,%v", name, v, err)
+		}
+	}
+}
+
+func TestFlagsOverrideFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(filename, []byte(`{"ms":{"db":{"host":"file","port":1}}}`), 0600); err != nil {
+		t.Fatalf("failed to write: %+v", err)
+	}
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.String("ms-db-host", "", "")
+	fs.Int("ms-db-port", 0, "")
+	if err := fs.Parse([]string{"--ms-db-host=flag"}); err != nil {
+		t.Fatalf("failed to parse: %+v", err)
+	}
+	config.MustConfigure("ms.db.host", "")
+	config.MustConfigure("ms.db.port", 0)
+	if err := config.AddSource("flags", configpflag.New(fs)); err != nil {
+		t.Fatalf("failed to add source: %+v", err)
+	}
+	if err := config.AddSource("file", config.File(filename)); err != nil {
+		t.Fatalf("failed to add source: %+v", err)
+	}
+	if err := config.Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if config.Get("ms.db.host") != "flag" {
+		t.Fatalf("set flag did not override: %v", config.Get("ms.db.host"))
+	}
+	if config.Get("ms.db.port") != 1 {
+		t.Fatalf("unset flag overrode: %v", config.Get("ms.db.port"))
+	}
+}
@@ -0,0 +1,66 @@
+// Package prefix is a config source that exposes only the values under a prefix
+// of another source, e.g. with prefix "services.shop" the inner value
+// "services.shop.db.host" is config "db.host"
+//
+// it is the complement of config.Sub(), which adds a prefix to reads
+package prefix
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-msvc/config"
+)
+
+// New creates a source that reads name from inner as prefix + "." + name
+func New(inner config.Source, prefix string) *Source {
+	if inner == nil {
+		panic("prefix.New(nil)")
+	}
+	prefix = strings.Trim(prefix, ".")
+	if prefix == "" {
+		panic("prefix.New() without prefix")
+	}
+	return &Source{inner: inner, prefix: prefix}
+}
+
+// Source implements config.Source and config.ListableSource
+type Source struct {
+	inner  config.Source
+	prefix string
+}
+
+func (s *Source) GetInto(name string, tmpl interface{}) (interface{}, error) {
+	return s.inner.GetInto(s.innerName(name), tmpl)
+}
+
+// Keys returns the keys of the inner source under the prefix, without the prefix
+// it returns nil if the inner source is not a config.ListableSource
+func (s *Source) Keys() []string {
+	listable, ok := s.inner.(config.ListableSource)
+	if !ok {
+		return nil
+	}
+	keys := []string{}
+	for _, key := range listable.Keys() {
+		if strings.HasPrefix(key, s.prefix+".") {
+			keys = append(keys, strings.TrimPrefix(key, s.prefix+"."))
+		}
+	}
+	return keys
+}
+
+// HealthCheck checks the inner source if it is a config.HealthChecker
+func (s *Source) HealthCheck(ctx context.Context) error {
+	if checker, ok := s.inner.(config.HealthChecker); ok {
+		return checker.HealthCheck(ctx)
+	}
+	return nil
+}
+
+func (s *Source) innerName(name string) string {
+	if name == "" {
+		return s.prefix
+	}
+	return s.prefix + "." + name
+}
@@ -0,0 +1,64 @@
+package prefix_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-msvc/config"
+	"github.com/go-msvc/config/source/mock"
+	"github.com/go-msvc/config/source/prefix"
+)
+
+type dbConfig struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+func TestPrefix(t *testing.T) {
+	inner := mock.New().
+		Permanent("services.shop.db", map[string]interface{}{"host": "db1", "port": 5432}).
+		Permanent("services.shop.name", "shop")
+	s := prefix.New(inner, "services.shop")
+	c := config.New()
+	c.MustConfigure("db", dbConfig{})
+	c.MustConfigure("name", "")
+	c.Optional("other", "none")
+	if err := c.AddSource("prefix", s); err != nil {
+		t.Fatalf("failed to add source: %+v", err)
+	}
+	if err := c.Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if db := c.Get("db").(dbConfig); db.Host != "db1" || db.Port != 5432 {
+		t.Fatalf("got %+v", db)
+	}
+	if name := c.Get("name"); name != "shop" {
+		t.Fatalf("name %v", name)
+	}
+	for _, call := range inner.Calls() {
+		if call.Name == "db" || call.Name == "other" {
+			t.Fatalf("read %s without prefix", call.Name)
+		}
+	}
+}
+
+type listableSource struct {
+	*mock.MockSource
+	keys []string
+}
+
+func (s listableSource) Keys() []string { return s.keys }
+
+func TestKeys(t *testing.T) {
+	inner := listableSource{
+		MockSource: mock.New(),
+		keys:       []string{"services.cart.db.host", "services.shop.db.host", "services.shop.name", "services.shopping.name"},
+	}
+	keys := prefix.New(inner, "services.shop.").Keys()
+	if expected := []string{"db.host", "name"}; !reflect.DeepEqual(keys, expected) {
+		t.Fatalf("keys %v", keys)
+	}
+	if keys := prefix.New(mock.New(), "services.shop").Keys(); keys != nil {
+		t.Fatalf("keys %v from source that is not listable", keys)
+	}
+}
@@ -0,0 +1,202 @@
+// Package s3 is a config source that reads a JSON object from AWS S3
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/go-msvc/config"
+	"github.com/go-msvc/data"
+	"github.com/go-msvc/errors"
+)
+
+// Client is the part of the S3 client used by this source
+// so tests can use a mock instead of *s3.Client
+type Client interface {
+	GetObject(ctx context.Context, params *awss3.GetObjectInput, optFns ...func(*awss3.Options)) (*awss3.GetObjectOutput, error)
+	HeadObject(ctx context.Context, params *awss3.HeadObjectInput, optFns ...func(*awss3.Options)) (*awss3.HeadObjectOutput, error)
+}
+
+// New downloads the JSON object s3://<bucket>/<key> using the default
+// AWS credentials and returns a source for the values in it
+func New(bucket, key, region string) (*Source, error) {
+	client, err := newClient(region)
+	if err != nil {
+		return nil, errors.Wrapf(err, "s3://%s/%s", bucket, key)
+	}
+	return NewWithClient(client, bucket, key)
+}
+
+// NewWithClient is New() using the specified client
+func NewWithClient(client Client, bucket, key string) (*Source, error) {
+	s := &Source{
+		client: client,
+		bucket: bucket,
+		key:    key,
+	}
+	if err := s.fetch(context.Background()); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// NewPolling is New() that checks every interval if the object changed
+// and then downloads it again, so Reload() will get the new values
+// call Close() to stop polling
+func NewPolling(bucket, key, region string, interval time.Duration) (*Source, error) {
+	if interval <= 0 {
+		return nil, errors.Errorf("s3://%s/%s: invalid polling interval %v", bucket, key, interval)
+	}
+	s, err := New(bucket, key, region)
+	if err != nil {
+		return nil, err
+	}
+	s.stop = make(chan struct{})
+	go s.poll(interval)
+	return s, nil
+}
+
+// FromURI is New() for "s3://<bucket>/<key>?region=<region>"
+// it is registered for config.AddSourceURI()
+func FromURI(uri string) (config.Source, error) {
+	bucket, key, region, err := parseURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	return New(bucket, key, region)
+}
+
+func parseURI(uri string) (bucket, key, region string, err error) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "s3" {
+		return "", "", "", errors.Errorf("invalid S3 URI, expecting s3://<bucket>/<key>")
+	}
+	bucket, key = u.Host, strings.TrimPrefix(u.Path, "/")
+	if bucket == "" || key == "" {
+		return "", "", "", errors.Errorf("invalid S3 URI %s, expecting s3://<bucket>/<key>", uri)
+	}
+	return bucket, key, u.Query().Get("region"), nil
+}
+
+func init() {
+	config.RegisterSourceScheme("s3", FromURI)
+}
+
+func newClient(region string) (*awss3.Client, error) {
+	cfg, err := awsconfig.LoadDefaultConfig(context.Background(), awsconfig.WithRegion(region))
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot load AWS config")
+	}
+	return awss3.NewFromConfig(cfg), nil
+}
+
+// Source implements config.Source
+type Source struct {
+	client    Client
+	bucket    string
+	key       string
+	mutex     sync.RWMutex
+	etag      string
+	data      map[string]interface{}
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+func (s *Source) GetInto(name string, tmpl interface{}) (interface{}, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if _, err := data.Get(s.data, name); err != nil {
+		return nil, nil //not configured
+	}
+	return data.GetInto(s.data, name, tmpl)
+}
+
+// HealthCheck checks that the object can be read
+// it implements config.HealthChecker
+func (s *Source) HealthCheck(ctx context.Context) error {
+	if _, err := s.client.HeadObject(ctx, &awss3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key),
+	}); err != nil {
+		return errors.Wrapf(err, "s3://%s/%s", s.bucket, s.key)
+	}
+	return nil
+}
+
+// Close stops polling
+func (s *Source) Close() error {
+	s.closeOnce.Do(func() {
+		if s.stop != nil {
+			close(s.stop)
+		}
+	})
+	return nil
+}
+
+func (s *Source) poll(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.fetchIfChanged(context.Background()); err != nil {
+				config.Logger().Errorf("%+v", err)
+			}
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// fetchIfChanged downloads the object only if the ETag changed
+func (s *Source) fetchIfChanged(ctx context.Context) error {
+	head, err := s.client.HeadObject(ctx, &awss3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "s3://%s/%s", s.bucket, s.key)
+	}
+	s.mutex.RLock()
+	unchanged := aws.ToString(head.ETag) == s.etag
+	s.mutex.RUnlock()
+	if unchanged {
+		return nil
+	}
+	return s.fetch(ctx)
+}
+
+func (s *Source) fetch(ctx context.Context) error {
+	out, err := s.client.GetObject(ctx, &awss3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "s3://%s/%s", s.bucket, s.key)
+	}
+	defer out.Body.Close()
+	buffer := bytes.NewBuffer(nil)
+	if _, err := buffer.ReadFrom(out.Body); err != nil {
+		return errors.Wrapf(err, "s3://%s/%s", s.bucket, s.key)
+	}
+	var value map[string]interface{}
+	if err := json.Unmarshal(buffer.Bytes(), &value); err != nil {
+		//do not log the content, it may contain secrets
+		return errors.Wrapf(err, "s3://%s/%s: not a JSON object", s.bucket, s.key)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.data = value
+	s.etag = aws.ToString(out.ETag)
+	config.Logger().Debugf("Fetched s3://%s/%s (ETag %s)", s.bucket, s.key, s.etag)
+	return nil
+} //Source.fetch()
@@ -0,0 +1,81 @@
+package s3_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/go-msvc/config"
+	"github.com/go-msvc/config/source/s3"
+)
+
+type mockClient struct {
+	etag    string
+	content string
+	gets    int
+}
+
+func (c *mockClient) GetObject(ctx context.Context, params *awss3.GetObjectInput, optFns ...func(*awss3.Options)) (*awss3.GetObjectOutput, error) {
+	c.gets++
+	return &awss3.GetObjectOutput{
+		Body: io.NopCloser(bytes.NewBufferString(c.content)),
+		ETag: aws.String(c.etag),
+	}, nil
+}
+
+func (c *mockClient) HeadObject(ctx context.Context, params *awss3.HeadObjectInput, optFns ...func(*awss3.Options)) (*awss3.HeadObjectOutput, error) {
+	return &awss3.HeadObjectOutput{ETag: aws.String(c.etag)}, nil
+}
+
+func TestGetInto(t *testing.T) {
+	client := &mockClient{etag: "1", content: `{"db":{"host":"localhost","port":5432}}`}
+	s, err := s3.NewWithClient(client, "bucket", "key")
+	if err != nil {
+		t.Fatalf("failed: %+v", err)
+	}
+	v, err := s.GetInto("db.port", 0)
+	if err != nil || v != 5432 {
+		t.Fatalf("got %v,%v", v, err)
+	}
+	v, err = s.GetInto("db.user", "")
+	if err != nil || v != nil {
+		t.Fatalf("absent value got %v,%v", v, err)
+	}
+}
+
+func TestInvalidJSON(t *testing.T) {
+	client := &mockClient{etag: "1", content: `not json`}
+	if _, err := s3.NewWithClient(client, "bucket", "key"); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestFromURI(t *testing.T) {
+	found := false
+	for _, scheme := range config.ListSchemes() {
+		found = found || scheme == "s3"
+	}
+	if !found {
+		t.Fatalf("s3 scheme not registered: %v", config.ListSchemes())
+	}
+	for _, uri := range []string{"s3://bucket", "s3:///key", "http://bucket/key"} {
+		if _, err := s3.FromURI(uri); err == nil {
+			t.Fatalf("no error for %s", uri)
+		}
+	}
+}
+
+func TestHealthCheck(t *testing.T) {
+	client := &mockClient{etag: "1", content: `{}`}
+	s, err := s3.NewWithClient(client, "bucket", "key")
+	if err != nil {
+		t.Fatalf("failed: %+v", err)
+	}
+	var hc config.HealthChecker = s
+	if err := hc.HealthCheck(context.Background()); err != nil {
+		t.Fatalf("unhealthy: %+v", err)
+	}
+}
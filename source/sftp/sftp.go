@@ -0,0 +1,224 @@
+// Package sftp is a config source that reads a JSON or YAML file from an SFTP server
+//
+// the file is parsed with the decoder registered in config for its extension,
+// e.g. ".json", ".yaml" or ".yml", and JSON is assumed without an extension
+package sftp
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-msvc/config"
+	"github.com/go-msvc/data"
+	"github.com/go-msvc/errors"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// Client is the part of the SFTP client used by this source
+// so tests can use a mock instead of an SFTP server
+// ReadFile must return an error for which os.IsNotExist() is true
+// when the file does not exist
+type Client interface {
+	ReadFile(path string) ([]byte, error)
+}
+
+const dialTimeout = 10 * time.Second
+
+// New connects to host with the private key in keyPath and downloads remotePath
+// the host key is verified with the user's ~/.ssh/known_hosts
+// host may include the port, the default is 22
+// call Close() to close the connection
+func New(host, user, keyPath, remotePath string) (*Source, error) {
+	key, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read SSH key")
+	}
+	signer, err := ssh.ParsePrivateKey(key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot parse SSH key(%s)", keyPath)
+	}
+	return NewWithAuth(host, user, ssh.PublicKeys(signer), remotePath)
+}
+
+// NewWithPassword is New() with password authentication
+func NewWithPassword(host, user, password, remotePath string) (*Source, error) {
+	return NewWithAuth(host, user, ssh.Password(password), remotePath)
+}
+
+// NewWithAuth is New() with any SSH authentication method
+func NewWithAuth(host, user string, auth ssh.AuthMethod, remotePath string) (*Source, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot find known_hosts")
+	}
+	hostKeyCallback, err := knownhosts.New(filepath.Join(home, ".ssh", "known_hosts"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read known_hosts")
+	}
+	return NewWithConfig(host, &ssh.ClientConfig{
+		User:            user,
+		Auth:            []ssh.AuthMethod{auth},
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         dialTimeout,
+	}, remotePath)
+}
+
+// NewWithConfig is New() with the SSH client config,
+// e.g. to verify the host key with another known_hosts file
+func NewWithConfig(host string, sshConfig *ssh.ClientConfig, remotePath string) (*Source, error) {
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(host, "22")
+	}
+	sshClient, err := ssh.Dial("tcp", host, sshConfig)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot connect to sftp://%s", host)
+	}
+	sftpClient, err := sftp.NewClient(sshClient)
+	if err != nil {
+		sshClient.Close()
+		return nil, errors.Wrapf(err, "cannot start SFTP on %s", host)
+	}
+	s, err := NewWithClient(fileClient{sftpClient}, remotePath)
+	if err != nil {
+		sftpClient.Close()
+		sshClient.Close()
+		return nil, errors.Wrapf(err, "sftp://%s", host)
+	}
+	s.closers = []io.Closer{sftpClient, sshClient}
+	return s, nil
+}
+
+// NewWithClient is New() using the specified client
+// the caller remains responsible for closing the connection
+func NewWithClient(client Client, remotePath string) (*Source, error) {
+	if client == nil {
+		panic("sftp.NewWithClient(nil)")
+	}
+	ext := strings.TrimPrefix(path.Ext(remotePath), ".")
+	if ext == "" {
+		ext = "json"
+	}
+	decoder, ok := config.LookupDecoder(ext)
+	if !ok {
+		return nil, errors.Errorf("no config decoder for %s", remotePath)
+	}
+	s := &Source{
+		client:     client,
+		remotePath: remotePath,
+		decoder:    decoder,
+		now:        time.Now,
+	}
+	if err := s.fetch(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// fileClient implements Client with *sftp.Client
+type fileClient struct {
+	client *sftp.Client
+}
+
+func (c fileClient) ReadFile(path string) ([]byte, error) {
+	f, err := c.client.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// Source implements config.Source
+type Source struct {
+	client     Client
+	remotePath string
+	decoder    func(r io.Reader) (map[string]interface{}, error)
+	closers    []io.Closer //nil when not created by New()
+	now        func() time.Time
+
+	mutex     sync.Mutex
+	ttl       time.Duration
+	fetchedAt time.Time
+	data      map[string]interface{} //nil when the file does not exist
+}
+
+// WithTTL makes GetInto() download the file again when it is older than ttl,
+// so that config.Reload() gets the new values
+// if that download fails, the error is logged and the old values are used
+func (s *Source) WithTTL(ttl time.Duration) *Source {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.ttl = ttl
+	return s
+}
+
+// GetInto returns nil,nil if the file or the name does not exist
+func (s *Source) GetInto(name string, tmpl interface{}) (interface{}, error) {
+	s.mutex.Lock()
+	expired := s.ttl > 0 && s.now().Sub(s.fetchedAt) >= s.ttl
+	s.mutex.Unlock()
+	if expired {
+		if err := s.fetch(); err != nil {
+			config.Logger().Errorf("%+v", err)
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.data == nil {
+		return nil, nil //file does not exist
+	}
+	if _, err := data.Get(s.data, name); err != nil {
+		return nil, nil //not configured
+	}
+	return data.GetInto(s.data, name, tmpl)
+}
+
+func (s *Source) fetch() error {
+	content, err := s.client.ReadFile(s.remotePath)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "cannot download %s", s.remotePath)
+	}
+	var value map[string]interface{}
+	if err == nil {
+		value, err = s.decoder(bytes.NewReader(content))
+		if err != nil {
+			//do not log the content, it may contain secrets
+			return errors.Wrapf(err, "cannot parse %s", s.remotePath)
+		}
+		if value == nil {
+			value = map[string]interface{}{} //empty file
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.data = value
+	s.fetchedAt = s.now()
+	config.Logger().Debugf("Fetched %s (exists=%v)", s.remotePath, value != nil)
+	return nil
+} //Source.fetch()
+
+// Close closes the connection if it was created by New()
+func (s *Source) Close() error {
+	s.mutex.Lock()
+	closers := s.closers
+	s.closers = nil
+	s.mutex.Unlock()
+	var firstErr error
+	for _, c := range closers {
+		if err := c.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,198 @@
+package sftp
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+type testConfig struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+// testServer is an SSH server with the SFTP subsystem serving files from dir
+func testServer(t *testing.T, dir, user, password string) (addr string, hostKey ssh.PublicKey) {
+	t.Helper()
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("cannot generate host key: %+v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(privateKey)
+	if err != nil {
+		t.Fatalf("cannot create signer: %+v", err)
+	}
+	serverConfig := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if c.User() == user && string(pass) == password {
+				return nil, nil
+			}
+			return nil, os.ErrPermission
+		},
+	}
+	serverConfig.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("cannot listen: %+v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSSH(conn, serverConfig, dir)
+		}
+	}()
+	return listener.Addr().String(), signer.PublicKey()
+}
+
+func serveSSH(conn net.Conn, serverConfig *ssh.ServerConfig, dir string) {
+	_, channels, requests, err := ssh.NewServerConn(conn, serverConfig)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(requests)
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "only sessions")
+			continue
+		}
+		channel, channelRequests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			for req := range channelRequests {
+				//payload is the string length (4 bytes) and "sftp"
+				ok := req.Type == "subsystem" && string(req.Payload[4:]) == "sftp"
+				req.Reply(ok, nil)
+				if ok {
+					server, err := sftp.NewServer(channel, sftp.WithServerWorkingDirectory(dir))
+					if err != nil {
+						channel.Close()
+						return
+					}
+					go func() {
+						server.Serve()
+						server.Close()
+					}()
+				}
+			}
+		}()
+	}
+}
+
+func TestNewWithConfig(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("db:\n  host: db1\n  port: 5432\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	addr, hostKey := testServer(t, dir, "app", "secret")
+	sshConfig := &ssh.ClientConfig{
+		User:            "app",
+		Auth:            []ssh.AuthMethod{ssh.Password("secret")},
+		HostKeyCallback: ssh.FixedHostKey(hostKey),
+		Timeout:         time.Second,
+	}
+	s, err := NewWithConfig(addr, sshConfig, "config.yaml")
+	if err != nil {
+		t.Fatalf("failed to create source: %+v", err)
+	}
+	defer s.Close()
+	v, err := s.GetInto("db", testConfig{})
+	if err != nil {
+		t.Fatalf("failed to get: %+v", err)
+	}
+	if c := v.(testConfig); c.Host != "db1" || c.Port != 5432 {
+		t.Fatalf("got %+v", c)
+	}
+	if v, err := s.GetInto("cache", testConfig{}); v != nil || err != nil {
+		t.Fatalf("got %v,%v for unknown name", v, err)
+	}
+
+	//missing file is not an error, it just has no values
+	missing, err := NewWithConfig(addr, sshConfig, "missing.json")
+	if err != nil {
+		t.Fatalf("failed with missing file: %+v", err)
+	}
+	defer missing.Close()
+	if v, err := missing.GetInto("db", testConfig{}); v != nil || err != nil {
+		t.Fatalf("got %v,%v from missing file", v, err)
+	}
+
+	//wrong password
+	sshConfig.Auth = []ssh.AuthMethod{ssh.Password("wrong")}
+	if _, err := NewWithConfig(addr, sshConfig, "config.yaml"); err == nil {
+		t.Fatalf("connected with wrong password")
+	}
+}
+
+type testClient struct {
+	sync.Mutex
+	files map[string]string
+	reads int
+}
+
+func (c *testClient) ReadFile(path string) ([]byte, error) {
+	c.Lock()
+	defer c.Unlock()
+	c.reads++
+	content, ok := c.files[path]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return []byte(content), nil
+}
+
+func (c *testClient) set(path, content string) {
+	c.Lock()
+	defer c.Unlock()
+	c.files[path] = content
+}
+
+func TestTTL(t *testing.T) {
+	client := &testClient{files: map[string]string{"/etc/app/config.json": `{"db":{"host":"db1","port":5432}}`}}
+	s, err := NewWithClient(client, "/etc/app/config.json")
+	if err != nil {
+		t.Fatalf("failed to create source: %+v", err)
+	}
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	s.WithTTL(time.Minute)
+
+	client.set("/etc/app/config.json", `{"db":{"host":"db2","port":5432}}`)
+	v, _ := s.GetInto("db", testConfig{})
+	if c := v.(testConfig); c.Host != "db1" {
+		t.Fatalf("downloaded before TTL expired: %+v", c)
+	}
+
+	now = now.Add(2 * time.Minute)
+	v, _ = s.GetInto("db", testConfig{})
+	if c := v.(testConfig); c.Host != "db2" {
+		t.Fatalf("not downloaded after TTL expired: %+v", c)
+	}
+	if client.reads != 2 {
+		t.Fatalf("reads=%d, expected 2", client.reads)
+	}
+}
+
+func TestInvalidFile(t *testing.T) {
+	client := &testClient{files: map[string]string{"config.json": `{"db":`}}
+	if _, err := NewWithClient(client, "config.json"); err == nil {
+		t.Fatalf("created source with invalid JSON")
+	}
+	if _, err := NewWithClient(client, "config.toml"); err == nil {
+		t.Fatalf("created source without decoder")
+	}
+}
@@ -0,0 +1,235 @@
+// Package template is a config source that renders Go templates in the
+// string values of another source, e.g. "{{ .Get \"db.host\" }}:{{ .Env \"DB_PORT\" }}"
+//
+// in templates, .Get reads other config from all sources of the config,
+// or "" if not configured, and .Env reads an env var
+// config that refers to itself, also through other values, fails to load
+package template
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"github.com/go-msvc/config"
+	"github.com/go-msvc/data"
+	"github.com/go-msvc/errors"
+)
+
+// New renders the templates in values from inner, reading .Get from config.Default()
+func New(inner config.Source) *Source {
+	return NewWithConfig(config.Default(), inner)
+}
+
+// NewWithConfig is New() that reads .Get from c
+func NewWithConfig(c *config.Config, inner config.Source) *Source {
+	if c == nil || inner == nil {
+		panic("template.NewWithConfig(nil)")
+	}
+	return &Source{
+		c:     c,
+		inner: inner,
+	}
+}
+
+// Source implements config.Source
+type Source struct {
+	c     *config.Config
+	inner config.Source
+}
+
+func (s *Source) GetInto(name string, tmpl interface{}) (interface{}, error) {
+	//names of the templates being rendered when called from .Get, to detect circular references
+	//it is passed in tmpl, because each render chain has its own, also when values load in parallel
+	chain := chainOf(tmpl)
+	if isObject(tmpl) {
+		//render the strings in the object before it is parsed into tmpl
+		v, err := s.inner.GetInto(name, map[string]interface{}{})
+		if err != nil || v == nil {
+			return v, err
+		}
+		rendered, err := s.renderValue(name, v, chain)
+		if err != nil {
+			return nil, err
+		}
+		return data.GetInto(rendered, "", tmpl)
+	}
+
+	if _, ok := tmpl.(json.RawMessage); ok {
+		//from templateData.Get(), where strings are JSON
+		v, err := s.inner.GetInto(name, tmpl)
+		if err != nil || v == nil {
+			return v, err
+		}
+		var text string
+		if err := json.Unmarshal(v.(json.RawMessage), &text); err != nil || !isTemplate(text) {
+			return v, nil
+		}
+		rendered, err := s.render(name, text, chain)
+		if err != nil {
+			return nil, err
+		}
+		jsonValue, err := json.Marshal(rendered)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot encode rendered config(%s)", name)
+		}
+		return json.RawMessage(jsonValue), nil
+	}
+
+	v, err := s.inner.GetInto(name, tmpl)
+	if err == nil {
+		if text, ok := v.(string); ok && isTemplate(text) {
+			return s.render(name, text, chain)
+		}
+		return v, nil
+	}
+	if _, ok := tmpl.(string); ok {
+		return nil, err
+	}
+	//a template for another type, e.g. a number, cannot be read into tmpl before it is rendered
+	raw, rawErr := s.inner.GetInto(name, "")
+	text, ok := raw.(string)
+	if rawErr != nil || !ok || !isTemplate(text) {
+		return nil, err
+	}
+	rendered, renderErr := s.render(name, text, chain)
+	if renderErr != nil {
+		return nil, renderErr
+	}
+	v, err = data.GetInto(map[string]interface{}{"value": parseValue(rendered)}, "value", tmpl)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid rendered config(%s)", name)
+	}
+	return v, nil
+} //Source.GetInto()
+
+// renderValue renders the templates in the strings of maps and slices
+func (s *Source) renderValue(name string, v interface{}, chain []string) (interface{}, error) {
+	switch value := v.(type) {
+	case string:
+		if !isTemplate(value) {
+			return value, nil
+		}
+		return s.render(name, value, chain)
+	case map[string]interface{}:
+		rendered := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			r, err := s.renderValue(name, item, chain)
+			if err != nil {
+				return nil, err
+			}
+			rendered[key] = r
+		}
+		return rendered, nil
+	case []interface{}:
+		rendered := make([]interface{}, len(value))
+		for i, item := range value {
+			r, err := s.renderValue(name, item, chain)
+			if err != nil {
+				return nil, err
+			}
+			rendered[i] = r
+		}
+		return rendered, nil
+	}
+	return v, nil
+}
+
+// render executes the template text of config name
+// chain has the names of the templates that are rendering this one
+func (s *Source) render(name, text string, chain []string) (string, error) {
+	for _, rendering := range chain {
+		if rendering == name {
+			return "", errors.Errorf("circular template reference to config(%s)", name)
+		}
+	}
+	t, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid template in config(%s)", name)
+	}
+	buffer := bytes.NewBuffer(nil)
+	td := templateData{c: s.c, chain: append(append([]string{}, chain...), name)}
+	if err := t.Execute(buffer, td); err != nil {
+		return "", errors.Wrapf(err, "failed to render config(%s)", name)
+	}
+	return buffer.String(), nil
+} //Source.render()
+
+// templateData is the data of templates
+type templateData struct {
+	c     *config.Config
+	chain []string //names of the templates being rendered, the last one uses this data
+}
+
+// chainTemplate is the template used by .Get, with the render chain, so
+// that nested renders in this source can detect circular references
+// other sources ignore the content of a json.RawMessage template
+type chainTemplate struct {
+	Chain []string `json:"__template_chain__"`
+}
+
+// chainOf returns the render chain passed in a template from .Get, or nil
+func chainOf(tmpl interface{}) []string {
+	raw, ok := tmpl.(json.RawMessage)
+	if !ok || len(raw) == 0 {
+		return nil
+	}
+	var ct chainTemplate
+	if err := json.Unmarshal(raw, &ct); err != nil {
+		return nil
+	}
+	return ct.Chain
+}
+
+// Get returns the config value of key as text, e.g. 5432 for a number
+// or JSON for objects, and "" if it is not configured
+func (d templateData) Get(key string) (string, error) {
+	tmpl, err := json.Marshal(chainTemplate{Chain: d.chain})
+	if err != nil {
+		return "", errors.Wrapf(err, "cannot encode template chain")
+	}
+	v, err := d.c.Struct(key, json.RawMessage(tmpl))
+	if err != nil {
+		//text/template formats the error with %v, which only shows the last message
+		return "", errors.Errorf("%s", err.Error())
+	}
+	raw := v.(json.RawMessage)
+	if bytes.Equal(raw, tmpl) {
+		return "", nil //not configured, Struct() returned the template
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s, nil
+	}
+	return string(raw), nil
+}
+
+// Env returns the value of the env var
+func (d templateData) Env(key string) string {
+	return os.Getenv(key)
+}
+
+func isTemplate(s string) bool {
+	return strings.Contains(s, "{{")
+}
+
+// parseValue returns the JSON value, or the string if it is not valid JSON
+func parseValue(s string) interface{} {
+	var value interface{}
+	if err := json.Unmarshal([]byte(s), &value); err == nil {
+		return value
+	}
+	return s
+}
+
+// isObject is true for struct and map templates
+func isObject(tmpl interface{}) bool {
+	t := reflect.TypeOf(tmpl)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t != nil && (t.Kind() == reflect.Struct || t.Kind() == reflect.Map)
+}
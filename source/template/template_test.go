@@ -0,0 +1,102 @@
+package template_test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-msvc/config"
+	"github.com/go-msvc/config/source/mock"
+	"github.com/go-msvc/config/source/template"
+)
+
+type serverConfig struct {
+	Addr string `json:"addr"`
+	Port int    `json:"port"`
+}
+
+func TestTemplate(t *testing.T) {
+	t.Setenv("TEST_TEMPLATE_PORT", "8080")
+	inner := mock.New().
+		Permanent("addr", `{{ .Get "db.host" }}:{{ .Get "db.port" }}`).
+		Permanent("db.host", "db1").
+		Permanent("db.port", 5432).
+		Permanent("port", `{{ .Env "TEST_TEMPLATE_PORT" }}`).
+		Permanent("server", map[string]interface{}{"addr": `{{ .Get "db.host" }}:{{ .Get "other" }}`, "port": 1})
+	c := config.New()
+	c.MustConfigure("addr", "")
+	c.MustConfigure("port", 0)
+	c.MustConfigure("server", serverConfig{})
+	if err := c.AddSource("template", template.NewWithConfig(c, inner)); err != nil {
+		t.Fatalf("failed to add source: %+v", err)
+	}
+	if err := c.Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if addr := c.Get("addr"); addr != "db1:5432" {
+		t.Fatalf("addr %v", addr)
+	}
+	if port := c.Get("port"); port != 8080 {
+		t.Fatalf("port %v", port)
+	}
+	if server := c.Get("server").(serverConfig); server != (serverConfig{Addr: "db1:", Port: 1}) {
+		t.Fatalf("server %+v", server)
+	}
+}
+
+func TestTemplateCircular(t *testing.T) {
+	inner := mock.New().
+		Permanent("a", `{{ .Get "b" }}`).
+		Permanent("b", `x{{ .Get "a" }}`)
+	c := config.New()
+	c.MustConfigure("a", "")
+	if err := c.AddSource("template", template.NewWithConfig(c, inner)); err != nil {
+		t.Fatalf("failed to add source: %+v", err)
+	}
+	if err := c.Load(); err == nil || !strings.Contains(err.Error(), "circular template reference to config(a)") {
+		t.Fatalf("wrong error: %v", err)
+	}
+}
+
+func TestTemplateParallel(t *testing.T) {
+	inner := mock.New().
+		Permanent("db.name", "shop").
+		Permanent("db.host", `{{ .Get "db.name" }}.db`)
+	c := config.New()
+	c.SetLoader(config.NewParallelLoader(10))
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("dsn%d", i)
+		inner.Permanent(name, fmt.Sprintf(`{{ .Get "db.host" }}/%d`, i))
+		c.MustConfigure(name, "")
+	}
+	if err := c.AddSource("template", template.NewWithConfig(c, inner)); err != nil {
+		t.Fatalf("failed to add source: %+v", err)
+	}
+	if err := c.Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	for i := 0; i < 20; i++ {
+		if dsn := c.Get(fmt.Sprintf("dsn%d", i)); dsn != fmt.Sprintf("shop.db/%d", i) {
+			t.Fatalf("dsn%d %v", i, dsn)
+		}
+	}
+
+	//concurrent reads outside Load()
+	wg := sync.WaitGroup{}
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Struct("dsn0", ""); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("failed to read: %+v", err)
+	}
+}
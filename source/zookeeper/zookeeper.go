@@ -0,0 +1,213 @@
+// Package zookeeper is a config source that reads values from Apache ZooKeeper
+// with one node per config value under a path prefix, e.g. "db.host" is read
+// from the node "<prefix>/db/host"
+//
+// the node data must contain JSON
+package zookeeper
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-msvc/config"
+	"github.com/go-msvc/data"
+	"github.com/go-msvc/errors"
+	"github.com/go-zookeeper/zk"
+)
+
+// Client is the part of *zk.Conn used by this source
+// so tests can use a mock instead of a ZooKeeper server
+type Client interface {
+	Get(path string) ([]byte, *zk.Stat, error)
+	GetW(path string) ([]byte, *zk.Stat, <-chan zk.Event, error)
+	ExistsW(path string) (bool, *zk.Stat, <-chan zk.Event, error)
+}
+
+const (
+	sessionTimeout = 10 * time.Second
+	minRetryDelay  = time.Second
+	maxRetryDelay  = 30 * time.Second
+)
+
+// New connects to the ZooKeeper servers and returns a source for the nodes under pathPrefix
+// call Close() to close the connection
+func New(servers []string, pathPrefix string, opts ...Option) (*Source, error) {
+	conn, events, err := zk.Connect(servers, sessionTimeout, zk.WithLogInfo(false))
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot connect to ZooKeeper(%s)", strings.Join(servers, ","))
+	}
+	s := NewWithClient(conn, pathPrefix, opts...)
+	s.conn = conn
+	go logSessionEvents(events)
+	return s, nil
+}
+
+// NewWithClient is New() using the specified client
+// the caller remains responsible for closing the connection
+func NewWithClient(client Client, pathPrefix string, opts ...Option) *Source {
+	if client == nil {
+		panic("zookeeper.NewWithClient(nil)")
+	}
+	s := &Source{
+		client:     client,
+		prefix:     "/" + strings.Trim(pathPrefix, "/"),
+		reload:     config.Reload,
+		retryDelay: minRetryDelay,
+		stop:       make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Option changes how New() and NewWithClient() create the source
+type Option func(*Source)
+
+// WithConfig makes Watch() reload c instead of the default config
+func WithConfig(c *config.Config) Option {
+	return WithReload(c.Reload)
+}
+
+// WithReload makes Watch() call reload instead of config.Reload()
+func WithReload(reload func() error) Option {
+	return func(s *Source) {
+		s.reload = reload
+	}
+}
+
+// logSessionEvents logs session changes until the connection is closed
+// watches are lost when the session expires and are then set again by Source.watch()
+func logSessionEvents(events <-chan zk.Event) {
+	for event := range events {
+		if event.Type != zk.EventSession {
+			continue
+		}
+		switch event.State {
+		case zk.StateExpired:
+			config.Logger().Errorf("ZooKeeper(%s) session expired", event.Server)
+		case zk.StateHasSession:
+			config.Logger().Infof("ZooKeeper(%s) session established", event.Server)
+		}
+	}
+}
+
+// Source implements config.Source
+type Source struct {
+	client     Client
+	prefix     string
+	conn       *zk.Conn //nil when not created by New()
+	reload     func() error
+	retryDelay time.Duration
+	stop       chan struct{}
+	closeOnce  sync.Once
+}
+
+// path returns the node path of a config name, e.g. "db.host" -> "<prefix>/db/host"
+func (s *Source) path(name string) string {
+	if s.prefix == "/" {
+		return "/" + strings.ReplaceAll(name, ".", "/")
+	}
+	return s.prefix + "/" + strings.ReplaceAll(name, ".", "/")
+}
+
+// GetInto reads the node of name
+// it returns nil,nil if there is no such node
+func (s *Source) GetInto(name string, tmpl interface{}) (interface{}, error) {
+	path := s.path(name)
+	value, _, err := s.client.Get(path)
+	if err == zk.ErrNoNode {
+		return nil, nil //not configured
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get ZooKeeper(%s)", path)
+	}
+	if len(value) == 0 {
+		return nil, nil //parent nodes usually have no data
+	}
+	v, err := data.JsonInto(value, tmpl)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid ZooKeeper(%s)", path)
+	}
+	return v, nil
+}
+
+// Watch calls config.Reload() each time the node of name is created, changed or deleted,
+// so that config.Watch() callbacks are called for the changed value
+// ZooKeeper watches fire once, so the watch is set again after each change,
+// and also when the session was lost, with backoff until ZooKeeper is reachable
+// watching stops on Close()
+func (s *Source) Watch(name string) {
+	go s.watch(s.path(name))
+}
+
+func (s *Source) watch(path string) {
+	delay := s.retryDelay
+	lost := false
+	for {
+		events, err := s.watchNode(path)
+		if err != nil {
+			config.Logger().Errorf("cannot watch ZooKeeper(%s), retry in %v: %+v", path, delay, err)
+			select {
+			case <-s.stop:
+				return
+			case <-time.After(delay):
+			}
+			delay *= 2
+			if delay > maxRetryDelay {
+				delay = maxRetryDelay
+			}
+			continue
+		}
+		delay = s.retryDelay
+		if lost {
+			//the node may have changed while not watching
+			lost = false
+			s.reloadAfter(path)
+		}
+
+		select {
+		case <-s.stop:
+			return
+		case event, ok := <-events:
+			if !ok || event.Type == zk.EventNotWatching {
+				config.Logger().Errorf("stopped watching ZooKeeper(%s), watching again", path)
+				lost = true
+				continue
+			}
+			config.Logger().Debugf("Changed ZooKeeper(%s): %v", path, event.Type)
+			s.reloadAfter(path)
+		}
+	}
+} //Source.watch()
+
+// watchNode sets a watch on the node data, or on its creation when it does not exist
+func (s *Source) watchNode(path string) (<-chan zk.Event, error) {
+	_, _, events, err := s.client.GetW(path)
+	if err != zk.ErrNoNode {
+		return events, err
+	}
+	exists, _, events, err := s.client.ExistsW(path)
+	if err == nil && exists {
+		return nil, errors.Errorf("created while setting watch") //retry with GetW()
+	}
+	return events, err
+}
+
+func (s *Source) reloadAfter(path string) {
+	if err := s.reload(); err != nil {
+		config.Logger().Errorf("failed to reload after ZooKeeper(%s) changed: %+v", path, err)
+	}
+}
+
+// Close stops all watches, and closes the connection if it was created by New()
+func (s *Source) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.stop)
+		if s.conn != nil {
+			s.conn.Close()
+		}
+	})
+	return nil
+}
@@ -0,0 +1,148 @@
+package zookeeper
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-zookeeper/zk"
+)
+
+type mockClient struct {
+	sync.Mutex
+	nodes   map[string]string
+	watches chan chan zk.Event //each watch that was set
+	fail    int                //number of watches that fail
+}
+
+func (c *mockClient) Get(path string) ([]byte, *zk.Stat, error) {
+	c.Lock()
+	defer c.Unlock()
+	value, ok := c.nodes[path]
+	if !ok {
+		return nil, nil, zk.ErrNoNode
+	}
+	return []byte(value), &zk.Stat{}, nil
+}
+
+func (c *mockClient) GetW(path string) ([]byte, *zk.Stat, <-chan zk.Event, error) {
+	value, stat, err := c.Get(path)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	events, err := c.newWatch()
+	return value, stat, events, err
+}
+
+func (c *mockClient) ExistsW(path string) (bool, *zk.Stat, <-chan zk.Event, error) {
+	events, err := c.newWatch()
+	return false, nil, events, err
+}
+
+func (c *mockClient) newWatch() (<-chan zk.Event, error) {
+	c.Lock()
+	defer c.Unlock()
+	if c.fail > 0 {
+		c.fail--
+		return nil, zk.ErrNoServer
+	}
+	events := make(chan zk.Event, 1)
+	c.watches <- events
+	return events, nil
+}
+
+func TestGetInto(t *testing.T) {
+	client := &mockClient{nodes: map[string]string{
+		"/config/db":      "",
+		"/config/db/host": `"localhost"`,
+		"/config/db/port": `5432`,
+		"/config/db/bad":  `not json`,
+	}}
+	s := NewWithClient(client, "/config/")
+	tests := map[string]struct {
+		name  string
+		tmpl  interface{}
+		value interface{}
+		err   bool
+	}{
+		"string":      {name: "db.host", tmpl: "", value: "localhost"},
+		"int":         {name: "db.port", tmpl: 0, value: 5432},
+		"missing":     {name: "db.user", tmpl: "", value: nil},
+		"parent node": {name: "db", tmpl: map[string]interface{}{}, value: nil},
+		"invalid":     {name: "db.bad", tmpl: "", err: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			value, err := s.GetInto(test.name, test.tmpl)
+			if (err != nil) != test.err || (test.value == nil && value != nil) || (test.value != nil && value != test.value) {
+				t.Fatalf("got %v,%v", value, err)
+			}
+		})
+	}
+	if path := NewWithClient(client, "").path("db.host"); path != "/db/host" {
+		t.Fatalf("path=%s", path)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	client := &mockClient{
+		nodes:   map[string]string{"/config/db/host": `"localhost"`},
+		watches: make(chan chan zk.Event, 10),
+		fail:    1,
+	}
+	reloaded := make(chan struct{}, 10)
+	s := NewWithClient(client, "config", WithReload(func() error {
+		reloaded <- struct{}{}
+		return nil
+	}))
+	s.retryDelay = time.Millisecond
+	nextWatch := func() chan zk.Event {
+		t.Helper()
+		select {
+		case events := <-client.watches:
+			return events
+		case <-time.After(time.Second):
+			t.Fatalf("watch not set")
+		}
+		return nil
+	}
+	expectReload := func() {
+		t.Helper()
+		select {
+		case <-reloaded:
+		case <-time.After(time.Second):
+			t.Fatalf("not reloaded")
+		}
+	}
+	s.Watch("db.host")
+
+	//the first watch fails and is retried
+	events := nextWatch()
+	events <- zk.Event{Type: zk.EventNodeDataChanged, Path: "/config/db/host"}
+	expectReload()
+
+	//watches fire once, so it is set again after each change
+	events = nextWatch()
+	events <- zk.Event{Type: zk.EventNotWatching, State: zk.StateDisconnected, Path: "/config/db/host"}
+
+	//set again when the session is lost, then reloaded in case of missed changes
+	events = nextWatch()
+	expectReload()
+
+	//a deleted node is watched for creation
+	client.Lock()
+	delete(client.nodes, "/config/db/host")
+	client.Unlock()
+	events <- zk.Event{Type: zk.EventNodeDeleted, Path: "/config/db/host"}
+	expectReload()
+	nextWatch()
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("failed to close: %+v", err)
+	}
+	select {
+	case <-client.watches:
+		t.Fatalf("watch set after close")
+	case <-time.After(10 * time.Millisecond):
+	}
+}
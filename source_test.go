@@ -0,0 +1,86 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAddSource(t *testing.T) {
+	reset(t)
+	first := &testSource{value: map[string]interface{}{"ms": map[string]interface{}{"name": "first"}}}
+	second := &testSource{value: map[string]interface{}{"ms": map[string]interface{}{"name": "second"}}}
+	if err := AddSource("test", first); err != nil {
+		t.Fatalf("failed to add source: %+v", err)
+	}
+	//same name is ignored, e.g. when called again in tests
+	if err := AddSource(" test ", second); err != nil {
+		t.Fatalf("failed to add source again: %+v", err)
+	}
+	if len(defaultConfig.sources) != 1 {
+		t.Fatalf("len(sources)=%d", len(defaultConfig.sources))
+	}
+	if err := AddSource("", second); err == nil {
+		t.Fatalf("added source without name")
+	}
+	if err := AddSource("nil", nil); err == nil {
+		t.Fatalf("added nil source")
+	}
+	if len(defaultConfig.sources) != 1 {
+		t.Fatalf("len(sources)=%d", len(defaultConfig.sources))
+	}
+}
+
+func TestFirstSourceWins(t *testing.T) {
+	reset(t)
+	MustConfigure("ms.name", "")
+	MustConfigure("ms.port", 0)
+	if err := AddSource("first", &testSource{value: map[string]interface{}{"ms": map[string]interface{}{"name": "first"}}}); err != nil {
+		t.Fatalf("failed to add source: %+v", err)
+	}
+	if err := AddSource("second", &testSource{value: map[string]interface{}{"ms": map[string]interface{}{"name": "second", "port": 2}}}); err != nil {
+		t.Fatalf("failed to add source: %+v", err)
+	}
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if Get("ms.name") != "first" || Get("ms.port") != 2 {
+		t.Fatalf("ms.name=%v ms.port=%v", Get("ms.name"), Get("ms.port"))
+	}
+	if defaultConfig.sourceNameByRef["ms.name"] != "first" || defaultConfig.sourceNameByRef["ms.port"] != "second" {
+		t.Fatalf("sources=%+v", defaultConfig.sourceNameByRef)
+	}
+}
+
+func TestLoadWithoutSources(t *testing.T) {
+	reset(t)
+	if err := Load(); err == nil {
+		t.Fatalf("loaded without sources")
+	}
+}
+
+func TestAddSourceWithPriority(t *testing.T) {
+	reset(t)
+	MustConfigure("ms.name", "")
+	for _, s := range []struct {
+		priority int
+		name     string
+	}{{0, "a"}, {100, "c"}, {10, "b"}, {0, "d"}, {100, "e"}} {
+		value := map[string]interface{}{"ms": map[string]interface{}{"name": s.name}}
+		if err := AddSourceWithPriority(s.priority, s.name, &testSource{value: value}); err != nil {
+			t.Fatalf("failed to add source: %+v", err)
+		}
+	}
+	names := []string{}
+	for _, ns := range defaultConfig.sources {
+		names = append(names, ns.name)
+	}
+	if strings.Join(names, ",") != "c,e,b,a,d" {
+		t.Fatalf("wrong order: %v", names)
+	}
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if Get("ms.name") != "c" {
+		t.Fatalf("ms.name=%v", Get("ms.name"))
+	}
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/go-msvc/data"
+	"github.com/go-msvc/errors"
+)
+
+// Struct reads prefix from the sources into a copy of tmpl once,
+// e.g. for a library init() or a CLI tool that needs config but not Reload():
+//
+//	v, err := config.Struct("db", dbConfig{Port: 5432})
+//
+// unlike MustConfigure(), prefix is not registered so it is not
+// reloaded, documented or dumped, and it may be called before or after Load()
+// fields not in any source keep the value from tmpl
+// the value is validated like in Load(), and if it implements Constructor,
+// the result of Create() is returned instead
+func (c *Config) Struct(prefix string, tmpl interface{}) (interface{}, error) {
+	if tmpl == nil {
+		return nil, errors.Errorf("config.Struct(%s) called with nil template", prefix)
+	}
+	if !validReference(prefix) {
+		return nil, errors.Errorf("config.Struct(%s) called with invalid prefix", prefix)
+	}
+	prefix = c.resolveAlias(prefix)
+	value, ns, err := c.getFromSources(prefix, tmpl)
+	if err != nil {
+		return nil, err
+	}
+	sourceName := ns.name
+	if value == nil {
+		//not configured: use the template, which must also be valid
+		value, sourceName = tmpl, "default"
+		if validator, ok := value.(data.Validator); ok {
+			if err := validator.Validate(); err != nil {
+				return nil, errors.Wrapf(err, "invalid default config(%s)", prefix)
+			}
+		}
+	}
+	value, err = c.decryptFields(value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid source(%s).config(%s)", sourceName, prefix)
+	}
+	value = resolveEnvFields(value)
+	if err := validateStruct(value); err != nil {
+		return nil, errors.Wrapf(err, "invalid source(%s).config(%s)", sourceName, prefix)
+	}
+	if err := c.runValidators(prefix, value); err != nil {
+		return nil, errors.Wrapf(err, "invalid source(%s).config(%s)", sourceName, prefix)
+	}
+	if constructor, ok := value.(Constructor); ok {
+		created, err := constructor.Create()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create config(%s)", prefix)
+		}
+		return created, nil
+	}
+	return value, nil
+} //Struct()
+
+// MustStruct is Struct() that panics on error
+func (c *Config) MustStruct(prefix string, tmpl interface{}) interface{} {
+	value, err := c.Struct(prefix, tmpl)
+	if err != nil {
+		panic(fmt.Sprintf("config.Struct(%s) failed: %+v", prefix, err))
+	}
+	return value
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-msvc/errors"
+)
+
+type testClientConfig struct {
+	Addr    string `json:"addr" validate:"required"`
+	Retries int    `json:"retries" validate:"max=5"`
+}
+
+type testClient struct {
+	addr string
+}
+
+func (c testClientConfig) Create() (interface{}, error) {
+	if c.Addr == "fail" {
+		return nil, errors.Errorf("cannot create")
+	}
+	return testClient{addr: c.Addr}, nil
+}
+
+func TestStruct(t *testing.T) {
+	reset(t)
+	addTestSource(t, map[string]interface{}{
+		"db":      map[string]interface{}{"host": "localhost", "port": 5432, "name": "db", "level": "info", "code": "a,1", "retries": 1},
+		"invalid": map[string]interface{}{"host": "localhost", "port": 0, "name": "db", "level": "info", "code": "a,1", "retries": 1},
+		"client":  map[string]interface{}{"addr": "server:80"},
+		"failing": map[string]interface{}{"addr": "fail"},
+	})
+	value, err := Struct("db", testValidateConfig{})
+	if err != nil {
+		t.Fatalf("failed: %+v", err)
+	}
+	if c := value.(testValidateConfig); c.Host != "localhost" || c.Port != 5432 || *c.Retries != 1 {
+		t.Fatalf("value=%+v", c)
+	}
+	//not registered
+	if _, ok := Dump()["db"]; ok {
+		t.Fatalf("dumped one-shot config")
+	}
+
+	if _, err := Struct("invalid", testValidateConfig{}); err == nil || !strings.Contains(err.Error(), "invalid field(port) because 0 < min 1") {
+		t.Fatalf("err=%v", err)
+	}
+
+	//not configured: the template is used and must be valid
+	if value, err := Struct("missing", testClientConfig{Addr: "default:80"}); err != nil || value != (testClient{addr: "default:80"}) {
+		t.Fatalf("got %+v,%v", value, err)
+	}
+	if _, err := Struct("missing", testClientConfig{}); err == nil || !strings.Contains(err.Error(), "invalid field(addr) because required") {
+		t.Fatalf("err=%v", err)
+	}
+
+	//constructor
+	if value := MustStruct("client", testClientConfig{Retries: 1}); value != (testClient{addr: "server:80"}) {
+		t.Fatalf("value=%+v", value)
+	}
+	if _, err := Struct("failing", testClientConfig{}); err == nil || !strings.Contains(err.Error(), "cannot create") {
+		t.Fatalf("err=%v", err)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatalf("MustStruct() did not panic")
+			}
+		}()
+		MustStruct("failing", testClientConfig{})
+	}()
+
+	//also after Load()
+	MustConfigure("client", testClientConfig{})
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if value := MustStruct("client", testClientConfig{}); value != (testClient{addr: "server:80"}) {
+		t.Fatalf("value after load=%+v", value)
+	}
+}
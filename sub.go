@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ISubConfig is used to access config in a namespace, e.g. a library
+// package can use config.Sub("database") then call Get("host")
+// to get the value of "database.host" without repeating the prefix
+// all calls are passed to the Config it was created from with "<prefix>." prepended
+// to the reference
+type ISubConfig interface {
+	Prefix() string
+	MayConfigure(ref string, tmpl interface{})
+	MustConfigure(ref string, tmpl interface{})
+	MayConstruct(ref string, constructedType reflect.Type)
+	MustConstruct(ref string, constructedType reflect.Type)
+	Get(ref string) any
+	Sub(subPrefix string) ISubConfig
+}
+
+func (c *Config) Sub(prefix string) ISubConfig {
+	if !validReference(prefix) {
+		panic(fmt.Sprintf("invalid config prefix(%s), expecting dot-notation reference", prefix))
+	}
+	return subConfig{c: c, prefix: prefix}
+}
+
+type subConfig struct {
+	c      *Config
+	prefix string
+}
+
+func (sc subConfig) Prefix() string {
+	return sc.prefix
+}
+
+func (sc subConfig) MayConfigure(ref string, tmpl interface{}) {
+	sc.c.MayConfigure(sc.prefix+"."+ref, tmpl)
+}
+
+func (sc subConfig) MustConfigure(ref string, tmpl interface{}) {
+	sc.c.MustConfigure(sc.prefix+"."+ref, tmpl)
+}
+
+func (sc subConfig) MayConstruct(ref string, constructedType reflect.Type) {
+	sc.c.MayConstruct(sc.prefix+"."+ref, constructedType)
+}
+
+func (sc subConfig) MustConstruct(ref string, constructedType reflect.Type) {
+	sc.c.MustConstruct(sc.prefix+"."+ref, constructedType)
+}
+
+func (sc subConfig) Get(ref string) any {
+	return sc.c.Get(sc.prefix + "." + ref)
+}
+
+func (sc subConfig) Sub(subPrefix string) ISubConfig {
+	return sc.c.Sub(sc.prefix + "." + subPrefix)
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSub(t *testing.T) {
+	reset(t)
+	db := Sub("ms").Sub("db")
+	if db.Prefix() != "ms.db" {
+		t.Fatalf("prefix=%s", db.Prefix())
+	}
+	db.MustConfigure("host", "")
+	db.MayConfigure("port", 0)
+	RegisterConstructor("hello", testGreeterConfig{})
+	db.MustConstruct("greeter", reflect.TypeOf((*testGreeter)(nil)).Elem())
+	addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{"db": map[string]interface{}{
+		"host":    "db1",
+		"greeter": map[string]interface{}{"hello": map[string]interface{}{"greeting": "hi"}},
+	}}})
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if db.Get("host") != "db1" || Get("ms.db.host") != "db1" {
+		t.Fatalf("host=%v", db.Get("host"))
+	}
+	if db.Get("port") != nil {
+		t.Fatalf("port=%v", db.Get("port"))
+	}
+	if db.Get("greeter").(testGreeter).Greet() != "hi" {
+		t.Fatalf("greeter not constructed")
+	}
+}
+
+func TestSubInvalidPrefix(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("Sub() with invalid prefix did not panic")
+		}
+	}()
+	Sub("ms..db")
+}
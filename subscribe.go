@@ -0,0 +1,65 @@
+package config
+
+import (
+	"sync"
+)
+
+// Subscribe returns a channel that receives the new value of ref each time it changes,
+// like Watch(), for use in select, e.g.
+//
+//	ch := config.Subscribe("db")
+//	defer config.Unsubscribe(ch)
+//	for { select { case v := <-ch: ... case <-ctx.Done(): return } }
+//
+// the channel has space for one value, and a change is dropped and logged
+// when the previous one was not yet received
+// call Unsubscribe() when done, else the subscription remains:
+// it is not removed when the channel is no longer used, because the
+// watcher that sends on the channel keeps it from being garbage collected
+func (c *Config) Subscribe(ref string) <-chan interface{} {
+	s := &subscription{ref: ref, ch: make(chan interface{}, 1)}
+	stop := c.Watch(ref, s.send)
+	c.subscriptionMutex.Lock()
+	defer c.subscriptionMutex.Unlock()
+	c.subscriptionByChan[s.ch] = s
+	s.stop = stop
+	return s.ch
+}
+
+// Unsubscribe stops the subscription and closes the channel from Subscribe()
+// it does nothing if ch is not subscribed
+func (c *Config) Unsubscribe(ch <-chan interface{}) {
+	c.subscriptionMutex.Lock()
+	s, ok := c.subscriptionByChan[ch]
+	delete(c.subscriptionByChan, ch)
+	c.subscriptionMutex.Unlock()
+	if !ok {
+		return
+	}
+	s.stop()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.closed = true
+	close(s.ch)
+}
+
+type subscription struct {
+	ref    string
+	ch     chan interface{}
+	stop   func()
+	mutex  sync.Mutex //so that send() does not write to the closed channel
+	closed bool
+}
+
+func (s *subscription) send(newValue interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.ch <- newValue:
+	default:
+		Logger().Infof("config(%s) change dropped, subscriber did not receive the previous change", s.ref)
+	}
+}
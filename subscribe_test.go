@@ -0,0 +1,62 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSubscribe(t *testing.T) {
+	reset(t)
+	w := captureLog(t)
+	MustConfigure("ms.server", testServerConfig{})
+	s := addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{"server": map[string]interface{}{"port": 8080}}})
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	ch := Subscribe("ms.server")
+	setPort := func(port int) {
+		s.set(map[string]interface{}{"ms": map[string]interface{}{"server": map[string]interface{}{"port": port}}})
+		if err := Reload(); err != nil {
+			t.Fatalf("failed to reload: %+v", err)
+		}
+	}
+
+	setPort(9090)
+	select {
+	case v := <-ch:
+		if v.(testServerConfig).Port != 9090 {
+			t.Fatalf("got %+v", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("no value received")
+	}
+
+	//second change is dropped while the first is not received
+	setPort(1)
+	time.Sleep(20 * time.Millisecond)
+	setPort(2)
+	deadline := time.Now().Add(time.Second)
+	for {
+		w.Lock()
+		dropped := strings.Contains(strings.Join(w.messages, "\n"), "config(ms.server) change dropped")
+		w.Unlock()
+		if dropped {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("change not dropped")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if v := <-ch; v.(testServerConfig).Port != 1 {
+		t.Fatalf("got %+v instead of the first change", v)
+	}
+
+	Unsubscribe(ch)
+	if _, ok := <-ch; ok {
+		t.Fatalf("channel not closed")
+	}
+	setPort(3)
+	Unsubscribe(ch) //does nothing
+}
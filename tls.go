@@ -0,0 +1,90 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+
+	"github.com/go-msvc/errors"
+)
+
+// TLSConfig is the config of TLS certificate files,
+// Create() loads them into a *tls.Config, e.g.:
+//
+//	v, err := config.Struct("server.tls", config.TLSConfig{})
+//	server.TLSConfig = v.(*config.TLSConfig).TLS()
+//
+// it can also be registered with RegisterConstructor() so that Reload()
+// loads the files again when the config changed
+type TLSConfig struct {
+	CertFile string `json:"cert_file"`
+	KeyFile  string `json:"key_file"`
+	CAFile   string `json:"ca_file,omitempty"` //optional, to verify peers
+
+	tlsConfig *tls.Config
+}
+
+// Create loads the files and returns a *TLSConfig with TLS()
+func (c TLSConfig) Create() (interface{}, error) {
+	if c.CertFile == "" || c.KeyFile == "" {
+		return nil, errors.Errorf("TLS config needs cert_file and key_file")
+	}
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot load TLS cert_file(%s) and key_file(%s)", c.CertFile, c.KeyFile)
+	}
+	c.tlsConfig = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if c.CAFile != "" {
+		pool, err := loadCertPool(c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		//used as client for servers and as server for client certs
+		c.tlsConfig.RootCAs = pool
+		c.tlsConfig.ClientCAs = pool
+	}
+	return &c, nil
+} //TLSConfig.Create()
+
+// TLS returns the *tls.Config loaded by Create(), or nil before Create()
+func (c *TLSConfig) TLS() *tls.Config {
+	if c == nil || c.tlsConfig == nil {
+		return nil
+	}
+	return c.tlsConfig.Clone()
+}
+
+// Validate fails if the files do not exist or are not a valid certificate and key
+// it does not fail if no files are set, use `validate:"required"` for that
+func (c TLSConfig) Validate() error {
+	if c.CertFile == "" && c.KeyFile == "" && c.CAFile == "" {
+		return nil
+	}
+	if c.CertFile == "" || c.KeyFile == "" {
+		return errors.Errorf("TLS config needs cert_file and key_file")
+	}
+	if _, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile); err != nil {
+		return errors.Wrapf(err, "invalid TLS cert_file(%s) and key_file(%s)", c.CertFile, c.KeyFile)
+	}
+	if c.CAFile != "" {
+		if _, err := loadCertPool(c.CAFile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func loadCertPool(filename string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read TLS ca_file(%s)", filename)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.Errorf("no certificates in TLS ca_file(%s)", filename)
+	}
+	return pool, nil
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTestCert writes a self-signed cert.pem and key.pem in a temp dir
+func writeTestCert(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to make key: %+v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to make cert: %+v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to encode key: %+v", err)
+	}
+	dir := t.TempDir()
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatalf("failed to write cert: %+v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatalf("failed to write key: %+v", err)
+	}
+	return certFile, keyFile
+}
+
+func TestTLSConfig(t *testing.T) {
+	reset(t)
+	certFile, keyFile := writeTestCert(t)
+	addTestSource(t, map[string]interface{}{"server": map[string]interface{}{"tls": map[string]interface{}{
+		"cert_file": certFile,
+		"key_file":  keyFile,
+		"ca_file":   certFile,
+	}}})
+	v, err := Struct("server.tls", TLSConfig{})
+	if err != nil {
+		t.Fatalf("failed to create: %+v", err)
+	}
+	tlsConfig := v.(*TLSConfig).TLS()
+	if tlsConfig == nil || len(tlsConfig.Certificates) != 1 || tlsConfig.RootCAs == nil {
+		t.Fatalf("tls config %+v", tlsConfig)
+	}
+	if (&TLSConfig{}).TLS() != nil {
+		t.Fatalf("TLS() before Create()")
+	}
+}
+
+func TestTLSConfigInvalid(t *testing.T) {
+	certFile, keyFile := writeTestCert(t)
+	invalidFile := filepath.Join(t.TempDir(), "invalid.pem")
+	if err := os.WriteFile(invalidFile, []byte("not a cert"), 0600); err != nil {
+		t.Fatalf("failed to write: %+v", err)
+	}
+	if err := (TLSConfig{}).Validate(); err != nil {
+		t.Fatalf("empty TLS config not valid: %+v", err)
+	}
+	for _, test := range []struct {
+		config TLSConfig
+		err    string
+	}{
+		{TLSConfig{CertFile: certFile}, "needs cert_file and key_file"},
+		{TLSConfig{CertFile: certFile, KeyFile: filepath.Join(t.TempDir(), "missing.pem")}, "invalid TLS cert_file"},
+		{TLSConfig{CertFile: invalidFile, KeyFile: keyFile}, "invalid TLS cert_file"},
+		{TLSConfig{CertFile: certFile, KeyFile: keyFile, CAFile: invalidFile}, "no certificates"},
+	} {
+		if err := test.config.Validate(); err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Fatalf("%+v: wrong error: %v", test.config, err)
+		}
+		if _, err := test.config.Create(); err == nil {
+			t.Fatalf("%+v: created", test.config)
+		}
+	}
+}
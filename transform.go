@@ -0,0 +1,70 @@
+package config
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/go-msvc/errors"
+)
+
+// AddTransform calls fn to change the value of ref each time it is loaded,
+// before it is validated, e.g. to add "https://" to a URL without a scheme
+// fn gets the value of the type registered with MustConfigure() and must return the same type
+// multiple transforms of the same ref are applied in the order they were added
+// if fn fails, Load() fails, and Reload() keeps the old value and returns the error
+// call the returned func to remove the transform
+func (c *Config) AddTransform(ref string, fn func(value interface{}) (interface{}, error)) func() {
+	if fn == nil {
+		panic("config.AddTransform() called with nil func")
+	}
+	ref = c.resolveAlias(ref)
+	t := &transform{fn: fn}
+
+	c.transformMutex.Lock()
+	defer c.transformMutex.Unlock()
+	c.transformsByRef[ref] = append(c.transformsByRef[ref], t)
+
+	var removeOnce sync.Once
+	return func() {
+		removeOnce.Do(func() {
+			c.transformMutex.Lock()
+			defer c.transformMutex.Unlock()
+			//search all refs because RegisterAlias() may have moved the transform
+			for ref, list := range c.transformsByRef {
+				for i, existing := range list {
+					if existing == t {
+						c.transformsByRef[ref] = append(list[:i:i], list[i+1:]...)
+						break
+					}
+				}
+				if len(c.transformsByRef[ref]) == 0 {
+					delete(c.transformsByRef, ref)
+				}
+			}
+		})
+	}
+} //AddTransform()
+
+type transform struct {
+	fn func(value interface{}) (interface{}, error)
+}
+
+// runTransforms applies all transforms of ref in order
+func (c *Config) runTransforms(ref string, value interface{}) (interface{}, error) {
+	c.transformMutex.Lock()
+	list := append([]*transform{}, c.transformsByRef[ref]...)
+	c.transformMutex.Unlock()
+
+	t := reflect.TypeOf(value)
+	for i, tr := range list {
+		newValue, err := tr.fn(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "transform %d of config(%s) failed", i+1, ref)
+		}
+		if reflect.TypeOf(newValue) != t {
+			return nil, errors.Errorf("transform %d of config(%s) changed %v to %T", i+1, ref, t, newValue)
+		}
+		value = newValue
+	}
+	return value, nil
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-msvc/errors"
+)
+
+type testNameConfig struct {
+	Name string `json:"name"`
+}
+
+func TestAddTransform(t *testing.T) {
+	reset(t)
+	MustConfigure("app", testNameConfig{})
+	s := addTestSource(t, map[string]interface{}{"app": map[string]interface{}{"name": "shop"}})
+	AddTransform("app", func(v interface{}) (interface{}, error) {
+		c := v.(testNameConfig)
+		if c.Name == "" {
+			return nil, errors.Errorf("missing name")
+		}
+		c.Name = strings.ToUpper(c.Name)
+		return c, nil
+	})
+	remove := AddTransform("app", func(v interface{}) (interface{}, error) {
+		c := v.(testNameConfig)
+		c.Name += "!"
+		return c, nil
+	})
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if name := Get("app").(testNameConfig).Name; name != "SHOP!" {
+		t.Fatalf("name=%s", name)
+	}
+
+	//failed transform keeps the old value
+	s.set(map[string]interface{}{"app": map[string]interface{}{"name": ""}})
+	if err := Reload(); err == nil || !strings.Contains(err.Error(), "missing name") {
+		t.Fatalf("unexpected reload error: %v", err)
+	}
+	if name := Get("app").(testNameConfig).Name; name != "SHOP!" {
+		t.Fatalf("name=%s after failed transform", name)
+	}
+
+	remove()
+	s.set(map[string]interface{}{"app": map[string]interface{}{"name": "store"}})
+	if err := Reload(); err != nil {
+		t.Fatalf("failed to reload: %+v", err)
+	}
+	if name := Get("app").(testNameConfig).Name; name != "STORE" {
+		t.Fatalf("name=%s after remove", name)
+	}
+}
+
+func TestAddTransformType(t *testing.T) {
+	reset(t)
+	MustConfigure("app", testNameConfig{})
+	addTestSource(t, map[string]interface{}{"app": map[string]interface{}{"name": "shop"}})
+	AddTransform("app", func(v interface{}) (interface{}, error) {
+		return v.(testNameConfig).Name, nil
+	})
+	if err := Load(); err == nil || !strings.Contains(err.Error(), "changed config.testNameConfig to string") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/go-msvc/errors"
+)
+
+// URL is a url.URL config field written as a string in JSON,
+// because url.URL cannot be unmarshalled from JSON
+//
+//	Database config.URL `json:"database_url" validate:"required"`
+type URL struct {
+	u url.URL
+}
+
+// MustParseURL parses s or panics, e.g. for defaults and tests
+func MustParseURL(s string) URL {
+	u, err := url.Parse(s)
+	if err != nil {
+		panic(errors.Wrapf(err, "invalid URL \"%s\"", s))
+	}
+	return URL{u: *u}
+}
+
+// URL returns a copy of the parsed URL that the caller may modify
+func (u URL) URL() *url.URL {
+	copied := u.u
+	if u.u.User != nil {
+		user := *u.u.User
+		copied.User = &user
+	}
+	return &copied
+}
+
+func (u URL) String() string {
+	return u.u.String()
+}
+
+// Validate fails if the URL is set without a scheme, e.g. "example.com/path"
+// use `validate:"required"` to fail when not set
+func (u URL) Validate() error {
+	if u.u != (url.URL{}) && u.u.Scheme == "" {
+		return errors.Errorf("URL \"%s\" has no scheme", u.u.String())
+	}
+	return nil
+}
+
+func (u URL) MarshalJSON() ([]byte, error) {
+	return json.Marshal(u.u.String())
+}
+
+func (u *URL) UnmarshalJSON(value []byte) error {
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return errors.Wrapf(err, "URL must be a string")
+	}
+	parsed, err := url.Parse(s)
+	if err != nil {
+		return errors.Wrapf(err, "invalid URL \"%s\"", s)
+	}
+	u.u = *parsed
+	return nil
+}
+
+// MustSchemes returns a func that fails if the URL scheme is not one of allowed,
+// e.g. to use in a Validate() method or with AddValidator():
+//
+//	checkScheme := config.MustSchemes("https")
+//	config.AddValidator("api.url", func(v interface{}) error { u := v.(config.URL); return checkScheme(&u) })
+func MustSchemes(allowed ...string) func(*URL) error {
+	if len(allowed) == 0 {
+		panic("config.MustSchemes() called without schemes")
+	}
+	return func(u *URL) error {
+		if u == nil {
+			return errors.Errorf("missing URL")
+		}
+		for _, scheme := range allowed {
+			if strings.EqualFold(u.u.Scheme, scheme) {
+				return nil
+			}
+		}
+		return errors.Errorf("URL scheme \"%s\" is not one of %s", u.u.Scheme, strings.Join(allowed, "|"))
+	}
+}
+
+var urlType = reflect.TypeOf(URL{})
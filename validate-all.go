@@ -0,0 +1,22 @@
+package config
+
+import (
+	"context"
+)
+
+// ValidateAll reads and validates all registered config from the sources
+// like Load() does, but does not call Create() and does not change the live config,
+// e.g. for a --validate-config option in CI/CD that must exit with all errors
+// it returns ConfigErrors for all missing and invalid values
+// Load() and Reload() can still be called after it
+func (c *Config) ValidateAll() error {
+	return c.ValidateAllContext(context.Background())
+}
+
+// ValidateAllContext is ValidateAll() that stops when ctx is done
+func (c *Config) ValidateAllContext(ctx context.Context) error {
+	c.moduleDataMutex.Lock()
+	defer c.moduleDataMutex.Unlock()
+	_, err := c.load(ctx, false)
+	return err
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestValidateAll(t *testing.T) {
+	reset(t)
+	atomic.StoreInt32(&testGreeterCreates, 0)
+	MustConfigure("ms.server", testServerConfig{})
+	RegisterConstructor("hello", testGreeterConfig{})
+	MustConstruct("ms.greeter", reflect.TypeOf((*testGreeter)(nil)).Elem())
+	s := addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{
+		"greeter": map[string]interface{}{"hello": map[string]interface{}{"greeting": "hi"}},
+	}})
+
+	//missing ms.server
+	err := ValidateAll()
+	if err == nil {
+		t.Fatalf("validated without required config")
+	}
+	errs, ok := err.(ConfigErrors)
+	if !ok || len(errs) != 1 || errs[0].Key != "ms.server" {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if defaultConfig.loaded {
+		t.Fatalf("loaded after ValidateAll()")
+	}
+
+	s.set(map[string]interface{}{"ms": map[string]interface{}{
+		"server":  map[string]interface{}{"port": 8080},
+		"greeter": map[string]interface{}{"hello": map[string]interface{}{"greeting": "hi"}},
+	}})
+	if err := ValidateAll(); err != nil {
+		t.Fatalf("failed to validate: %+v", err)
+	}
+	if n := atomic.LoadInt32(&testGreeterCreates); n != 0 {
+		t.Fatalf("ValidateAll() constructed %d items", n)
+	}
+	if defaultConfig.loaded {
+		t.Fatalf("loaded after ValidateAll()")
+	}
+
+	//still loads normally
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if Get("ms.greeter").(testGreeter).Greet() != "hi" {
+		t.Fatalf("wrong greeter")
+	}
+}
+
+func TestValidateAllContext(t *testing.T) {
+	reset(t)
+	MustConfigure("ms.server", testServerConfig{})
+	addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{"server": map[string]interface{}{"port": 8080}}})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := ValidateAllContext(ctx); err == nil {
+		t.Fatalf("validated with cancelled ctx")
+	}
+}
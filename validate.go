@@ -0,0 +1,190 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-msvc/data"
+	"github.com/go-msvc/errors"
+)
+
+// validateStruct checks the `validate:"..."` tags on all fields of a struct
+// and nested structs, e.g.:
+//
+//	Host  string `json:"host" validate:"required"`
+//	Port  int    `json:"port" validate:"min=1,max=65535"`
+//	Name  string `json:"name" validate:"regexp=^[a-z]+$"`
+//	Level string `json:"level" validate:"oneof=debug info warn error"`
+//
+// constraints are comma separated, except regexp which must be last
+// because the rest of the tag is used as the pattern
+// min and max apply to numbers, or to the length of strings, slices and maps
+// values that are not structs are not checked
+func validateStruct(v interface{}) error {
+	return validateValue(reflect.ValueOf(v), "")
+}
+
+func validateValue(v reflect.Value, path string) error {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	//all fields are checked to report all invalid fields at once
+	errs := ValidatorErrors{}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _ := JSONName(f)
+		if name == "" {
+			continue
+		}
+		if path != "" {
+			name = path + "." + name
+		}
+		if tag := FieldTag(f, "validate"); tag != "" {
+			if err := validateField(v.Field(i), tag); err != nil {
+				errs = append(errs, errors.Wrapf(err, "invalid field(%s)", name))
+				continue
+			}
+		}
+		if validator, ok := fieldValidator(v.Field(i)); ok {
+			if err := validator.Validate(); err != nil {
+				errs = append(errs, errors.Wrapf(err, "invalid field(%s)", name))
+				continue
+			}
+		}
+		if err := validateValue(v.Field(i), name); err != nil {
+			errs = append(errs, err.(ValidatorErrors)...)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+} //validateValue()
+
+// fieldValidator returns the field if it implements Validate(), e.g. config.IP
+// the top level value is already validated by data.GetInto()
+func fieldValidator(v reflect.Value) (data.Validator, bool) {
+	if !v.CanInterface() || (v.Kind() == reflect.Ptr && v.IsNil()) {
+		return nil, false
+	}
+	validator, ok := v.Interface().(data.Validator)
+	return validator, ok
+}
+
+// validateConstraint is one constraint from a `validate:"..."` tag
+// e.g. "min=1" is {name:"min", arg:"1"}
+type validateConstraint struct {
+	name string
+	arg  string
+}
+
+func parseValidateTag(tag string) []validateConstraint {
+	constraints := []validateConstraint{}
+	for tag != "" {
+		var constraint string
+		if strings.HasPrefix(tag, "regexp=") {
+			constraint, tag = tag, ""
+		} else {
+			constraint, tag, _ = strings.Cut(tag, ",")
+		}
+		name, arg, _ := strings.Cut(strings.TrimSpace(constraint), "=")
+		constraints = append(constraints, validateConstraint{name: name, arg: arg})
+	}
+	return constraints
+}
+
+func validateField(v reflect.Value, tag string) error {
+	//pointers are checked by their value, and other than required
+	//the constraints do not apply when the pointer is nil
+	//a pointer to a zero value is not required, e.g. *int may point to 0
+	isPtr := v.Kind() == reflect.Ptr
+	isNil := false
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			isNil = true
+			break
+		}
+		v = v.Elem()
+	}
+	for _, c := range parseValidateTag(tag) {
+		name, arg := c.name, c.arg
+		if isNil && name != "required" {
+			continue
+		}
+		switch name {
+		case "required":
+			if isNil || (!isPtr && v.IsZero()) {
+				return errors.Errorf("required")
+			}
+		case "min", "max":
+			limit, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				return errors.Errorf("invalid constraint %s=\"%s\"", name, arg)
+			}
+			n, ok := numericValue(v)
+			if !ok {
+				return errors.Errorf("constraint %s does not apply to %v", name, v.Kind())
+			}
+			if name == "min" && n < limit {
+				return errors.Errorf("%v < min %v", n, limit)
+			}
+			if name == "max" && n > limit {
+				return errors.Errorf("%v > max %v", n, limit)
+			}
+		case "regexp":
+			if v.Kind() != reflect.String {
+				return errors.Errorf("constraint regexp does not apply to %v", v.Kind())
+			}
+			re, err := regexp.Compile(arg)
+			if err != nil {
+				return errors.Wrapf(err, "invalid constraint regexp=\"%s\"", arg)
+			}
+			if !re.MatchString(v.String()) {
+				return errors.Errorf("\"%s\" does not match %s", v.String(), arg)
+			}
+		case "oneof":
+			s := fmt.Sprintf("%v", v.Interface())
+			found := false
+			for _, option := range strings.Fields(arg) {
+				if s == option {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return errors.Errorf("\"%s\" is not one of %s", s, strings.Join(strings.Fields(arg), "|"))
+			}
+		default:
+			return errors.Errorf("unknown constraint \"%s\"", name)
+		}
+	}
+	return nil
+} //validateField()
+
+// numericValue returns numbers as float, and the length of strings, slices and maps
+func numericValue(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return float64(v.Len()), true
+	}
+	return 0, false
+}
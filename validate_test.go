@@ -0,0 +1,136 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+type testValidateConfig struct {
+	Host    string            `json:"host" validate:"required"`
+	Port    int               `json:"port" validate:"min=1,max=65535"`
+	Name    string            `json:"name" validate:"min=2,max=5"`
+	Tags    []string          `json:"tags" validate:"max=2"`
+	Level   string            `json:"level" validate:"oneof=debug info error"`
+	Code    string            `json:"code" validate:"regexp=^[a-z]+,[0-9]+$"`
+	Timeout *int              `json:"timeout" validate:"min=1,max=60"`
+	Mode    *string           `json:"mode" validate:"oneof=fast slow"`
+	Retries *int              `json:"retries" validate:"required"`
+	Nested  testNestedConfig  `json:"nested"`
+	Labels  map[string]string `json:"labels,omitempty" validate:"max=1"`
+}
+
+type testNestedConfig struct {
+	Weight float64 `json:"weight" validate:"max=1.5"`
+}
+
+func validTestValidateConfig() testValidateConfig {
+	zero := 0
+	return testValidateConfig{
+		Host:    "localhost",
+		Port:    80,
+		Name:    "abc",
+		Level:   "info",
+		Code:    "abc,123",
+		Retries: &zero, //pointer to zero value is set
+	}
+}
+
+func TestValidate(t *testing.T) {
+	intPtr := func(i int) *int { return &i }
+	strPtr := func(s string) *string { return &s }
+	tests := map[string]struct {
+		change func(c *testValidateConfig)
+		err    string
+	}{
+		"valid":             {change: func(c *testValidateConfig) {}},
+		"required":          {change: func(c *testValidateConfig) { c.Host = "" }, err: "invalid field(host) because required"},
+		"min":               {change: func(c *testValidateConfig) { c.Port = 0 }, err: "invalid field(port) because 0 < min 1"},
+		"max":               {change: func(c *testValidateConfig) { c.Port = 65536 }, err: "invalid field(port) because 65536 > max 65535"},
+		"min length":        {change: func(c *testValidateConfig) { c.Name = "a" }, err: "invalid field(name) because 1 < min 2"},
+		"max length":        {change: func(c *testValidateConfig) { c.Name = "abcdef" }, err: "invalid field(name) because 6 > max 5"},
+		"max slice length":  {change: func(c *testValidateConfig) { c.Tags = []string{"a", "b", "c"} }, err: "invalid field(tags) because 3 > max 2"},
+		"max map length":    {change: func(c *testValidateConfig) { c.Labels = map[string]string{"a": "1", "b": "2"} }, err: "invalid field(labels) because 2 > max 1"},
+		"oneof":             {change: func(c *testValidateConfig) { c.Level = "warn" }, err: "invalid field(level) because \"warn\" is not one of debug|info|error"},
+		"regexp":            {change: func(c *testValidateConfig) { c.Code = "ABC,1" }, err: "invalid field(code) because \"ABC,1\" does not match ^[a-z]+,[0-9]+$"},
+		"nested":            {change: func(c *testValidateConfig) { c.Nested.Weight = 2 }, err: "invalid field(nested.weight) because 2 > max 1.5"},
+		"pointer valid":     {change: func(c *testValidateConfig) { c.Timeout = intPtr(30) }},
+		"pointer min":       {change: func(c *testValidateConfig) { c.Timeout = intPtr(0) }, err: "invalid field(timeout) because 0 < min 1"},
+		"pointer max":       {change: func(c *testValidateConfig) { c.Timeout = intPtr(61) }, err: "invalid field(timeout) because 61 > max 60"},
+		"pointer oneof":     {change: func(c *testValidateConfig) { c.Mode = strPtr("slow") }},
+		"pointer not oneof": {change: func(c *testValidateConfig) { c.Mode = strPtr("medium") }, err: "invalid field(mode) because \"medium\" is not one of fast|slow"},
+		"pointer required":  {change: func(c *testValidateConfig) { c.Retries = nil }, err: "invalid field(retries) because required"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := validTestValidateConfig()
+			test.change(&c)
+			err := validateStruct(&c)
+			if test.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %+v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("expected error %q, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestValidateInvalidTag(t *testing.T) {
+	type badMin struct {
+		Port int `json:"port" validate:"min=x"`
+	}
+	type badKind struct {
+		Flag bool `json:"flag" validate:"max=1"`
+	}
+	type badName struct {
+		Other int `json:"other" validate:"unknown"`
+	}
+	for _, test := range []struct {
+		value interface{}
+		err   string
+	}{
+		{badMin{}, "invalid constraint min=\"x\""},
+		{badKind{}, "constraint max does not apply to bool"},
+		{badName{}, "unknown constraint \"unknown\""},
+	} {
+		if err := validateStruct(test.value); err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Fatalf("expected error %q, got %v", test.err, err)
+		}
+	}
+}
+
+func TestValidateOnLoad(t *testing.T) {
+	reset(t)
+	MustConfigure("ms.server", testValidateConfig{})
+	addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{"server": map[string]interface{}{"host": "localhost", "port": 0}}})
+	err := Load()
+	if err == nil || !strings.Contains(err.Error(), "invalid source(test).config(ms.server)") {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
+
+func TestValidateAllFields(t *testing.T) {
+	c := validTestValidateConfig()
+	c.Host = ""
+	c.Nested.Weight = 2
+	err := validateStruct(c)
+	if err == nil || err.Error() != "invalid field(host) because required; invalid field(nested.weight) because 2 > max 1.5" {
+		t.Fatalf("expected both fields, got %v", err)
+	}
+}
+
+func TestParseValidateTag(t *testing.T) {
+	constraints := parseValidateTag("required,min=1,regexp=^a,b$")
+	expected := []validateConstraint{{name: "required"}, {name: "min", arg: "1"}, {name: "regexp", arg: "^a,b$"}}
+	if len(constraints) != len(expected) {
+		t.Fatalf("got %+v", constraints)
+	}
+	for i := range expected {
+		if constraints[i] != expected[i] {
+			t.Fatalf("got %+v", constraints)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"strings"
+	"sync"
+)
+
+// AddValidator calls fn to validate the value of name each time it is loaded,
+// after validate tags and Validate() were checked
+// use it for types where you cannot add a Validate() method,
+// e.g. config.AddValidator("db.port", func(v interface{}) error { ... v.(int) ... })
+// fn gets the value of the type registered with MustConfigure()
+// or the constructor config for MustConstruct()
+// multiple validators of the same name are all called and their errors returned together
+// call the returned func to remove the validator
+func (c *Config) AddValidator(name string, fn func(value interface{}) error) func() {
+	if fn == nil {
+		panic("config.AddValidator() called with nil func")
+	}
+	name = c.resolveAlias(name)
+	v := &validator{fn: fn}
+
+	c.validatorMutex.Lock()
+	defer c.validatorMutex.Unlock()
+	c.validatorsByName[name] = append(c.validatorsByName[name], v)
+
+	var removeOnce sync.Once
+	return func() {
+		removeOnce.Do(func() {
+			c.validatorMutex.Lock()
+			defer c.validatorMutex.Unlock()
+			//search all names because RegisterAlias() may have moved the validator
+			for name, list := range c.validatorsByName {
+				for i, existing := range list {
+					if existing == v {
+						c.validatorsByName[name] = append(list[:i:i], list[i+1:]...)
+						break
+					}
+				}
+				if len(c.validatorsByName[name]) == 0 {
+					delete(c.validatorsByName, name)
+				}
+			}
+		})
+	}
+} //AddValidator()
+
+type validator struct {
+	fn func(value interface{}) error
+}
+
+// ValidatorErrors are all errors from validating one value,
+// i.e. all invalid fields or all failed AddValidator() funcs
+type ValidatorErrors []error
+
+func (errs ValidatorErrors) Error() string {
+	s := make([]string, len(errs))
+	for i, err := range errs {
+		s[i] = err.Error()
+	}
+	return strings.Join(s, "; ")
+}
+
+func (errs ValidatorErrors) Unwrap() []error {
+	return errs
+}
+
+// runValidators calls all validators of name and returns ValidatorErrors if any failed
+func (c *Config) runValidators(name string, value interface{}) error {
+	c.validatorMutex.Lock()
+	list := append([]*validator{}, c.validatorsByName[name]...)
+	c.validatorMutex.Unlock()
+
+	errs := ValidatorErrors{}
+	for _, v := range list {
+		if err := v.fn(value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-msvc/errors"
+)
+
+func TestAddValidator(t *testing.T) {
+	reset(t)
+	MustConfigure("db.port", 0)
+	addTestSource(t, map[string]interface{}{"db": map[string]interface{}{"port": 80}})
+	removeMin := AddValidator("db.port", func(v interface{}) error {
+		if v.(int) < 1024 {
+			return errors.Errorf("port must be >= 1024")
+		}
+		return nil
+	})
+	AddValidator("db.port", func(v interface{}) error {
+		if v.(int)%2 == 0 {
+			return errors.Errorf("port must be odd")
+		}
+		return nil
+	})
+	err := Load()
+	if err == nil {
+		t.Fatalf("loaded invalid port")
+	}
+	if !strings.Contains(err.Error(), "port must be >= 1024; port must be odd") {
+		t.Fatalf("wrong error: %v", err)
+	}
+
+	removeMin()
+	if err := Load(); err == nil || strings.Contains(err.Error(), ">= 1024") {
+		t.Fatalf("removed validator still called: %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/go-msvc/errors"
+)
+
+// Version returns the number of times config was loaded successfully
+// it is 0 before Load(), 1 after Load() and incremented on each Reload()
+// so you can tell if values you got from Get() may be stale
+func (c *Config) Version() uint64 {
+	c.versionMutex.Lock()
+	defer c.versionMutex.Unlock()
+	return c.version
+}
+
+// VersionOf returns the Version() in which the current value of ref
+// was loaded, i.e. the last version in which it changed
+// it returns false if ref is not loaded
+func (c *Config) VersionOf(ref string) (uint64, bool) {
+	ref = c.resolveAlias(ref)
+	c.versionMutex.Lock()
+	defer c.versionMutex.Unlock()
+	v, ok := c.versionByRef[ref]
+	return v, ok
+}
+
+// OnVersion calls fn once in a goroutine when Version() reaches targetVersion
+// e.g. in tests to wait for a reload
+// if the version was already reached, fn is called immediately
+func (c *Config) OnVersion(targetVersion uint64, fn func()) {
+	c.versionMutex.Lock()
+	defer c.versionMutex.Unlock()
+	if c.version >= targetVersion {
+		go fn()
+		return
+	}
+	c.versionWaiters = append(c.versionWaiters, versionWaiter{target: targetVersion, fn: fn})
+}
+
+// LoadedAt returns when config was last read from the sources successfully
+// by Load() or Reload(), also when no values changed
+// it is the zero time before Load()
+func (c *Config) LoadedAt() time.Time {
+	c.versionMutex.Lock()
+	defer c.versionMutex.Unlock()
+	return c.loadedAt
+}
+
+// Age returns the time since LoadedAt(), e.g. to detect that sources could
+// not be read for a while and values may be stale
+// it is 0 before Load()
+func (c *Config) Age() time.Duration {
+	loadedAt := c.LoadedAt()
+	if loadedAt.IsZero() {
+		return 0
+	}
+	return time.Since(loadedAt)
+}
+
+// CheckMaxAge fails if config was not loaded or is older than maxAge,
+// e.g. to enter degraded mode rather than use expired credentials
+func (c *Config) CheckMaxAge(maxAge time.Duration) error {
+	loadedAt := c.LoadedAt()
+	if loadedAt.IsZero() {
+		return errors.Errorf("config not loaded")
+	}
+	if age := time.Since(loadedAt); age > maxAge {
+		return errors.Errorf("config loaded %v ago is older than %v", age.Round(time.Millisecond), maxAge)
+	}
+	return nil
+}
+
+type versionWaiter struct {
+	target uint64
+	fn     func()
+}
+
+// nextVersion increments the version after a successful load
+// and records the version of each value that changed
+func (c *Config) nextVersion(oldConfigByRef, newConfigByRef map[string]interface{}) {
+	c.versionMutex.Lock()
+	defer c.versionMutex.Unlock()
+	c.version++
+	c.loadedAt = time.Now()
+	for ref, newValue := range newConfigByRef {
+		if oldValue, ok := oldConfigByRef[ref]; !ok || !reflect.DeepEqual(oldValue, newValue) {
+			c.versionByRef[ref] = c.version
+		}
+	}
+	for ref := range c.versionByRef {
+		if _, ok := newConfigByRef[ref]; !ok {
+			delete(c.versionByRef, ref)
+		}
+	}
+
+	waiters := c.versionWaiters[:0]
+	for _, w := range c.versionWaiters {
+		if c.version >= w.target {
+			go w.fn()
+		} else {
+			waiters = append(waiters, w)
+		}
+	}
+	c.versionWaiters = waiters
+} //nextVersion()
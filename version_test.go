@@ -0,0 +1,95 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVersion(t *testing.T) {
+	reset(t)
+	MustConfigure("ms.name", "")
+	MustConfigure("ms.port", 0)
+	s := addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{"name": "a", "port": 1}})
+	if Version() != 0 {
+		t.Fatalf("Version=%d before load", Version())
+	}
+	if _, ok := VersionOf("ms.name"); ok {
+		t.Fatalf("VersionOf found before load")
+	}
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if Version() != 1 {
+		t.Fatalf("Version=%d after load", Version())
+	}
+
+	reloaded := make(chan struct{})
+	OnVersion(2, func() { close(reloaded) })
+	s.set(map[string]interface{}{"ms": map[string]interface{}{"name": "b", "port": 1}})
+	if err := Reload(); err != nil {
+		t.Fatalf("failed to reload: %+v", err)
+	}
+	select {
+	case <-reloaded:
+	case <-time.After(time.Second):
+		t.Fatalf("OnVersion(2) not called")
+	}
+	if Version() != 2 {
+		t.Fatalf("Version=%d after reload", Version())
+	}
+	if v, ok := VersionOf("ms.name"); !ok || v != 2 {
+		t.Fatalf("changed VersionOf=%d,%v", v, ok)
+	}
+	if v, ok := VersionOf("ms.port"); !ok || v != 1 {
+		t.Fatalf("unchanged VersionOf=%d,%v", v, ok)
+	}
+
+	//already reached
+	called := make(chan struct{})
+	OnVersion(1, func() { close(called) })
+	select {
+	case <-called:
+	case <-time.After(time.Second):
+		t.Fatalf("OnVersion(1) not called")
+	}
+}
+
+func TestLoadedAt(t *testing.T) {
+	reset(t)
+	MustConfigure("ms.name", "")
+	s := addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{"name": "a"}})
+	if !LoadedAt().IsZero() || Age() != 0 || CheckMaxAge(time.Hour) == nil {
+		t.Fatalf("loaded at %v before load", LoadedAt())
+	}
+	before := time.Now()
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	loadedAt := LoadedAt()
+	if loadedAt.Before(before) || loadedAt.After(time.Now()) {
+		t.Fatalf("loaded at %v, expected after %v", loadedAt, before)
+	}
+	if err := CheckMaxAge(time.Hour); err != nil {
+		t.Fatalf("stale after load: %+v", err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	if Age() < 2*time.Millisecond || CheckMaxAge(time.Millisecond) == nil {
+		t.Fatalf("age %v", Age())
+	}
+
+	//updated by reload also when nothing changed, but not by failed reload
+	if err := Reload(); err != nil {
+		t.Fatalf("failed to reload: %+v", err)
+	}
+	reloadedAt := LoadedAt()
+	if !reloadedAt.After(loadedAt) {
+		t.Fatalf("reloaded at %v, loaded at %v", reloadedAt, loadedAt)
+	}
+	s.set(map[string]interface{}{})
+	if err := Reload(); err == nil {
+		t.Fatalf("reloaded without value")
+	}
+	if LoadedAt() != reloadedAt {
+		t.Fatalf("failed reload changed loaded at")
+	}
+}
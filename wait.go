@@ -0,0 +1,82 @@
+package config
+
+import (
+	"context"
+
+	"github.com/go-msvc/errors"
+)
+
+// GetContext is Get() that waits for Load() when called before Load() completed,
+// e.g. from a goroutine started before main() loaded config,
+// and returns an error instead of panic
+// if ctx is done before config was loaded, the error wraps ctx.Err()
+func (c *Config) GetContext(ctx context.Context, ref string) (interface{}, error) {
+	select {
+	case <-c.waitLoaded():
+	case <-ctx.Done():
+		return nil, errors.Wrapf(ctx.Err(), "config(%s) not loaded", ref)
+	}
+	return c.getLoaded(ref)
+}
+
+// waitLoaded returns a chan that is closed when Load() completed
+func (c *Config) waitLoaded() <-chan struct{} {
+	c.configByRefMutex.RLock()
+	defer c.configByRefMutex.RUnlock()
+	return c.loadedChan
+}
+
+// WaitReady waits until Load() completed, e.g. for a readiness probe
+// where the handler responds 503 until it returns nil:
+//
+//	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+//	defer cancel()
+//	if err := config.WaitReady(ctx); err != nil { ... 503 ... }
+//
+// it returns an error wrapping ctx.Err() if ctx is done before config was loaded
+func (c *Config) WaitReady(ctx context.Context) error {
+	select {
+	case <-c.waitLoaded():
+		return nil
+	case <-ctx.Done():
+		return errors.Wrapf(ctx.Err(), "config not loaded")
+	}
+}
+
+// Ready is true when Load() completed
+func (c *Config) Ready() bool {
+	select {
+	case <-c.waitLoaded():
+		return true
+	default:
+		return false
+	}
+}
+
+// ReadyCount returns the nr of loaded values and the nr of values registered
+// with MustConfigure(), MayConfigure(), MustConstruct() and MayConstruct()
+// because Load() loads all or nothing, ready is either 0 or total
+func (c *Config) ReadyCount() (ready, total int) {
+	c.configByRefMutex.RLock()
+	if c.loaded {
+		n := len(c.configByRef)
+		c.configByRefMutex.RUnlock()
+		return n, n
+	}
+	c.configByRefMutex.RUnlock()
+
+	c.moduleDataMutex.Lock()
+	defer c.moduleDataMutex.Unlock()
+	refs := map[string]bool{}
+	for ref := range c.mustConfigureByRef {
+		refs[ref] = true
+	}
+	for _, info := range c.constructorsByType {
+		info.Lock()
+		for ref := range info.mustConstructByRef {
+			refs[ref] = true
+		}
+		info.Unlock()
+	}
+	return 0, len(refs)
+} //ReadyCount()
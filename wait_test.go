@@ -0,0 +1,77 @@
+package config
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestGetContext(t *testing.T) {
+	reset(t)
+	MustConfigure("ms.name", "")
+	addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{"name": "test"}})
+
+	//times out before load
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := GetContext(ctx, "ms.name"); err == nil {
+		t.Fatalf("got value before load")
+	}
+
+	//waits for load
+	result := make(chan interface{})
+	go func() {
+		value, err := GetContext(context.Background(), "ms.name")
+		if err != nil {
+			value = err
+		}
+		result <- value
+	}()
+	time.Sleep(10 * time.Millisecond)
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if value := <-result; value != "test" {
+		t.Fatalf("got %v", value)
+	}
+	if _, err := GetContext(context.Background(), "ms.unknown"); err == nil {
+		t.Fatalf("got unknown value")
+	}
+}
+
+func TestWaitReady(t *testing.T) {
+	reset(t)
+	MustConfigure("ms.name", "")
+	MayConfigure("ms.code", "")
+	MustConstruct("ms.greeter", testGreeterType)
+	RegisterConstructor("hello", testGreeterConfig{})
+	addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{"name": "test", "greeter": map[string]interface{}{"hello": map[string]interface{}{"greeting": "hi"}}}})
+	if Ready() {
+		t.Fatalf("ready before load")
+	}
+	if ready, total := ReadyCount(); ready != 0 || total != 3 {
+		t.Fatalf("ReadyCount()=%d,%d", ready, total)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := WaitReady(ctx); err == nil {
+		t.Fatalf("ready before load")
+	}
+
+	done := make(chan error)
+	go func() { done <- WaitReady(context.Background()) }()
+	select {
+	case err := <-done:
+		t.Fatalf("WaitReady() returned %v before load", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("WaitReady() failed: %+v", err)
+	}
+	if ready, total := ReadyCount(); !Ready() || ready != 3 || total != 3 {
+		t.Fatalf("ReadyCount()=%d,%d", ready, total)
+	}
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Watch calls fn in a goroutine each time the value of ref changes after Reload()
+// if called before Load(), fn is also called when the value is first loaded
+// fn is called with nil when the value no longer exists, e.g. optional config
+// that was removed from the source
+// multiple watchers of the same ref are called in the order they were added
+// call the returned func to stop watching
+func (c *Config) Watch(ref string, fn func(newValue interface{})) func() {
+	if fn == nil {
+		panic("config.Watch() called with nil func")
+	}
+	ref = c.resolveAlias(ref)
+	w := &watcher{fn: fn}
+
+	c.watchMutex.Lock()
+	defer c.watchMutex.Unlock()
+	c.watchersByRef[ref] = append(c.watchersByRef[ref], w)
+
+	var stopOnce sync.Once
+	return func() {
+		stopOnce.Do(func() {
+			c.watchMutex.Lock()
+			defer c.watchMutex.Unlock()
+			//search all refs because RegisterAlias() may have moved the watcher
+			for ref, list := range c.watchersByRef {
+				for i, existing := range list {
+					if existing == w {
+						c.watchersByRef[ref] = append(list[:i:i], list[i+1:]...)
+						break
+					}
+				}
+				if len(c.watchersByRef[ref]) == 0 {
+					delete(c.watchersByRef, ref)
+				}
+			}
+		})
+	}
+} //Watch()
+
+type watcher struct {
+	fn func(newValue interface{})
+}
+
+// notifyWatchers calls the watchers of all values that differ between old and new
+func (c *Config) notifyWatchers(oldConfigByRef, newConfigByRef map[string]interface{}) {
+	c.watchMutex.Lock()
+	defer c.watchMutex.Unlock()
+	for ref, list := range c.watchersByRef {
+		oldValue := oldConfigByRef[ref]
+		newValue := newConfigByRef[ref]
+		if reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		Logger().Debugf("Changed(%s): %d watchers", ref, len(list))
+		go func(list []*watcher, newValue interface{}) {
+			for _, w := range list {
+				w.fn(newValue)
+			}
+		}(append([]*watcher{}, list...), newValue)
+	}
+} //notifyWatchers()
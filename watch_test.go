@@ -0,0 +1,131 @@
+package config
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	reset(t)
+	MustConfigure("ms.name", "")
+	MayConfigure("ms.label", "")
+	s := addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{"name": "a", "label": "x"}})
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	names := make(chan interface{}, 10)
+	labels := make(chan interface{}, 10)
+	stopName := Watch("ms.name", func(v interface{}) { names <- v })
+	defer Watch("ms.label", func(v interface{}) { labels <- v })()
+
+	//changed name and removed optional label
+	s.set(map[string]interface{}{"ms": map[string]interface{}{"name": "b"}})
+	if err := Reload(); err != nil {
+		t.Fatalf("failed to reload: %+v", err)
+	}
+	if v := waitFor(t, names); v != "b" {
+		t.Fatalf("name watcher got %v", v)
+	}
+	if v := waitFor(t, labels); v != nil {
+		t.Fatalf("label watcher got %v", v)
+	}
+
+	//stopped watchers are not called
+	stopName()
+	stopName()
+	s.set(map[string]interface{}{"ms": map[string]interface{}{"name": "c"}})
+	if err := Reload(); err != nil {
+		t.Fatalf("failed to reload: %+v", err)
+	}
+	select {
+	case v := <-names:
+		t.Fatalf("stopped watcher got %v", v)
+	case v := <-labels:
+		t.Fatalf("unchanged watcher got %v", v)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestWatchUnchanged(t *testing.T) {
+	reset(t)
+	MustConfigure("ms.server", testServerConfig{})
+	addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{"server": map[string]interface{}{"host": "a", "port": 1}}})
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	called := int32(0)
+	defer Watch("ms.server", func(interface{}) { atomic.AddInt32(&called, 1) })()
+	if err := Reload(); err != nil {
+		t.Fatalf("failed to reload: %+v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if n := atomic.LoadInt32(&called); n != 0 {
+		t.Fatalf("watcher called %d times for unchanged value", n)
+	}
+}
+
+func TestWatchNil(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("Watch() with nil func did not panic")
+		}
+	}()
+	Watch("ms.name", nil)
+}
+
+func waitFor(t *testing.T, c chan interface{}) interface{} {
+	t.Helper()
+	select {
+	case v := <-c:
+		return v
+	case <-time.After(time.Second):
+		t.Fatalf("watcher not called")
+		return nil
+	}
+}
+
+func TestWatchBeforeLoad(t *testing.T) {
+	reset(t)
+	MustConfigure("ms.name", "")
+	MayConfigure("ms.label", "")
+	addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{"name": "a"}})
+	names := make(chan interface{}, 10)
+	labels := make(chan interface{}, 10)
+	defer Watch("ms.name", func(v interface{}) { names <- v })()
+	defer Watch("ms.label", func(v interface{}) { labels <- v })()
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if v := waitFor(t, names); v != "a" {
+		t.Fatalf("watcher got %v on load", v)
+	}
+	select {
+	case v := <-labels:
+		t.Fatalf("watcher of absent value got %v on load", v)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestWatchOrder(t *testing.T) {
+	reset(t)
+	MustConfigure("ms.name", "")
+	s := addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{"name": "a"}})
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	order := make(chan interface{}, 10)
+	for i := 0; i < 5; i++ {
+		i := i
+		defer Watch("ms.name", func(interface{}) { order <- i })()
+	}
+	s.set(map[string]interface{}{"ms": map[string]interface{}{"name": "b"}})
+	if err := Reload(); err != nil {
+		t.Fatalf("failed to reload: %+v", err)
+	}
+	for i := 0; i < 5; i++ {
+		if v := waitFor(t, order); v != i {
+			t.Fatalf("watcher %v called as number %d", v, i)
+		}
+	}
+}
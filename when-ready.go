@@ -0,0 +1,36 @@
+package config
+
+// WhenReady calls fn once in a goroutine with the value of ref when Load() completed,
+// or immediately in a goroutine if it already completed
+// unlike Watch(), it is not called again after Reload()
+// if ref was not registered with MustConfigure() or MustConstruct(), the error is logged
+// and fn is not called
+func (c *Config) WhenReady(ref string, fn func(interface{})) {
+	if fn == nil {
+		panic("config.WhenReady() called with nil func")
+	}
+	loaded := c.waitLoaded()
+	go func() {
+		<-loaded
+		value, err := c.getLoaded(ref)
+		if err != nil {
+			Logger().Errorf("WhenReady(%s): %+v", ref, err)
+			return
+		}
+		fn(value)
+	}()
+}
+
+// WhenAllReady calls fn once in a goroutine when all registered config was loaded,
+// or immediately in a goroutine if it already was
+// because Load() loads all or nothing, that is when Load() completed
+func (c *Config) WhenAllReady(fn func()) {
+	if fn == nil {
+		panic("config.WhenAllReady() called with nil func")
+	}
+	loaded := c.waitLoaded()
+	go func() {
+		<-loaded
+		fn()
+	}()
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWhenReady(t *testing.T) {
+	reset(t)
+	s := addTestSource(t, map[string]interface{}{"ms": map[string]interface{}{"name": "shop"}})
+	MustConfigure("ms.name", "")
+
+	var mutex sync.Mutex
+	calls := []string{}
+	record := func(call string) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		calls = append(calls, call)
+	}
+	count := func() int {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(calls)
+	}
+	waitCalls := func(n int) {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for count() < n {
+			if time.Now().After(deadline) {
+				t.Fatalf("%d calls instead of %d: %v", count(), n, calls)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	WhenReady("ms.name", func(v interface{}) { record("1:" + v.(string)) })
+	WhenReady("ms.name", func(v interface{}) { record("2:" + v.(string)) })
+	WhenAllReady(func() { record("all") })
+	time.Sleep(10 * time.Millisecond)
+	if n := count(); n != 0 {
+		t.Fatalf("%d calls before Load()", n)
+	}
+
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	waitCalls(3)
+
+	//not called again after reload
+	s.set(map[string]interface{}{"ms": map[string]interface{}{"name": "other"}})
+	if err := Reload(); err != nil {
+		t.Fatalf("failed to reload: %+v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if n := count(); n != 3 {
+		t.Fatalf("%d calls after reload: %v", n, calls)
+	}
+
+	//immediately when already loaded
+	WhenReady("ms.name", func(v interface{}) { record("3:" + v.(string)) })
+	WhenAllReady(func() { record("all") })
+	waitCalls(5)
+	mutex.Lock()
+	defer mutex.Unlock()
+	found := map[string]bool{}
+	for _, call := range calls {
+		found[call] = true
+	}
+	for _, call := range []string{"1:shop", "2:shop", "all", "3:other"} {
+		if !found[call] {
+			t.Fatalf("%s not called: %v", call, calls)
+		}
+	}
+}
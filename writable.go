@@ -0,0 +1,104 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/go-msvc/errors"
+)
+
+// WritableSource is a source that Set() can write to
+type WritableSource interface {
+	Source
+	Set(name string, value interface{}) error
+	Delete(name string) error
+}
+
+// writablePriority makes writable sources used before all other sources
+// so that values written with Set() are used, only overrides are used before them
+const writablePriority = math.MaxInt
+
+// EnsureWritable makes ws the source that Set() writes to for name and the names inside it
+// ws is also added as a source, used before all other sources, so values written
+// with Set() replace values from other sources
+func (c *Config) EnsureWritable(name string, ws WritableSource) {
+	if !validReference(name) {
+		panic(fmt.Sprintf("invalid writable config(%s), expecting dot-notation reference", name))
+	}
+	if ws == nil {
+		panic(fmt.Sprintf("config.EnsureWritable(%s) called with nil source", name))
+	}
+	name = c.resolveAlias(name)
+	c.writableMutex.Lock()
+	c.writableByName[name] = ws
+	c.writableMutex.Unlock()
+	if err := c.AddSourceWithPriority(writablePriority, "writable:"+name, ws); err != nil {
+		panic(fmt.Sprintf("cannot add writable config(%s): %+v", name, err))
+	}
+} //EnsureWritable()
+
+// Set writes the value of name to the source registered with EnsureWritable()
+// for name or its nearest parent, and nil value deletes it from that source
+// if config is already loaded, it is reloaded so that Get() and Watch() see the new value
+func (c *Config) Set(name string, value interface{}) error {
+	return c.SetAll(map[string]interface{}{name: value})
+}
+
+// SetAll is Set() for multiple names that must change together, e.g. host and port,
+// it writes all values before reloading once, so Get() never returns some new
+// and some old values, and Watch() callbacks are called once per changed value
+// if any name is not writable, nothing is written
+func (c *Config) SetAll(values map[string]interface{}) error {
+	//find all sources before writing anything
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	wsNames := make([]string, len(names))
+	wsList := make([]WritableSource, len(names))
+	for i, name := range names {
+		wsNames[i], wsList[i] = c.writableFor(c.resolveAlias(name))
+		if wsList[i] == nil {
+			return errors.Errorf("config(%s) is not writable (call config.EnsureWritable(...))", name)
+		}
+	}
+
+	for i, name := range names {
+		ws, wsName, value := wsList[i], wsNames[i], values[name]
+		name = c.resolveAlias(name)
+		if value == nil {
+			if err := ws.Delete(name); err != nil {
+				return errors.Wrapf(err, "failed to delete writable:%s.config(%s)", wsName, name)
+			}
+		} else {
+			if err := ws.Set(name, value); err != nil {
+				return errors.Wrapf(err, "failed to set writable:%s.config(%s)", wsName, name)
+			}
+		}
+	}
+
+	c.configByRefMutex.RLock()
+	isLoaded := c.loaded
+	c.configByRefMutex.RUnlock()
+	if !isLoaded {
+		return nil
+	}
+	return c.Reload()
+} //SetAll()
+
+// writableFor returns the source registered for name or its nearest parent, or nil
+func (c *Config) writableFor(name string) (string, WritableSource) {
+	c.writableMutex.Lock()
+	defer c.writableMutex.Unlock()
+	var ws WritableSource
+	wsName := ""
+	for n, s := range c.writableByName {
+		if (n == name || strings.HasPrefix(name, n+".")) && len(n) > len(wsName) {
+			wsName, ws = n, s //nearest
+		}
+	}
+	return wsName, ws
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-msvc/errors"
+)
+
+// testWritableSource is a testSource that Set() can write to
+type testWritableSource struct {
+	testSource
+	err error
+}
+
+func (s *testWritableSource) Set(name string, value interface{}) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.Lock()
+	defer s.Unlock()
+	if s.value == nil {
+		s.value = map[string]interface{}{}
+	}
+	setNested(s.value, name, value)
+	return nil
+}
+
+func (s *testWritableSource) Delete(name string) error {
+	s.Lock()
+	defer s.Unlock()
+	names := strings.Split(name, ".")
+	obj := s.value
+	for _, n := range names[:len(names)-1] {
+		sub, ok := obj[n].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		obj = sub
+	}
+	delete(obj, names[len(names)-1])
+	return nil
+}
+
+func TestSet(t *testing.T) {
+	reset(t)
+	MustConfigure("db.host", "")
+	MayConfigure("db.port", 0)
+	addTestSource(t, map[string]interface{}{"db": map[string]interface{}{"host": "filehost", "port": 1}})
+	ws := &testWritableSource{}
+	EnsureWritable("db", ws)
+
+	//before load, the written value is used by Load()
+	if err := Set("db.host", "firsthost"); err != nil {
+		t.Fatalf("failed to set: %+v", err)
+	}
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	if Get("db.host") != "firsthost" || Get("db.port") != 1 {
+		t.Fatalf("db.host=%v db.port=%v", Get("db.host"), Get("db.port"))
+	}
+
+	//after load, config is reloaded
+	if err := Set("db.host", "newhost"); err != nil {
+		t.Fatalf("failed to set: %+v", err)
+	}
+	if Get("db.host") != "newhost" || defaultConfig.sourceNameByRef["db.host"] != "writable:db" {
+		t.Fatalf("db.host=%v from %s", Get("db.host"), defaultConfig.sourceNameByRef["db.host"])
+	}
+
+	//nil deletes the written value
+	if err := Set("db.host", nil); err != nil {
+		t.Fatalf("failed to delete: %+v", err)
+	}
+	if Get("db.host") != "filehost" {
+		t.Fatalf("db.host=%v after delete", Get("db.host"))
+	}
+
+	if err := Set("ms.name", "test"); err == nil || !strings.Contains(err.Error(), "not writable") {
+		t.Fatalf("err=%v", err)
+	}
+	ws.err = errors.Errorf("read-only")
+	if err := Set("db.port", 2); err == nil || !strings.Contains(err.Error(), "read-only") {
+		t.Fatalf("err=%v", err)
+	}
+	//invalid values are written but fail the reload
+	ws.err = nil
+	if err := Set("db.port", "x"); err == nil {
+		t.Fatalf("reloaded invalid value")
+	}
+}
+
+func TestSetAll(t *testing.T) {
+	reset(t)
+	MustConfigure("db.host", "")
+	MustConfigure("db.port", 0)
+	addTestSource(t, map[string]interface{}{"db": map[string]interface{}{"host": "filehost", "port": 1}})
+	ws := &testWritableSource{}
+	EnsureWritable("db", ws)
+	if err := Load(); err != nil {
+		t.Fatalf("failed to load: %+v", err)
+	}
+	changed := make(chan string, 10)
+	defer Watch("db.host", func(v interface{}) { changed <- "host" })()
+	defer Watch("db.port", func(v interface{}) { changed <- "port" })()
+
+	v := Version()
+	if err := SetAll(map[string]interface{}{"db.host": "newhost", "db.port": 2}); err != nil {
+		t.Fatalf("failed to set: %+v", err)
+	}
+	if Version() != v+1 {
+		t.Fatalf("version %d after one SetAll() from %d", Version(), v)
+	}
+	if Get("db.host") != "newhost" || Get("db.port") != 2 {
+		t.Fatalf("db.host=%v db.port=%v", Get("db.host"), Get("db.port"))
+	}
+	calls := map[string]int{}
+	for i := 0; i < 2; i++ {
+		select {
+		case name := <-changed:
+			calls[name]++
+		case <-time.After(time.Second):
+			t.Fatalf("watchers called %v", calls)
+		}
+	}
+	select {
+	case name := <-changed:
+		t.Fatalf("watcher of %s called again", name)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	//nothing is written if one name is not writable
+	if err := SetAll(map[string]interface{}{"db.host": "otherhost", "ms.name": "x"}); err == nil || !strings.Contains(err.Error(), "config(ms.name) is not writable") {
+		t.Fatalf("err=%v", err)
+	}
+	if v, _ := ws.GetInto("db.host", ""); v != "newhost" {
+		t.Fatalf("wrote db.host=%v", v)
+	}
+}